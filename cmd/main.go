@@ -38,6 +38,18 @@ func main() {
 		driver.WithEndpoint(options.ServerOptions.Endpoint),
 		driver.WithMode(options.ServerOptions.DriverMode),
 		driver.WithExtraTags(options.ControllerOptions.ExtraTags),
+		driver.WithClusterName(options.ControllerOptions.ClusterName),
+		driver.WithNodeSecurityGroupIds(options.ControllerOptions.NodeSecurityGroupIds),
+		driver.WithCreateSecurityGroup(options.ControllerOptions.CreateSecurityGroup),
+		driver.WithSubdirectoryDeletePolicy(options.ControllerOptions.SubdirectoryDeletePolicy),
+		driver.WithMountTimeout(options.NodeOptions.MountTimeout),
+		driver.WithMountHealthCheckInterval(options.NodeOptions.MountHealthCheckInterval),
+		driver.WithMountRecovery(options.NodeOptions.MountRecovery),
+		driver.WithUnmountPolicy(options.NodeOptions.UnmountPolicy),
+		driver.WithUnmountTimeout(options.NodeOptions.UnmountTimeout),
+		driver.WithOrphanedMountCleanup(options.NodeOptions.OrphanedMountCleanup),
+		driver.WithOrphanedMountCleanupInterval(options.NodeOptions.OrphanedMountCleanupInterval),
+		driver.WithKubeletPath(options.NodeOptions.KubeletPath),
 	)
 
 	if err != nil {
@@ -24,8 +24,20 @@ import (
 type ControllerOptions struct {
 	// ExtraTags is a map of tags that will be attached to each dynamically provisioned resource.
 	ExtraTags string
+	// ClusterName is the name of the cluster that the cluster security group is created for.
+	ClusterName string
+	// NodeSecurityGroupIds are the security groups of the cluster's nodes that the cluster security group allows.
+	NodeSecurityGroupIds []string
+	// CreateSecurityGroup enables creating a security group for the filesystems of the cluster.
+	CreateSecurityGroup bool
+	// SubdirectoryDeletePolicy controls whether the subdirectories of deleted subdirectory volumes are deleted, archived or retained.
+	SubdirectoryDeletePolicy string
 }
 
 func (s *ControllerOptions) AddFlags(fs *flag.FlagSet) {
 	fs.StringVar(&s.ExtraTags, "extra-tags", "", "Extra tags to attach to each dynamically provisioned resource. It is a comma separated list of key value pairs like '<key1>=<value1>,<key2>=<value2>'")
+	fs.StringVar(&s.ClusterName, "cluster-name", "", "The name of the cluster, used to tag the security group created by --create-security-group")
+	fs.StringSliceVar(&s.NodeSecurityGroupIds, "node-security-group-ids", nil, "Comma separated list of the security group IDs of the cluster's nodes, which are allowed to reach the filesystems in the security group created by --create-security-group")
+	fs.BoolVar(&s.CreateSecurityGroup, "create-security-group", false, "Create a security group per cluster that opens the Lustre ports to the node security groups, attach it to dynamically provisioned filesystems, and delete it when no filesystem uses it")
+	fs.StringVar(&s.SubdirectoryDeletePolicy, "subdirectory-delete-policy", "delete", "What to do with the subdirectory of a deleted subdirectory volume: 'delete' it, 'archive' it by renaming it to archived-<name>-<timestamp>, or 'retain' it")
 }
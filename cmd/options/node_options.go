@@ -17,12 +17,38 @@ limitations under the License.
 package options
 
 import (
+	"time"
+
 	flag "github.com/spf13/pflag"
 )
 
 // NodeOptions contains options and configuration settings for the node service.
 type NodeOptions struct {
+	// MountTimeout bounds how long the node waits for a Lustre mount before it is killed.
+	MountTimeout time.Duration
+	// MountHealthCheckInterval is the interval to probe the Lustre mounts of the node.
+	MountHealthCheckInterval time.Duration
+	// MountRecovery controls how unhealthy Lustre mounts are recovered.
+	MountRecovery string
+	// UnmountPolicy controls how far unmounts that fail or time out are escalated.
+	UnmountPolicy string
+	// UnmountTimeout bounds each attempt to unmount a target before it is escalated.
+	UnmountTimeout time.Duration
+	// OrphanedMountCleanup controls whether the Lustre mounts of deleted pods are unmounted or only reported.
+	OrphanedMountCleanup string
+	// OrphanedMountCleanupInterval is the interval to clean up orphaned mounts after startup.
+	OrphanedMountCleanupInterval time.Duration
+	// KubeletPath is the root directory of the kubelet.
+	KubeletPath string
 }
 
 func (o *NodeOptions) AddFlags(fs *flag.FlagSet) {
+	fs.DurationVar(&o.MountTimeout, "mount-timeout", 90*time.Second, "How long to wait for a Lustre mount before the mount is killed and cleaned up, which usually means that the node cannot reach the filesystem over LNet. 0 disables the timeout")
+	fs.DurationVar(&o.MountHealthCheckInterval, "mount-health-check-interval", time.Minute, "How often to probe the Lustre mounts of the node for evicted clients, which are reported as abnormal volume conditions and events on the pods. 0 disables the check")
+	fs.StringVar(&o.MountRecovery, "mount-recovery", "none", "How to recover unhealthy Lustre mounts: 'none', 'lctl' to reconnect the client to the filesystem with lctl recover, or 'remount' to unmount and mount them again")
+	fs.StringVar(&o.UnmountPolicy, "unmount-policy", "none", "How far to escalate unmounts that fail or time out: 'none', 'force' to retry with umount -f, or 'lazy' to retry with umount -f and then detach the mount with umount -l")
+	fs.DurationVar(&o.UnmountTimeout, "unmount-timeout", 30*time.Second, "How long each attempt to unmount a target may take before it is escalated by the unmount policy. 0 disables the timeout")
+	fs.StringVar(&o.OrphanedMountCleanup, "orphaned-mount-cleanup", "disabled", "What to do with Lustre mounts in the pods directory of the kubelet whose pods no longer exist: 'disabled', 'dry-run' to only report them, or 'enabled' to unmount them at startup and periodically")
+	fs.DurationVar(&o.OrphanedMountCleanupInterval, "orphaned-mount-cleanup-interval", 10*time.Minute, "How often to clean up orphaned Lustre mounts after startup. 0 only cleans up at startup")
+	fs.StringVar(&o.KubeletPath, "kubelet-path", "/var/lib/kubelet", "The root directory of the kubelet")
 }
@@ -28,6 +28,46 @@ func TestNodeOptions(t *testing.T) {
 		flag  string
 		found bool
 	}{
+		{
+			name:  "lookup mount timeout flag",
+			flag:  "mount-timeout",
+			found: true,
+		},
+		{
+			name:  "lookup mount health check interval flag",
+			flag:  "mount-health-check-interval",
+			found: true,
+		},
+		{
+			name:  "lookup mount recovery flag",
+			flag:  "mount-recovery",
+			found: true,
+		},
+		{
+			name:  "lookup unmount policy flag",
+			flag:  "unmount-policy",
+			found: true,
+		},
+		{
+			name:  "lookup unmount timeout flag",
+			flag:  "unmount-timeout",
+			found: true,
+		},
+		{
+			name:  "lookup orphaned mount cleanup flag",
+			flag:  "orphaned-mount-cleanup",
+			found: true,
+		},
+		{
+			name:  "lookup orphaned mount cleanup interval flag",
+			flag:  "orphaned-mount-cleanup-interval",
+			found: true,
+		},
+		{
+			name:  "lookup kubelet path flag",
+			flag:  "kubelet-path",
+			found: true,
+		},
 		{
 			name:  "fail for non-desired flag",
 			flag:  "some-flag",
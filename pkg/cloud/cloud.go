@@ -21,14 +21,18 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/aws/aws-sdk-go-v2/service/fsx"
 	"github.com/aws/aws-sdk-go-v2/service/fsx/types"
+	"github.com/aws/smithy-go"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
 )
@@ -54,8 +58,25 @@ const (
 const (
 	// VolumeNameTagKey is the key value that refers to the volume's name.
 	VolumeNameTagKey = "CSIVolumeName"
+	// SnapshotNameTagKey is the key value that refers to the snapshot's name.
+	SnapshotNameTagKey = "CSIVolumeSnapshotName"
+	// CloneVolumeNameTagKey is the key value that refers to the name of the clone an intermediate backup is created for.
+	CloneVolumeNameTagKey = "CSICloneVolumeName"
+	// ClusterSecurityGroupTagKey is the key value that refers to the cluster a security group is created for.
+	ClusterSecurityGroupTagKey = "fsx.csi.aws.com/cluster-name"
+	// ClusterSecurityGroupLastUsedTagKey is the key value that refers to the time a security group was last used to
+	// create a filesystem.
+	ClusterSecurityGroupLastUsedTagKey = "fsx.csi.aws.com/last-used"
 )
 
+// clusterSecurityGroupInUseGracePeriod is the time after its last use that a cluster security group is not deleted
+// even though no network interface uses it, which covers the time until a new filesystem creates its network
+// interfaces. Another controller replica may use the security group while this replica garbage collects it.
+var clusterSecurityGroupInUseGracePeriod = time.Hour
+
+// LustrePortRanges are the TCP port ranges that FSx for Lustre filesystems and clients communicate on
+var LustrePortRanges = [][2]int32{{988, 988}, {1018, 1023}}
+
 // Set during build time via -ldflags
 var driverVersion string
 
@@ -70,17 +91,40 @@ var (
 
 	// ErrNotFound is returned when a resource is not found.
 	ErrNotFound = errors.New("Resource was not found")
+
+	// ErrBackupExistsDiffFs is an error that is returned if a backup
+	// exists with a given name, but a different source filesystem is requested.
+	ErrBackupExistsDiffFs = errors.New("There is already a backup with same name and different source filesystem")
 )
 
 // FileSystem represents a FSx for Lustre filesystem
 type FileSystem struct {
-	FileSystemId             string
-	CapacityGiB              int32
-	DnsName                  string
-	MountName                string
-	StorageType              string
-	DeploymentType           string
-	PerUnitStorageThroughput int32
+	FileSystemId                  string
+	CapacityGiB                   int32
+	DnsName                       string
+	MountName                     string
+	SubnetId                      string
+	StorageType                   string
+	DeploymentType                string
+	PerUnitStorageThroughput      int32
+	DataCompressionType           string
+	MetadataConfigurationMode     string
+	MetadataIops                  int32
+	AutomaticBackupRetentionDays  int32
+	DailyAutomaticBackupStartTime string
+	WeeklyMaintenanceStartTime    string
+	Lifecycle                     string
+	FailureMessage                string
+	AdministrativeActions         []AdministrativeAction
+	Tags                          map[string]string
+}
+
+// AdministrativeAction represents an administrative action in progress or completed on a FSx for Lustre filesystem
+type AdministrativeAction struct {
+	ActionType     string
+	Status         string
+	RequestTime    time.Time
+	FailureMessage string
 }
 
 // FileSystemOptions represents the options to create FSx for Lustre filesystem
@@ -108,27 +152,150 @@ type FileSystemOptions struct {
 	MetadataIops                  int32
 }
 
+// FileSystemUpdateOptions represents the options to update a FSx for Lustre filesystem. Fields left at their zero
+// value are not changed. AutomaticBackupRetentionDays is a pointer because a retention of 0 days disables automatic
+// backups.
+type FileSystemUpdateOptions struct {
+	PerUnitStorageThroughput      int32
+	DataCompressionType           string
+	MetadataConfigurationMode     string
+	MetadataIops                  int32
+	AutomaticBackupRetentionDays  *int32
+	DailyAutomaticBackupStartTime string
+	WeeklyMaintenanceStartTime    string
+}
+
+// Backup represents a FSx for Lustre backup
+type Backup struct {
+	BackupId                 string
+	FileSystemId             string
+	CapacityGiB              int32
+	StorageType              string
+	DeploymentType           string
+	PerUnitStorageThroughput int32
+	CreationTime             time.Time
+	Lifecycle                string
+	FailureMessage           string
+	Tags                     map[string]string
+}
+
+// DataRepositoryAssociation represents a link between a directory of a FSx for Lustre filesystem and an S3 prefix
+type DataRepositoryAssociation struct {
+	AssociationId      string
+	FileSystemId       string
+	FileSystemPath     string
+	DataRepositoryPath string
+	Lifecycle          string
+	FailureMessage     string
+}
+
+// DataRepositoryTask represents a task that transfers data between a FSx for Lustre filesystem and its data repositories
+type DataRepositoryTask struct {
+	TaskId         string
+	FileSystemId   string
+	Type           string
+	Lifecycle      string
+	FailureMessage string
+}
+
+// DataRepositoryAssociationOptions represents the options to create a data repository association
+type DataRepositoryAssociationOptions struct {
+	FileSystemPath              string
+	DataRepositoryPath          string
+	AutoImportEvents            []string
+	AutoExportEvents            []string
+	BatchImportMetaDataOnCreate bool
+	ImportedFileChunkSize       int32
+}
+
+// Subnet represents an EC2 subnet that FSx for Lustre filesystems are created in
+type Subnet struct {
+	SubnetId                string
+	VpcId                   string
+	AvailabilityZone        string
+	AvailableIpAddressCount int32
+}
+
+// BackupOptions represents the options to create a FSx for Lustre backup
+type BackupOptions struct {
+	FileSystemId string
+	ExtraTags    []string
+	// CloneVolumeName marks the backup as the intermediate backup of the clone with the volume name instead of a
+	// snapshot
+	CloneVolumeName string
+}
+
+// DeleteFileSystemOptions represents the options to delete a FSx for Lustre filesystem
+type DeleteFileSystemOptions struct {
+	// FinalBackup requests a backup of the filesystem before it is deleted
+	FinalBackup     bool
+	FinalBackupTags []string
+}
+
 // FSx abstracts FSx client to facilitate its mocking.
 type FSx interface {
 	CreateFileSystem(context.Context, *fsx.CreateFileSystemInput, ...func(*fsx.Options)) (*fsx.CreateFileSystemOutput, error)
 	UpdateFileSystem(context.Context, *fsx.UpdateFileSystemInput, ...func(*fsx.Options)) (*fsx.UpdateFileSystemOutput, error)
 	DeleteFileSystem(context.Context, *fsx.DeleteFileSystemInput, ...func(*fsx.Options)) (*fsx.DeleteFileSystemOutput, error)
 	DescribeFileSystems(context.Context, *fsx.DescribeFileSystemsInput, ...func(*fsx.Options)) (*fsx.DescribeFileSystemsOutput, error)
+	CreateFileSystemFromBackup(context.Context, *fsx.CreateFileSystemFromBackupInput, ...func(*fsx.Options)) (*fsx.CreateFileSystemFromBackupOutput, error)
+	CreateBackup(context.Context, *fsx.CreateBackupInput, ...func(*fsx.Options)) (*fsx.CreateBackupOutput, error)
+	DeleteBackup(context.Context, *fsx.DeleteBackupInput, ...func(*fsx.Options)) (*fsx.DeleteBackupOutput, error)
+	DescribeBackups(context.Context, *fsx.DescribeBackupsInput, ...func(*fsx.Options)) (*fsx.DescribeBackupsOutput, error)
+	CreateDataRepositoryAssociation(context.Context, *fsx.CreateDataRepositoryAssociationInput, ...func(*fsx.Options)) (*fsx.CreateDataRepositoryAssociationOutput, error)
+	DescribeDataRepositoryAssociations(context.Context, *fsx.DescribeDataRepositoryAssociationsInput, ...func(*fsx.Options)) (*fsx.DescribeDataRepositoryAssociationsOutput, error)
+	CreateDataRepositoryTask(context.Context, *fsx.CreateDataRepositoryTaskInput, ...func(*fsx.Options)) (*fsx.CreateDataRepositoryTaskOutput, error)
+	DescribeDataRepositoryTasks(context.Context, *fsx.DescribeDataRepositoryTasksInput, ...func(*fsx.Options)) (*fsx.DescribeDataRepositoryTasksOutput, error)
+}
+
+// EC2 abstracts EC2 client to facilitate its mocking.
+type EC2 interface {
+	DescribeSubnets(context.Context, *ec2.DescribeSubnetsInput, ...func(*ec2.Options)) (*ec2.DescribeSubnetsOutput, error)
+	DescribeSecurityGroups(context.Context, *ec2.DescribeSecurityGroupsInput, ...func(*ec2.Options)) (*ec2.DescribeSecurityGroupsOutput, error)
+	CreateSecurityGroup(context.Context, *ec2.CreateSecurityGroupInput, ...func(*ec2.Options)) (*ec2.CreateSecurityGroupOutput, error)
+	AuthorizeSecurityGroupIngress(context.Context, *ec2.AuthorizeSecurityGroupIngressInput, ...func(*ec2.Options)) (*ec2.AuthorizeSecurityGroupIngressOutput, error)
+	DeleteSecurityGroup(context.Context, *ec2.DeleteSecurityGroupInput, ...func(*ec2.Options)) (*ec2.DeleteSecurityGroupOutput, error)
+	CreateTags(context.Context, *ec2.CreateTagsInput, ...func(*ec2.Options)) (*ec2.CreateTagsOutput, error)
+	DescribeNetworkInterfaces(context.Context, *ec2.DescribeNetworkInterfacesInput, ...func(*ec2.Options)) (*ec2.DescribeNetworkInterfacesOutput, error)
 }
 
 type Cloud interface {
 	CreateFileSystem(ctx context.Context, volumeName string, fileSystemOptions *FileSystemOptions) (fs *FileSystem, err error)
+	CreateFileSystemFromBackup(ctx context.Context, volumeName string, backupId string, fileSystemOptions *FileSystemOptions) (fs *FileSystem, err error)
 	ResizeFileSystem(ctx context.Context, fileSystemId string, newSizeGiB int32) (int32, error)
-	DeleteFileSystem(ctx context.Context, fileSystemId string) (err error)
+	DeleteFileSystem(ctx context.Context, fileSystemId string, deleteOptions *DeleteFileSystemOptions) (backupId string, err error)
 	DescribeFileSystem(ctx context.Context, fileSystemId string) (fs *FileSystem, err error)
 	WaitForFileSystemAvailable(ctx context.Context, fileSystemId string) error
 	WaitForFileSystemResize(ctx context.Context, fileSystemId string, resizeGiB int32) error
+	UpdateFileSystem(ctx context.Context, fileSystemId string, updateOptions *FileSystemUpdateOptions) error
+	WaitForFileSystemUpdate(ctx context.Context, fileSystemId string, updateOptions *FileSystemUpdateOptions) error
 	FindFileSystemByVolumeName(ctx context.Context, volumeName string) (*FileSystem, error)
+	ListFileSystems(ctx context.Context) ([]*FileSystem, error)
+	CreateBackup(ctx context.Context, snapshotName string, backupOptions *BackupOptions) (*Backup, error)
+	DeleteBackup(ctx context.Context, backupId string) error
+	DescribeBackup(ctx context.Context, backupId string) (*Backup, error)
+	WaitForBackupAvailable(ctx context.Context, backupId string) error
+	ListBackups(ctx context.Context, fileSystemId string, maxResults int32, nextToken string) ([]*Backup, string, error)
+	FindCloneBackup(ctx context.Context, fileSystemId string, volumeName string) (*Backup, error)
+	CreateDataRepositoryAssociation(ctx context.Context, fileSystemId string, associationOptions *DataRepositoryAssociationOptions) (*DataRepositoryAssociation, error)
+	DescribeDataRepositoryAssociation(ctx context.Context, associationId string) (*DataRepositoryAssociation, error)
+	WaitForDataRepositoryAssociationAvailable(ctx context.Context, associationId string) error
+	ListDataRepositoryAssociations(ctx context.Context, fileSystemId string) ([]*DataRepositoryAssociation, error)
+	CreateDataRepositoryTask(ctx context.Context, fileSystemId string, taskType string) (*DataRepositoryTask, error)
+	DescribeDataRepositoryTask(ctx context.Context, taskId string) (*DataRepositoryTask, error)
+	WaitForDataRepositoryTask(ctx context.Context, taskId string) error
+	ListDataRepositoryTasks(ctx context.Context, fileSystemId string) ([]*DataRepositoryTask, error)
+	DescribeSubnet(ctx context.Context, subnetId string) (*Subnet, error)
+	FindSubnetsByTags(ctx context.Context, tags map[string]string) ([]*Subnet, error)
+	FindSecurityGroupsByTags(ctx context.Context, vpcId string, tags map[string]string) ([]string, error)
+	EnsureClusterSecurityGroup(ctx context.Context, clusterName string, vpcId string, sourceSecurityGroupIds []string) (string, error)
+	DeleteUnusedClusterSecurityGroups(ctx context.Context, clusterName string) error
 }
 
 type cloud struct {
 	region      string
 	fsx         FSx
+	ec2         EC2
 	volumeCache map[string]*FileSystem
 	cacheMutex  sync.RWMutex
 }
@@ -149,6 +316,7 @@ func NewCloud(region string) (Cloud, error) {
 	c := &cloud{
 		region:      region,
 		fsx:         svc,
+		ec2:         ec2.NewFromConfig(awsConfig),
 		volumeCache: make(map[string]*FileSystem),
 	}
 	go c.pollFileSystems()
@@ -160,85 +328,70 @@ func (c *cloud) CreateFileSystem(ctx context.Context, volumeName string, fileSys
 		return nil, fmt.Errorf("SubnetId is required")
 	}
 
-	lustreConfiguration := &types.CreateFileSystemLustreConfiguration{}
-
-	if fileSystemOptions.AutoImportPolicy != "" {
-		lustreConfiguration.AutoImportPolicy = types.AutoImportPolicyType(fileSystemOptions.AutoImportPolicy)
-	}
+	lustreConfiguration := newCreateFileSystemLustreConfiguration(fileSystemOptions)
 
-	if fileSystemOptions.S3ImportPath != "" {
-		lustreConfiguration.ImportPath = aws.String(fileSystemOptions.S3ImportPath)
+	var tags = []types.Tag{
+		{
+			Key:   aws.String(VolumeNameTagKey),
+			Value: aws.String(volumeName),
+		},
 	}
+	tags = append(tags, newExtraTags(fileSystemOptions.ExtraTags)...)
 
-	if fileSystemOptions.S3ExportPath != "" {
-		lustreConfiguration.ExportPath = aws.String(fileSystemOptions.S3ExportPath)
+	input := &fsx.CreateFileSystemInput{
+		ClientRequestToken:  aws.String(volumeName),
+		FileSystemType:      "LUSTRE",
+		LustreConfiguration: lustreConfiguration,
+		StorageCapacity:     aws.Int32(fileSystemOptions.CapacityGiB),
+		SubnetIds:           []string{fileSystemOptions.SubnetId},
+		SecurityGroupIds:    fileSystemOptions.SecurityGroupIds,
+		Tags:                tags,
 	}
 
-	if fileSystemOptions.DeploymentType != "" {
-		lustreConfiguration.DeploymentType = types.LustreDeploymentType(fileSystemOptions.DeploymentType)
+	if fileSystemOptions.FileSystemTypeVersion != "" {
+		input.FileSystemTypeVersion = aws.String(fileSystemOptions.FileSystemTypeVersion)
 	}
-
-	if fileSystemOptions.DriveCacheType != "" {
-		lustreConfiguration.DriveCacheType = types.DriveCacheType(fileSystemOptions.DriveCacheType)
+	if fileSystemOptions.StorageType != "" {
+		input.StorageType = types.StorageType(fileSystemOptions.StorageType)
 	}
-
-	if fileSystemOptions.PerUnitStorageThroughput != 0 {
-		lustreConfiguration.PerUnitStorageThroughput = aws.Int32(fileSystemOptions.PerUnitStorageThroughput)
+	if fileSystemOptions.KmsKeyId != "" {
+		input.KmsKeyId = aws.String(fileSystemOptions.KmsKeyId)
 	}
 
-	if fileSystemOptions.AutomaticBackupRetentionDays != 0 {
-		lustreConfiguration.AutomaticBackupRetentionDays = aws.Int32(fileSystemOptions.AutomaticBackupRetentionDays)
-		if fileSystemOptions.DailyAutomaticBackupStartTime != "" {
-			lustreConfiguration.DailyAutomaticBackupStartTime = aws.String(fileSystemOptions.DailyAutomaticBackupStartTime)
-		}
+	output, err := c.fsx.CreateFileSystem(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("CreateFileSystem failed: %v", err)
 	}
 
-	if fileSystemOptions.CopyTagsToBackups {
-		lustreConfiguration.CopyTagsToBackups = aws.Bool(true)
-	}
+	fs = newFileSystem(output.FileSystem)
 
-	if fileSystemOptions.DataCompressionType != "" {
-		lustreConfiguration.DataCompressionType = types.DataCompressionType(fileSystemOptions.DataCompressionType)
-	}
+	c.cacheMutex.Lock()
+	c.volumeCache[volumeName] = fs
+	c.cacheMutex.Unlock()
+	klog.V(4).InfoS("CreateFileSystem: added to cache", "volumeName", volumeName)
 
-	if fileSystemOptions.WeeklyMaintenanceStartTime != "" {
-		lustreConfiguration.WeeklyMaintenanceStartTime = aws.String(fileSystemOptions.WeeklyMaintenanceStartTime)
-	}
+	return fs, nil
+}
 
-	if fileSystemOptions.EfaEnabled {
-		lustreConfiguration.EfaEnabled = aws.Bool(true)
+// CreateFileSystemFromBackup makes a request to the FSx API to restore a new filesystem from a backup. The filesystem
+// inherits the deployment and storage type of the backup, while the remaining options may override the backup's.
+func (c *cloud) CreateFileSystemFromBackup(ctx context.Context, volumeName string, backupId string, fileSystemOptions *FileSystemOptions) (fs *FileSystem, err error) {
+	if len(fileSystemOptions.SubnetId) == 0 {
+		return nil, fmt.Errorf("SubnetId is required")
 	}
 
-	if fileSystemOptions.MetadataConfigurationMode != "" {
-		metadataConfiguration := &types.CreateFileSystemLustreMetadataConfiguration{}
-		metadataConfiguration.Mode = types.MetadataConfigurationMode(fileSystemOptions.MetadataConfigurationMode)
-		if fileSystemOptions.MetadataIops != 0 {
-			metadataConfiguration.Iops = aws.Int32(fileSystemOptions.MetadataIops)
-		}
-		lustreConfiguration.MetadataConfiguration = metadataConfiguration
-	}
 	var tags = []types.Tag{
 		{
 			Key:   aws.String(VolumeNameTagKey),
 			Value: aws.String(volumeName),
 		},
 	}
+	tags = append(tags, newExtraTags(fileSystemOptions.ExtraTags)...)
 
-	for _, extraTag := range fileSystemOptions.ExtraTags {
-		extraTagSplit := strings.Split(extraTag, "=")
-		tagKey := extraTagSplit[0]
-		tagValue := extraTagSplit[1]
-
-		tags = append(tags, types.Tag{
-			Key:   aws.String(tagKey),
-			Value: aws.String(tagValue),
-		})
-	}
-
-	input := &fsx.CreateFileSystemInput{
+	input := &fsx.CreateFileSystemFromBackupInput{
+		BackupId:            aws.String(backupId),
 		ClientRequestToken:  aws.String(volumeName),
-		FileSystemType:      "LUSTRE",
-		LustreConfiguration: lustreConfiguration,
+		LustreConfiguration: newCreateFileSystemLustreConfiguration(fileSystemOptions),
 		StorageCapacity:     aws.Int32(fileSystemOptions.CapacityGiB),
 		SubnetIds:           []string{fileSystemOptions.SubnetId},
 		SecurityGroupIds:    fileSystemOptions.SecurityGroupIds,
@@ -255,35 +408,20 @@ func (c *cloud) CreateFileSystem(ctx context.Context, volumeName string, fileSys
 		input.KmsKeyId = aws.String(fileSystemOptions.KmsKeyId)
 	}
 
-	output, err := c.fsx.CreateFileSystem(ctx, input)
+	output, err := c.fsx.CreateFileSystemFromBackup(ctx, input)
 	if err != nil {
-		return nil, fmt.Errorf("CreateFileSystem failed: %v", err)
-	}
-
-	mountName := "fsx"
-	if output.FileSystem.LustreConfiguration.MountName != nil {
-		mountName = *output.FileSystem.LustreConfiguration.MountName
-	}
-
-	perUnitStorageThroughput := int32(0)
-	if output.FileSystem.LustreConfiguration.PerUnitStorageThroughput != nil {
-		perUnitStorageThroughput = *output.FileSystem.LustreConfiguration.PerUnitStorageThroughput
+		if isBackupNotFound(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("CreateFileSystemFromBackup failed: %v", err)
 	}
 
-	fs = &FileSystem{
-		FileSystemId:             *output.FileSystem.FileSystemId,
-		CapacityGiB:              *output.FileSystem.StorageCapacity,
-		DnsName:                  *output.FileSystem.DNSName,
-		MountName:                mountName,
-		StorageType:              string(output.FileSystem.StorageType),
-		DeploymentType:           string(output.FileSystem.LustreConfiguration.DeploymentType),
-		PerUnitStorageThroughput: perUnitStorageThroughput,
-	}
+	fs = newFileSystem(output.FileSystem)
 
 	c.cacheMutex.Lock()
 	c.volumeCache[volumeName] = fs
 	c.cacheMutex.Unlock()
-	klog.V(4).InfoS("CreateFileSystem: added to cache", "volumeName", volumeName)
+	klog.V(4).InfoS("CreateFileSystemFromBackup: added to cache", "volumeName", volumeName)
 
 	return fs, nil
 }
@@ -318,15 +456,87 @@ func (c *cloud) ResizeFileSystem(ctx context.Context, fileSystemId string, newSi
 	return newSizeGiB, nil
 }
 
-func (c *cloud) DeleteFileSystem(ctx context.Context, fileSystemId string) (err error) {
+func (c *cloud) UpdateFileSystem(ctx context.Context, fileSystemId string, updateOptions *FileSystemUpdateOptions) error {
+	lustreConfiguration := &types.UpdateFileSystemLustreConfiguration{}
+	if updateOptions.PerUnitStorageThroughput != 0 {
+		lustreConfiguration.PerUnitStorageThroughput = aws.Int32(updateOptions.PerUnitStorageThroughput)
+	}
+
+	if updateOptions.DataCompressionType != "" {
+		lustreConfiguration.DataCompressionType = types.DataCompressionType(updateOptions.DataCompressionType)
+	}
+
+	if updateOptions.MetadataConfigurationMode != "" || updateOptions.MetadataIops != 0 {
+		metadataConfiguration := &types.UpdateFileSystemLustreMetadataConfiguration{
+			Mode: types.MetadataConfigurationMode(updateOptions.MetadataConfigurationMode),
+		}
+		if updateOptions.MetadataIops != 0 {
+			metadataConfiguration.Iops = aws.Int32(updateOptions.MetadataIops)
+		}
+		lustreConfiguration.MetadataConfiguration = metadataConfiguration
+	}
+
+	if updateOptions.AutomaticBackupRetentionDays != nil {
+		lustreConfiguration.AutomaticBackupRetentionDays = aws.Int32(*updateOptions.AutomaticBackupRetentionDays)
+	}
+
+	if updateOptions.DailyAutomaticBackupStartTime != "" {
+		lustreConfiguration.DailyAutomaticBackupStartTime = aws.String(updateOptions.DailyAutomaticBackupStartTime)
+	}
+
+	if updateOptions.WeeklyMaintenanceStartTime != "" {
+		lustreConfiguration.WeeklyMaintenanceStartTime = aws.String(updateOptions.WeeklyMaintenanceStartTime)
+	}
+
+	input := &fsx.UpdateFileSystemInput{
+		FileSystemId:        aws.String(fileSystemId),
+		LustreConfiguration: lustreConfiguration,
+	}
+
+	_, err := c.fsx.UpdateFileSystem(ctx, input)
+	if err != nil {
+		if isFileSystemNotFound(err) {
+			return ErrNotFound
+		}
+		if !isBadRequestUpdateInProgress(err) {
+			return fmt.Errorf("UpdateFileSystem failed: %v", err)
+		}
+
+		// A previous modification request that experienced a timeout could have already made an equivalent update
+		// request to the FSx API.
+		if !hasAdministrativeUpdate(updateOptions) {
+			return c.verifyImmediateUpdate(ctx, fileSystemId, updateOptions)
+		}
+		_, err = c.getUpdateAdministrativeAction(ctx, fileSystemId, updateOptions)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteFileSystem makes a request to the FSx API to delete the filesystem. If a final backup is requested, the ID of
+// the final backup is returned.
+func (c *cloud) DeleteFileSystem(ctx context.Context, fileSystemId string, deleteOptions *DeleteFileSystemOptions) (backupId string, err error) {
 	input := &fsx.DeleteFileSystemInput{
 		FileSystemId: aws.String(fileSystemId),
 	}
-	if _, err = c.fsx.DeleteFileSystem(ctx, input); err != nil {
+	if deleteOptions != nil && deleteOptions.FinalBackup {
+		input.LustreConfiguration = &types.DeleteFileSystemLustreConfiguration{
+			SkipFinalBackup: aws.Bool(false),
+			FinalBackupTags: newExtraTags(deleteOptions.FinalBackupTags),
+		}
+	}
+	output, err := c.fsx.DeleteFileSystem(ctx, input)
+	if err != nil {
 		if isFileSystemNotFound(err) {
-			return ErrNotFound
+			return "", ErrNotFound
 		}
-		return fmt.Errorf("DeleteFileSystem failed: %v", err)
+		return "", fmt.Errorf("DeleteFileSystem failed: %v", err)
+	}
+	if output.LustreResponse != nil {
+		backupId = aws.ToString(output.LustreResponse.FinalBackupId)
 	}
 
 	c.cacheMutex.Lock()
@@ -339,34 +549,19 @@ func (c *cloud) DeleteFileSystem(ctx context.Context, fileSystemId string) (err
 		}
 	}
 
-	return nil
+	return backupId, nil
 }
 
 func (c *cloud) DescribeFileSystem(ctx context.Context, fileSystemId string) (*FileSystem, error) {
 	fs, err := c.getFileSystem(ctx, fileSystemId)
 	if err != nil {
+		if isFileSystemNotFound(err) {
+			return nil, ErrNotFound
+		}
 		return nil, err
 	}
 
-	mountName := "fsx"
-	if fs.LustreConfiguration.MountName != nil {
-		mountName = *fs.LustreConfiguration.MountName
-	}
-
-	perUnitStorageThroughput := int32(0)
-	if fs.LustreConfiguration.PerUnitStorageThroughput != nil {
-		perUnitStorageThroughput = *fs.LustreConfiguration.PerUnitStorageThroughput
-	}
-
-	return &FileSystem{
-		FileSystemId:             *fs.FileSystemId,
-		CapacityGiB:              *fs.StorageCapacity,
-		DnsName:                  *fs.DNSName,
-		MountName:                mountName,
-		StorageType:              string(fs.StorageType),
-		DeploymentType:           string(fs.LustreConfiguration.DeploymentType),
-		PerUnitStorageThroughput: perUnitStorageThroughput,
-	}, nil
+	return newFileSystem(fs), nil
 }
 
 func (c *cloud) WaitForFileSystemAvailable(ctx context.Context, fileSystemId string) error {
@@ -415,62 +610,1086 @@ func (c *cloud) WaitForFileSystemResize(ctx context.Context, fileSystemId string
 	return err
 }
 
-func (c *cloud) getFileSystem(ctx context.Context, fileSystemId string) (*types.FileSystem, error) {
-	input := &fsx.DescribeFileSystemsInput{
-		FileSystemIds: []string{fileSystemId},
-	}
-
-	output, err := c.fsx.DescribeFileSystems(ctx, input)
-	if err != nil {
-		return nil, err
+// WaitForFileSystemUpdate polls the FSx API for status of the update operation with the given target values. The
+// polling terminates when the update operation reaches a completed, failed, or unknown state.
+func (c *cloud) WaitForFileSystemUpdate(ctx context.Context, fileSystemId string, updateOptions *FileSystemUpdateOptions) error {
+	if !hasAdministrativeUpdate(updateOptions) {
+		// Backup and maintenance window settings are applied without a FILE_SYSTEM_UPDATE administrative action
+		return c.verifyImmediateUpdate(ctx, fileSystemId, updateOptions)
 	}
 
-	if len(output.FileSystems) > 1 {
-		return nil, ErrMultiFileSystems
-	}
+	err := wait.PollImmediate(PollCheckInterval, PollCheckTimeout, func() (done bool, err error) {
+		updateAction, err := c.getUpdateAdministrativeAction(ctx, fileSystemId, updateOptions)
+		if err != nil {
+			return true, err
+		}
 
-	if len(output.FileSystems) == 0 {
-		return nil, ErrNotFound
-	}
+		klog.V(2).InfoS("WaitForFileSystemUpdate", "filesystem", fileSystemId, "update status", string(updateAction.Status))
+		switch string(updateAction.Status) {
+		case "PENDING", "IN_PROGRESS":
+			// The update workflow has not completed
+			return false, nil
+		case "UPDATED_OPTIMIZING", "COMPLETED":
+			// The update workflow has completed and the filesystem is in a usable state
+			return true, nil
+		default:
+			failureMessage := ""
+			if updateAction.FailureDetails != nil {
+				failureMessage = aws.ToString(updateAction.FailureDetails.Message)
+			}
+			return true, fmt.Errorf("update failed for filesystem %s: %q", fileSystemId, failureMessage)
+		}
+	})
 
-	return &(output.FileSystems[0]), nil
+	return err
 }
 
-// getUpdateResizeAdministrativeAction retrieves the AdministrativeAction associated with a file system update with the
-// given target storage capacity, if one exists.
-func (c *cloud) getUpdateResizeAdministrativeAction(ctx context.Context, fileSystemId string, resizeGiB int32) (*types.AdministrativeAction, error) {
-	fs, err := c.getFileSystem(ctx, fileSystemId)
-	if err != nil {
-		return nil, fmt.Errorf("DescribeFileSystems failed: %v", err)
+// CreateBackup makes a request to the FSx API to create a user-initiated backup of the filesystem. The snapshot name is
+// used as the client request token, so repeated requests for the same snapshot return the same backup.
+func (c *cloud) CreateBackup(ctx context.Context, snapshotName string, backupOptions *BackupOptions) (*Backup, error) {
+	if len(backupOptions.FileSystemId) == 0 {
+		return nil, fmt.Errorf("FileSystemId is required")
 	}
 
-	if len(fs.AdministrativeActions) == 0 {
-		return nil, fmt.Errorf("there is no update on filesystem %s", fileSystemId)
+	var tags = []types.Tag{
+		{
+			Key:   aws.String(SnapshotNameTagKey),
+			Value: aws.String(snapshotName),
+		},
+	}
+	if backupOptions.CloneVolumeName != "" {
+		tags = []types.Tag{
+			{
+				Key:   aws.String(CloneVolumeNameTagKey),
+				Value: aws.String(backupOptions.CloneVolumeName),
+			},
+		}
 	}
+	tags = append(tags, newExtraTags(backupOptions.ExtraTags)...)
 
-	// AdministrativeAction items are ordered by newest to oldest start time, so use the first satisfactory target
-	// storage capacity match
-	for _, action := range fs.AdministrativeActions {
-		if action.AdministrativeActionType == "FILE_SYSTEM_UPDATE" &&
-			action.TargetFileSystemValues.StorageCapacity != nil &&
-			*action.TargetFileSystemValues.StorageCapacity == resizeGiB {
-			return &action, nil
+	input := &fsx.CreateBackupInput{
+		ClientRequestToken: aws.String(snapshotName),
+		FileSystemId:       aws.String(backupOptions.FileSystemId),
+		Tags:               tags,
+	}
+
+	output, err := c.fsx.CreateBackup(ctx, input)
+	if err != nil {
+		if isFileSystemNotFound(err) {
+			return nil, ErrNotFound
 		}
+		if isIncompatibleParameter(err) {
+			return nil, ErrBackupExistsDiffFs
+		}
+		return nil, fmt.Errorf("CreateBackup failed: %v", err)
 	}
 
-	return nil, fmt.Errorf("there is no update with storage capacity of %d GiB on filesystem %s", resizeGiB, fileSystemId)
+	return newBackup(output.Backup), nil
 }
 
-func isFileSystemNotFound(err error) bool {
-	var notFound *types.FileSystemNotFound
-	return errors.As(err, &notFound)
-}
+func (c *cloud) DeleteBackup(ctx context.Context, backupId string) error {
+	input := &fsx.DeleteBackupInput{
+		BackupId: aws.String(backupId),
+	}
+	if _, err := c.fsx.DeleteBackup(ctx, input); err != nil {
+		if isBackupNotFound(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("DeleteBackup failed: %v", err)
+	}
+	return nil
+}
+
+func (c *cloud) DescribeBackup(ctx context.Context, backupId string) (*Backup, error) {
+	input := &fsx.DescribeBackupsInput{
+		BackupIds: []string{backupId},
+	}
+
+	output, err := c.fsx.DescribeBackups(ctx, input)
+	if err != nil {
+		if isBackupNotFound(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	if len(output.Backups) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return newBackup(&output.Backups[0]), nil
+}
+
+func (c *cloud) WaitForBackupAvailable(ctx context.Context, backupId string) error {
+	err := wait.PollImmediate(PollCheckInterval, PollCheckTimeout, func() (done bool, err error) {
+		backup, err := c.DescribeBackup(ctx, backupId)
+		if err != nil {
+			return true, err
+		}
+		klog.V(2).InfoS("WaitForBackupAvailable", "backup", backupId, "status", backup.Lifecycle)
+		switch backup.Lifecycle {
+		case "AVAILABLE":
+			return true, nil
+		case "CREATING", "PENDING", "TRANSFERRING":
+			return false, nil
+		case "FAILED":
+			return true, fmt.Errorf("backup %s failed: %s", backupId, backup.FailureMessage)
+		default:
+			return true, fmt.Errorf("unexpected state for backup %s: %q", backupId, backup.Lifecycle)
+		}
+	})
+
+	return err
+}
+
+// ListBackups returns a page of the user-initiated FSx for Lustre backups, optionally restricted to the backups of the
+// given filesystem, along with the token to retrieve the next page.
+func (c *cloud) ListBackups(ctx context.Context, fileSystemId string, maxResults int32, nextToken string) ([]*Backup, string, error) {
+	input := &fsx.DescribeBackupsInput{
+		Filters: []types.Filter{
+			{
+				Name:   types.FilterNameFileSystemType,
+				Values: []string{string(types.FileSystemTypeLustre)},
+			},
+			{
+				Name:   types.FilterNameBackupType,
+				Values: []string{string(types.BackupTypeUserInitiated)},
+			},
+		},
+	}
+	if fileSystemId != "" {
+		input.Filters = append(input.Filters, types.Filter{
+			Name:   types.FilterNameFileSystemId,
+			Values: []string{fileSystemId},
+		})
+	}
+	if maxResults > 0 {
+		input.MaxResults = aws.Int32(maxResults)
+	}
+	if nextToken != "" {
+		input.NextToken = aws.String(nextToken)
+	}
+
+	output, err := c.fsx.DescribeBackups(ctx, input)
+	if err != nil {
+		return nil, "", fmt.Errorf("DescribeBackups failed: %v", err)
+	}
+
+	backups := make([]*Backup, 0, len(output.Backups))
+	for i := range output.Backups {
+		backups = append(backups, newBackup(&output.Backups[i]))
+	}
+
+	return backups, aws.ToString(output.NextToken), nil
+}
+
+// FindCloneBackup returns the intermediate backup of the filesystem that was created for the clone with the volume
+// name. Backups cannot be filtered by tag, so the backups of the filesystem are paged through.
+func (c *cloud) FindCloneBackup(ctx context.Context, fileSystemId string, volumeName string) (*Backup, error) {
+	input := &fsx.DescribeBackupsInput{
+		Filters: []types.Filter{
+			{
+				Name:   types.FilterNameFileSystemId,
+				Values: []string{fileSystemId},
+			},
+			{
+				Name:   types.FilterNameBackupType,
+				Values: []string{string(types.BackupTypeUserInitiated)},
+			},
+		},
+	}
+
+	for {
+		output, err := c.fsx.DescribeBackups(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("DescribeBackups failed: %v", err)
+		}
+		for i := range output.Backups {
+			for _, tag := range output.Backups[i].Tags {
+				if aws.ToString(tag.Key) == CloneVolumeNameTagKey && aws.ToString(tag.Value) == volumeName {
+					return newBackup(&output.Backups[i]), nil
+				}
+			}
+		}
+		if aws.ToString(output.NextToken) == "" {
+			return nil, ErrNotFound
+		}
+		input.NextToken = output.NextToken
+	}
+}
+
+func (c *cloud) CreateDataRepositoryAssociation(ctx context.Context, fileSystemId string, associationOptions *DataRepositoryAssociationOptions) (*DataRepositoryAssociation, error) {
+	input := &fsx.CreateDataRepositoryAssociationInput{
+		FileSystemId:       aws.String(fileSystemId),
+		FileSystemPath:     aws.String(associationOptions.FileSystemPath),
+		DataRepositoryPath: aws.String(associationOptions.DataRepositoryPath),
+	}
+
+	if associationOptions.BatchImportMetaDataOnCreate {
+		input.BatchImportMetaDataOnCreate = aws.Bool(true)
+	}
+
+	if associationOptions.ImportedFileChunkSize > 0 {
+		input.ImportedFileChunkSize = aws.Int32(associationOptions.ImportedFileChunkSize)
+	}
+
+	if len(associationOptions.AutoImportEvents) > 0 || len(associationOptions.AutoExportEvents) > 0 {
+		input.S3 = &types.S3DataRepositoryConfiguration{}
+		if len(associationOptions.AutoImportEvents) > 0 {
+			input.S3.AutoImportPolicy = &types.AutoImportPolicy{Events: newEventTypes(associationOptions.AutoImportEvents)}
+		}
+		if len(associationOptions.AutoExportEvents) > 0 {
+			input.S3.AutoExportPolicy = &types.AutoExportPolicy{Events: newEventTypes(associationOptions.AutoExportEvents)}
+		}
+	}
+
+	output, err := c.fsx.CreateDataRepositoryAssociation(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("CreateDataRepositoryAssociation failed: %v", err)
+	}
+
+	return newDataRepositoryAssociation(output.Association), nil
+}
+
+func (c *cloud) DescribeDataRepositoryAssociation(ctx context.Context, associationId string) (*DataRepositoryAssociation, error) {
+	input := &fsx.DescribeDataRepositoryAssociationsInput{
+		AssociationIds: []string{associationId},
+	}
+
+	output, err := c.fsx.DescribeDataRepositoryAssociations(ctx, input)
+	if err != nil {
+		if isDataRepositoryAssociationNotFound(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	if len(output.Associations) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return newDataRepositoryAssociation(&output.Associations[0]), nil
+}
+
+func (c *cloud) WaitForDataRepositoryAssociationAvailable(ctx context.Context, associationId string) error {
+	err := wait.PollImmediate(PollCheckInterval, PollCheckTimeout, func() (done bool, err error) {
+		association, err := c.DescribeDataRepositoryAssociation(ctx, associationId)
+		if err != nil {
+			return true, err
+		}
+		klog.V(2).InfoS("WaitForDataRepositoryAssociationAvailable", "association", associationId, "status", association.Lifecycle)
+		switch association.Lifecycle {
+		case "AVAILABLE":
+			return true, nil
+		case "CREATING", "UPDATING":
+			return false, nil
+		case "FAILED", "MISCONFIGURED":
+			return true, fmt.Errorf("data repository association %s is %s: %s", associationId, association.Lifecycle, association.FailureMessage)
+		default:
+			return true, fmt.Errorf("unexpected state for data repository association %s: %q", associationId, association.Lifecycle)
+		}
+	})
+
+	return err
+}
+
+// ListDataRepositoryAssociations returns all data repository associations of the given filesystem.
+func (c *cloud) ListDataRepositoryAssociations(ctx context.Context, fileSystemId string) ([]*DataRepositoryAssociation, error) {
+	input := &fsx.DescribeDataRepositoryAssociationsInput{
+		Filters: []types.Filter{
+			{
+				Name:   types.FilterNameFileSystemId,
+				Values: []string{fileSystemId},
+			},
+		},
+	}
+
+	var associations []*DataRepositoryAssociation
+	paginator := fsx.NewDescribeDataRepositoryAssociationsPaginator(c.fsx, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("DescribeDataRepositoryAssociations failed: %v", err)
+		}
+		for i := range output.Associations {
+			associations = append(associations, newDataRepositoryAssociation(&output.Associations[i]))
+		}
+	}
+
+	return associations, nil
+}
+
+// CreateDataRepositoryTask starts a task of the given type on all data repositories of the filesystem. No completion
+// report is written.
+func (c *cloud) CreateDataRepositoryTask(ctx context.Context, fileSystemId string, taskType string) (*DataRepositoryTask, error) {
+	input := &fsx.CreateDataRepositoryTaskInput{
+		FileSystemId: aws.String(fileSystemId),
+		Type:         types.DataRepositoryTaskType(taskType),
+		Report: &types.CompletionReport{
+			Enabled: aws.Bool(false),
+		},
+	}
+
+	output, err := c.fsx.CreateDataRepositoryTask(ctx, input)
+	if err != nil {
+		if isFileSystemNotFound(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("CreateDataRepositoryTask failed: %v", err)
+	}
+
+	return newDataRepositoryTask(output.DataRepositoryTask), nil
+}
+
+func (c *cloud) DescribeDataRepositoryTask(ctx context.Context, taskId string) (*DataRepositoryTask, error) {
+	input := &fsx.DescribeDataRepositoryTasksInput{
+		TaskIds: []string{taskId},
+	}
+
+	output, err := c.fsx.DescribeDataRepositoryTasks(ctx, input)
+	if err != nil {
+		if isDataRepositoryTaskNotFound(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	if len(output.DataRepositoryTasks) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return newDataRepositoryTask(&output.DataRepositoryTasks[0]), nil
+}
+
+// WaitForDataRepositoryTask waits for a data repository task to finish and returns an error if it did not succeed.
+func (c *cloud) WaitForDataRepositoryTask(ctx context.Context, taskId string) error {
+	err := wait.PollImmediate(PollCheckInterval, PollCheckTimeout, func() (done bool, err error) {
+		task, err := c.DescribeDataRepositoryTask(ctx, taskId)
+		if err != nil {
+			return true, err
+		}
+		klog.V(2).InfoS("WaitForDataRepositoryTask", "task", taskId, "status", task.Lifecycle)
+		switch task.Lifecycle {
+		case "SUCCEEDED":
+			return true, nil
+		case "PENDING", "EXECUTING":
+			return false, nil
+		case "FAILED", "CANCELED", "CANCELING":
+			return true, fmt.Errorf("data repository task %s is %s: %s", taskId, task.Lifecycle, task.FailureMessage)
+		default:
+			return true, fmt.Errorf("unexpected state for data repository task %s: %q", taskId, task.Lifecycle)
+		}
+	})
+
+	return err
+}
+
+// ListDataRepositoryTasks returns all data repository tasks of the given filesystem.
+func (c *cloud) ListDataRepositoryTasks(ctx context.Context, fileSystemId string) ([]*DataRepositoryTask, error) {
+	input := &fsx.DescribeDataRepositoryTasksInput{
+		Filters: []types.DataRepositoryTaskFilter{
+			{
+				Name:   types.DataRepositoryTaskFilterNameFileSystemId,
+				Values: []string{fileSystemId},
+			},
+		},
+	}
+
+	var tasks []*DataRepositoryTask
+	paginator := fsx.NewDescribeDataRepositoryTasksPaginator(c.fsx, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("DescribeDataRepositoryTasks failed: %v", err)
+		}
+		for i := range output.DataRepositoryTasks {
+			tasks = append(tasks, newDataRepositoryTask(&output.DataRepositoryTasks[i]))
+		}
+	}
+
+	return tasks, nil
+}
+
+func (c *cloud) DescribeSubnet(ctx context.Context, subnetId string) (*Subnet, error) {
+	input := &ec2.DescribeSubnetsInput{
+		SubnetIds: []string{subnetId},
+	}
+
+	output, err := c.ec2.DescribeSubnets(ctx, input)
+	if err != nil {
+		if isSubnetNotFound(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("DescribeSubnets failed: %v", err)
+	}
+
+	if len(output.Subnets) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return newSubnet(&output.Subnets[0]), nil
+}
+
+// FindSubnetsByTags returns all subnets that carry the given tags. A tag with an empty value matches any subnet that
+// has the tag key, regardless of its value.
+func (c *cloud) FindSubnetsByTags(ctx context.Context, tags map[string]string) ([]*Subnet, error) {
+	input := &ec2.DescribeSubnetsInput{
+		Filters: newTagFilters(tags),
+	}
+
+	var subnets []*Subnet
+	paginator := ec2.NewDescribeSubnetsPaginator(c.ec2, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("DescribeSubnets failed: %v", err)
+		}
+		for i := range output.Subnets {
+			subnets = append(subnets, newSubnet(&output.Subnets[i]))
+		}
+	}
+
+	return subnets, nil
+}
+
+// FindSecurityGroupsByTags returns the IDs of all security groups in the VPC that carry the given tags. A tag with an
+// empty value matches any security group that has the tag key, regardless of its value.
+func (c *cloud) FindSecurityGroupsByTags(ctx context.Context, vpcId string, tags map[string]string) ([]string, error) {
+	filters := append(newTagFilters(tags), ec2types.Filter{
+		Name:   aws.String("vpc-id"),
+		Values: []string{vpcId},
+	})
+	return c.findSecurityGroups(ctx, filters)
+}
+
+// EnsureClusterSecurityGroup returns the security group that the driver manages for a cluster in the VPC, creating it
+// if it does not exist yet. A new security group allows the Lustre ports from itself and from the source security
+// groups, which are usually the security groups of the cluster's nodes. The security group is tagged with the time of
+// its use, so that it is not garbage collected before the filesystem that uses it creates its network interfaces.
+func (c *cloud) EnsureClusterSecurityGroup(ctx context.Context, clusterName string, vpcId string, sourceSecurityGroupIds []string) (string, error) {
+	groupIds, err := c.FindSecurityGroupsByTags(ctx, vpcId, map[string]string{ClusterSecurityGroupTagKey: clusterName})
+	if err != nil {
+		return "", err
+	}
+	if len(groupIds) > 0 {
+		if err := c.markSecurityGroupUsed(ctx, groupIds[0]); err != nil {
+			return "", err
+		}
+		return groupIds[0], nil
+	}
+
+	groupName := fmt.Sprintf("fsx-lustre-%s", clusterName)
+	input := &ec2.CreateSecurityGroupInput{
+		GroupName:   aws.String(groupName),
+		Description: aws.String(fmt.Sprintf("FSx for Lustre filesystems of cluster %s", clusterName)),
+		VpcId:       aws.String(vpcId),
+		TagSpecifications: []ec2types.TagSpecification{
+			{
+				ResourceType: ec2types.ResourceTypeSecurityGroup,
+				Tags: []ec2types.Tag{
+					{
+						Key:   aws.String(ClusterSecurityGroupTagKey),
+						Value: aws.String(clusterName),
+					},
+					{
+						Key:   aws.String("Name"),
+						Value: aws.String(groupName),
+					},
+					{
+						Key:   aws.String(ClusterSecurityGroupLastUsedTagKey),
+						Value: aws.String(time.Now().UTC().Format(time.RFC3339)),
+					},
+				},
+			},
+		},
+	}
+
+	output, err := c.ec2.CreateSecurityGroup(ctx, input)
+	if err != nil {
+		if isSecurityGroupDuplicate(err) {
+			// The security group may have been created by a concurrent request
+			groupIds, findErr := c.FindSecurityGroupsByTags(ctx, vpcId, map[string]string{ClusterSecurityGroupTagKey: clusterName})
+			if findErr == nil && len(groupIds) > 0 {
+				if err := c.markSecurityGroupUsed(ctx, groupIds[0]); err != nil {
+					return "", err
+				}
+				return groupIds[0], nil
+			}
+		}
+		return "", fmt.Errorf("CreateSecurityGroup failed: %v", err)
+	}
+	groupId := aws.ToString(output.GroupId)
+	klog.V(2).InfoS("Created security group", "groupId", groupId, "clusterName", clusterName, "vpcId", vpcId)
+
+	groupPairs := []ec2types.UserIdGroupPair{{GroupId: aws.String(groupId)}}
+	for _, sourceGroupId := range sourceSecurityGroupIds {
+		groupPairs = append(groupPairs, ec2types.UserIdGroupPair{GroupId: aws.String(sourceGroupId)})
+	}
+	permissions := make([]ec2types.IpPermission, 0, len(LustrePortRanges))
+	for _, portRange := range LustrePortRanges {
+		permissions = append(permissions, ec2types.IpPermission{
+			IpProtocol:       aws.String("tcp"),
+			FromPort:         aws.Int32(portRange[0]),
+			ToPort:           aws.Int32(portRange[1]),
+			UserIdGroupPairs: groupPairs,
+		})
+	}
+
+	_, err = c.ec2.AuthorizeSecurityGroupIngress(ctx, &ec2.AuthorizeSecurityGroupIngressInput{
+		GroupId:       aws.String(groupId),
+		IpPermissions: permissions,
+	})
+	if err != nil {
+		// Do not leave a security group behind that would be found without its rules on the next attempt
+		if _, deleteErr := c.ec2.DeleteSecurityGroup(ctx, &ec2.DeleteSecurityGroupInput{GroupId: aws.String(groupId)}); deleteErr != nil {
+			klog.ErrorS(deleteErr, "Could not delete security group", "groupId", groupId)
+		}
+		return "", fmt.Errorf("AuthorizeSecurityGroupIngress failed: %v", err)
+	}
+
+	return groupId, nil
+}
+
+// markSecurityGroupUsed tags the security group with the current time as the time of its last use
+func (c *cloud) markSecurityGroupUsed(ctx context.Context, groupId string) error {
+	_, err := c.ec2.CreateTags(ctx, &ec2.CreateTagsInput{
+		Resources: []string{groupId},
+		Tags: []ec2types.Tag{
+			{
+				Key:   aws.String(ClusterSecurityGroupLastUsedTagKey),
+				Value: aws.String(time.Now().UTC().Format(time.RFC3339)),
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("CreateTags failed: %v", err)
+	}
+	return nil
+}
+
+// DeleteUnusedClusterSecurityGroups deletes the security groups that the driver manages for a cluster and that are no
+// longer attached to any network interface, i.e. used by any filesystem. Security groups that were used within
+// clusterSecurityGroupInUseGracePeriod are kept, a filesystem that is being created with them may not have created
+// its network interfaces yet.
+func (c *cloud) DeleteUnusedClusterSecurityGroups(ctx context.Context, clusterName string) error {
+	groups, err := c.describeSecurityGroups(ctx, newTagFilters(map[string]string{ClusterSecurityGroupTagKey: clusterName}))
+	if err != nil {
+		return err
+	}
+
+	for _, group := range groups {
+		groupId := aws.ToString(group.GroupId)
+		if lastUsed, ok := securityGroupLastUsed(group); ok && time.Since(lastUsed) < clusterSecurityGroupInUseGracePeriod {
+			klog.V(4).InfoS("Keeping recently used security group", "groupId", groupId, "lastUsed", lastUsed)
+			continue
+		}
+
+		output, err := c.ec2.DescribeNetworkInterfaces(ctx, &ec2.DescribeNetworkInterfacesInput{
+			Filters: []ec2types.Filter{
+				{
+					Name:   aws.String("group-id"),
+					Values: []string{groupId},
+				},
+			},
+			MaxResults: aws.Int32(5),
+		})
+		if err != nil {
+			return fmt.Errorf("DescribeNetworkInterfaces failed: %v", err)
+		}
+		if len(output.NetworkInterfaces) > 0 {
+			continue
+		}
+
+		if _, err = c.ec2.DeleteSecurityGroup(ctx, &ec2.DeleteSecurityGroupInput{GroupId: aws.String(groupId)}); err != nil {
+			if isDependencyViolation(err) {
+				// A filesystem started using the security group in the meantime
+				continue
+			}
+			return fmt.Errorf("DeleteSecurityGroup failed: %v", err)
+		}
+		klog.V(2).InfoS("Deleted unused security group", "groupId", groupId, "clusterName", clusterName)
+	}
+
+	return nil
+}
+
+func (c *cloud) findSecurityGroups(ctx context.Context, filters []ec2types.Filter) ([]string, error) {
+	groups, err := c.describeSecurityGroups(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	var groupIds []string
+	for _, group := range groups {
+		groupIds = append(groupIds, aws.ToString(group.GroupId))
+	}
+
+	return groupIds, nil
+}
+
+func (c *cloud) describeSecurityGroups(ctx context.Context, filters []ec2types.Filter) ([]ec2types.SecurityGroup, error) {
+	input := &ec2.DescribeSecurityGroupsInput{
+		Filters: filters,
+	}
+
+	var groups []ec2types.SecurityGroup
+	paginator := ec2.NewDescribeSecurityGroupsPaginator(c.ec2, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("DescribeSecurityGroups failed: %v", err)
+		}
+		groups = append(groups, output.SecurityGroups...)
+	}
+
+	return groups, nil
+}
+
+// securityGroupLastUsed returns the time of the last use of a cluster security group from its tags
+func securityGroupLastUsed(group ec2types.SecurityGroup) (time.Time, bool) {
+	for _, tag := range group.Tags {
+		if aws.ToString(tag.Key) != ClusterSecurityGroupLastUsedTagKey {
+			continue
+		}
+		lastUsed, err := time.Parse(time.RFC3339, aws.ToString(tag.Value))
+		return lastUsed, err == nil
+	}
+	return time.Time{}, false
+}
+
+// newTagFilters builds EC2 filters that match resources carrying the given tags. A tag with an empty value only
+// requires the tag key to be present.
+func newTagFilters(tags map[string]string) []ec2types.Filter {
+	filters := make([]ec2types.Filter, 0, len(tags))
+	for key, value := range tags {
+		if value == "" {
+			filters = append(filters, ec2types.Filter{
+				Name:   aws.String("tag-key"),
+				Values: []string{key},
+			})
+			continue
+		}
+		filters = append(filters, ec2types.Filter{
+			Name:   aws.String("tag:" + key),
+			Values: []string{value},
+		})
+	}
+	return filters
+}
+
+func (c *cloud) getFileSystem(ctx context.Context, fileSystemId string) (*types.FileSystem, error) {
+	input := &fsx.DescribeFileSystemsInput{
+		FileSystemIds: []string{fileSystemId},
+	}
+
+	output, err := c.fsx.DescribeFileSystems(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(output.FileSystems) > 1 {
+		return nil, ErrMultiFileSystems
+	}
+
+	if len(output.FileSystems) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return &(output.FileSystems[0]), nil
+}
+
+// getUpdateResizeAdministrativeAction retrieves the AdministrativeAction associated with a file system update with the
+// given target storage capacity, if one exists.
+func (c *cloud) getUpdateResizeAdministrativeAction(ctx context.Context, fileSystemId string, resizeGiB int32) (*types.AdministrativeAction, error) {
+	fs, err := c.getFileSystem(ctx, fileSystemId)
+	if err != nil {
+		return nil, fmt.Errorf("DescribeFileSystems failed: %v", err)
+	}
+
+	if len(fs.AdministrativeActions) == 0 {
+		return nil, fmt.Errorf("there is no update on filesystem %s", fileSystemId)
+	}
+
+	// AdministrativeAction items are ordered by newest to oldest start time, so use the first satisfactory target
+	// storage capacity match
+	for _, action := range fs.AdministrativeActions {
+		if action.AdministrativeActionType == "FILE_SYSTEM_UPDATE" &&
+			action.TargetFileSystemValues.StorageCapacity != nil &&
+			*action.TargetFileSystemValues.StorageCapacity == resizeGiB {
+			return &action, nil
+		}
+	}
+
+	return nil, fmt.Errorf("there is no update with storage capacity of %d GiB on filesystem %s", resizeGiB, fileSystemId)
+}
+
+// getUpdateAdministrativeAction finds the latest FILE_SYSTEM_UPDATE administrative action whose target Lustre
+// configuration matches every value set in updateOptions.
+func (c *cloud) getUpdateAdministrativeAction(ctx context.Context, fileSystemId string, updateOptions *FileSystemUpdateOptions) (*types.AdministrativeAction, error) {
+	fs, err := c.getFileSystem(ctx, fileSystemId)
+	if err != nil {
+		return nil, fmt.Errorf("DescribeFileSystems failed: %v", err)
+	}
+
+	if len(fs.AdministrativeActions) == 0 {
+		return nil, fmt.Errorf("there is no update on filesystem %s", fileSystemId)
+	}
+
+	// AdministrativeAction items are ordered by newest to oldest start time, so use the first match
+	for _, action := range fs.AdministrativeActions {
+		if action.AdministrativeActionType == "FILE_SYSTEM_UPDATE" &&
+			action.TargetFileSystemValues != nil &&
+			action.TargetFileSystemValues.LustreConfiguration != nil &&
+			matchesUpdateOptions(action.TargetFileSystemValues.LustreConfiguration, updateOptions) {
+			return &action, nil
+		}
+	}
+
+	return nil, fmt.Errorf("there is no matching update on filesystem %s", fileSystemId)
+}
+
+// hasAdministrativeUpdate reports whether updateOptions change settings that FSx applies with a FILE_SYSTEM_UPDATE
+// administrative action. Backup and maintenance window settings are applied immediately.
+func hasAdministrativeUpdate(updateOptions *FileSystemUpdateOptions) bool {
+	return updateOptions.PerUnitStorageThroughput != 0 ||
+		updateOptions.DataCompressionType != "" ||
+		updateOptions.MetadataConfigurationMode != "" ||
+		updateOptions.MetadataIops != 0
+}
+
+// verifyImmediateUpdate checks that the filesystem has the backup and maintenance window settings of updateOptions
+func (c *cloud) verifyImmediateUpdate(ctx context.Context, fileSystemId string, updateOptions *FileSystemUpdateOptions) error {
+	fs, err := c.getFileSystem(ctx, fileSystemId)
+	if err != nil {
+		return fmt.Errorf("DescribeFileSystems failed: %v", err)
+	}
+	if fs.LustreConfiguration == nil || !matchesImmediateUpdateOptions(fs.LustreConfiguration, updateOptions) {
+		return fmt.Errorf("filesystem %s does not have the requested backup and maintenance settings", fileSystemId)
+	}
+	return nil
+}
+
+// matchesUpdateOptions reports whether the target Lustre configuration of a FILE_SYSTEM_UPDATE administrative action
+// carries every value set in updateOptions that is applied with the action.
+func matchesUpdateOptions(lustreConfiguration *types.LustreFileSystemConfiguration, updateOptions *FileSystemUpdateOptions) bool {
+	if updateOptions.PerUnitStorageThroughput != 0 &&
+		aws.ToInt32(lustreConfiguration.PerUnitStorageThroughput) != updateOptions.PerUnitStorageThroughput {
+		return false
+	}
+
+	if updateOptions.DataCompressionType != "" &&
+		string(lustreConfiguration.DataCompressionType) != updateOptions.DataCompressionType {
+		return false
+	}
+
+	if updateOptions.MetadataConfigurationMode != "" || updateOptions.MetadataIops != 0 {
+		metadataConfiguration := lustreConfiguration.MetadataConfiguration
+		if metadataConfiguration == nil {
+			return false
+		}
+		if updateOptions.MetadataConfigurationMode != "" &&
+			string(metadataConfiguration.Mode) != updateOptions.MetadataConfigurationMode {
+			return false
+		}
+		if updateOptions.MetadataIops != 0 && aws.ToInt32(metadataConfiguration.Iops) != updateOptions.MetadataIops {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchesImmediateUpdateOptions reports whether a Lustre configuration carries every backup and maintenance window
+// value set in updateOptions.
+func matchesImmediateUpdateOptions(lustreConfiguration *types.LustreFileSystemConfiguration, updateOptions *FileSystemUpdateOptions) bool {
+	if updateOptions.AutomaticBackupRetentionDays != nil &&
+		aws.ToInt32(lustreConfiguration.AutomaticBackupRetentionDays) != *updateOptions.AutomaticBackupRetentionDays {
+		return false
+	}
+
+	if updateOptions.DailyAutomaticBackupStartTime != "" &&
+		aws.ToString(lustreConfiguration.DailyAutomaticBackupStartTime) != updateOptions.DailyAutomaticBackupStartTime {
+		return false
+	}
+
+	if updateOptions.WeeklyMaintenanceStartTime != "" &&
+		aws.ToString(lustreConfiguration.WeeklyMaintenanceStartTime) != updateOptions.WeeklyMaintenanceStartTime {
+		return false
+	}
+
+	return true
+}
+
+func isFileSystemNotFound(err error) bool {
+	var notFound *types.FileSystemNotFound
+	return errors.As(err, &notFound)
+}
+
+// isSubnetNotFound identifies an error returned from the EC2 API when a subnet does not exist.
+func isSubnetNotFound(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "InvalidSubnetID.NotFound"
+}
+
+func isDataRepositoryAssociationNotFound(err error) bool {
+	var notFound *types.DataRepositoryAssociationNotFound
+	return errors.As(err, &notFound)
+}
+
+func isDataRepositoryTaskNotFound(err error) bool {
+	var notFound *types.DataRepositoryTaskNotFound
+	return errors.As(err, &notFound)
+}
+
+func isSecurityGroupDuplicate(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "InvalidGroup.Duplicate"
+}
+
+func isDependencyViolation(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "DependencyViolation"
+}
+
+func isBackupNotFound(err error) bool {
+	var notFound *types.BackupNotFound
+	return errors.As(err, &notFound)
+}
+
+// isIncompatibleParameter identifies an error returned from the FSx API when a request reuses a client request token
+// with different parameters.
+func isIncompatibleParameter(err error) bool {
+	var incompatibleParameter *types.IncompatibleParameterError
+	return errors.As(err, &incompatibleParameter)
+}
 
 // isBadRequestUpdateInProgress identifies an error returned from the FSx API as a BadRequest with an "update already
 // in progress" message.
 func isBadRequestUpdateInProgress(err error) bool {
 	var badRequest *types.BadRequest
-	return errors.As(err, &badRequest) && strings.Contains(err.Error(), "Unable to perform the storage capacity update. There is an update already in progress.")
+	return errors.As(err, &badRequest) && strings.Contains(err.Error(), "There is an update already in progress.")
+}
+
+// newExtraTags converts a list of key=value pairs into FSx tags.
+func newExtraTags(extraTags []string) []types.Tag {
+	var tags []types.Tag
+	for _, extraTag := range extraTags {
+		extraTagSplit := strings.Split(extraTag, "=")
+		tagKey := extraTagSplit[0]
+		tagValue := extraTagSplit[1]
+
+		tags = append(tags, types.Tag{
+			Key:   aws.String(tagKey),
+			Value: aws.String(tagValue),
+		})
+	}
+	return tags
+}
+
+// newCreateFileSystemLustreConfiguration builds the Lustre configuration shared by new and restored filesystems.
+func newCreateFileSystemLustreConfiguration(fileSystemOptions *FileSystemOptions) *types.CreateFileSystemLustreConfiguration {
+	lustreConfiguration := &types.CreateFileSystemLustreConfiguration{}
+
+	if fileSystemOptions.AutoImportPolicy != "" {
+		lustreConfiguration.AutoImportPolicy = types.AutoImportPolicyType(fileSystemOptions.AutoImportPolicy)
+	}
+
+	if fileSystemOptions.S3ImportPath != "" {
+		lustreConfiguration.ImportPath = aws.String(fileSystemOptions.S3ImportPath)
+	}
+
+	if fileSystemOptions.S3ExportPath != "" {
+		lustreConfiguration.ExportPath = aws.String(fileSystemOptions.S3ExportPath)
+	}
+
+	if fileSystemOptions.DeploymentType != "" {
+		lustreConfiguration.DeploymentType = types.LustreDeploymentType(fileSystemOptions.DeploymentType)
+	}
+
+	if fileSystemOptions.DriveCacheType != "" {
+		lustreConfiguration.DriveCacheType = types.DriveCacheType(fileSystemOptions.DriveCacheType)
+	}
+
+	if fileSystemOptions.PerUnitStorageThroughput != 0 {
+		lustreConfiguration.PerUnitStorageThroughput = aws.Int32(fileSystemOptions.PerUnitStorageThroughput)
+	}
+
+	if fileSystemOptions.AutomaticBackupRetentionDays != 0 {
+		lustreConfiguration.AutomaticBackupRetentionDays = aws.Int32(fileSystemOptions.AutomaticBackupRetentionDays)
+		if fileSystemOptions.DailyAutomaticBackupStartTime != "" {
+			lustreConfiguration.DailyAutomaticBackupStartTime = aws.String(fileSystemOptions.DailyAutomaticBackupStartTime)
+		}
+	}
+
+	if fileSystemOptions.CopyTagsToBackups {
+		lustreConfiguration.CopyTagsToBackups = aws.Bool(true)
+	}
+
+	if fileSystemOptions.DataCompressionType != "" {
+		lustreConfiguration.DataCompressionType = types.DataCompressionType(fileSystemOptions.DataCompressionType)
+	}
+
+	if fileSystemOptions.WeeklyMaintenanceStartTime != "" {
+		lustreConfiguration.WeeklyMaintenanceStartTime = aws.String(fileSystemOptions.WeeklyMaintenanceStartTime)
+	}
+
+	if fileSystemOptions.EfaEnabled {
+		lustreConfiguration.EfaEnabled = aws.Bool(true)
+	}
+
+	if fileSystemOptions.MetadataConfigurationMode != "" {
+		metadataConfiguration := &types.CreateFileSystemLustreMetadataConfiguration{}
+		metadataConfiguration.Mode = types.MetadataConfigurationMode(fileSystemOptions.MetadataConfigurationMode)
+		if fileSystemOptions.MetadataIops != 0 {
+			metadataConfiguration.Iops = aws.Int32(fileSystemOptions.MetadataIops)
+		}
+		lustreConfiguration.MetadataConfiguration = metadataConfiguration
+	}
+
+	return lustreConfiguration
+}
+
+func newFileSystem(fs *types.FileSystem) *FileSystem {
+	mountName := "fsx"
+	if fs.LustreConfiguration.MountName != nil {
+		mountName = *fs.LustreConfiguration.MountName
+	}
+
+	subnetId := ""
+	if len(fs.SubnetIds) > 0 {
+		subnetId = fs.SubnetIds[0]
+	}
+
+	perUnitStorageThroughput := int32(0)
+	if fs.LustreConfiguration.PerUnitStorageThroughput != nil {
+		perUnitStorageThroughput = *fs.LustreConfiguration.PerUnitStorageThroughput
+	}
+
+	fileSystem := &FileSystem{
+		FileSystemId:                  *fs.FileSystemId,
+		CapacityGiB:                   *fs.StorageCapacity,
+		DnsName:                       *fs.DNSName,
+		MountName:                     mountName,
+		SubnetId:                      subnetId,
+		StorageType:                   string(fs.StorageType),
+		DeploymentType:                string(fs.LustreConfiguration.DeploymentType),
+		PerUnitStorageThroughput:      perUnitStorageThroughput,
+		DataCompressionType:           string(fs.LustreConfiguration.DataCompressionType),
+		AutomaticBackupRetentionDays:  aws.ToInt32(fs.LustreConfiguration.AutomaticBackupRetentionDays),
+		DailyAutomaticBackupStartTime: aws.ToString(fs.LustreConfiguration.DailyAutomaticBackupStartTime),
+		WeeklyMaintenanceStartTime:    aws.ToString(fs.LustreConfiguration.WeeklyMaintenanceStartTime),
+		Lifecycle:                     string(fs.Lifecycle),
+	}
+
+	if len(fs.Tags) > 0 {
+		fileSystem.Tags = make(map[string]string, len(fs.Tags))
+		for _, tag := range fs.Tags {
+			fileSystem.Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+	}
+
+	if metadataConfiguration := fs.LustreConfiguration.MetadataConfiguration; metadataConfiguration != nil {
+		fileSystem.MetadataConfigurationMode = string(metadataConfiguration.Mode)
+		fileSystem.MetadataIops = aws.ToInt32(metadataConfiguration.Iops)
+	}
+
+	if fs.FailureDetails != nil {
+		fileSystem.FailureMessage = aws.ToString(fs.FailureDetails.Message)
+	}
+
+	for _, action := range fs.AdministrativeActions {
+		administrativeAction := AdministrativeAction{
+			ActionType: string(action.AdministrativeActionType),
+			Status:     string(action.Status),
+		}
+		if action.RequestTime != nil {
+			administrativeAction.RequestTime = *action.RequestTime
+		}
+		if action.FailureDetails != nil {
+			administrativeAction.FailureMessage = aws.ToString(action.FailureDetails.Message)
+		}
+		fileSystem.AdministrativeActions = append(fileSystem.AdministrativeActions, administrativeAction)
+	}
+
+	return fileSystem
+}
+
+func newDataRepositoryAssociation(association *types.DataRepositoryAssociation) *DataRepositoryAssociation {
+	a := &DataRepositoryAssociation{
+		AssociationId:      aws.ToString(association.AssociationId),
+		FileSystemId:       aws.ToString(association.FileSystemId),
+		FileSystemPath:     aws.ToString(association.FileSystemPath),
+		DataRepositoryPath: aws.ToString(association.DataRepositoryPath),
+		Lifecycle:          string(association.Lifecycle),
+	}
+	if association.FailureDetails != nil {
+		a.FailureMessage = aws.ToString(association.FailureDetails.Message)
+	}
+	return a
+}
+
+func newDataRepositoryTask(task *types.DataRepositoryTask) *DataRepositoryTask {
+	t := &DataRepositoryTask{
+		TaskId:       aws.ToString(task.TaskId),
+		FileSystemId: aws.ToString(task.FileSystemId),
+		Type:         string(task.Type),
+		Lifecycle:    string(task.Lifecycle),
+	}
+	if task.FailureDetails != nil {
+		t.FailureMessage = aws.ToString(task.FailureDetails.Message)
+	}
+	return t
+}
+
+func newEventTypes(events []string) []types.EventType {
+	eventTypes := make([]types.EventType, 0, len(events))
+	for _, event := range events {
+		eventTypes = append(eventTypes, types.EventType(event))
+	}
+	return eventTypes
+}
+
+func newSubnet(subnet *ec2types.Subnet) *Subnet {
+	return &Subnet{
+		SubnetId:                aws.ToString(subnet.SubnetId),
+		VpcId:                   aws.ToString(subnet.VpcId),
+		AvailabilityZone:        aws.ToString(subnet.AvailabilityZone),
+		AvailableIpAddressCount: aws.ToInt32(subnet.AvailableIpAddressCount),
+	}
+}
+
+func newBackup(backup *types.Backup) *Backup {
+	b := &Backup{
+		BackupId:  aws.ToString(backup.BackupId),
+		Lifecycle: string(backup.Lifecycle),
+	}
+
+	if backup.CreationTime != nil {
+		b.CreationTime = *backup.CreationTime
+	}
+
+	if backup.FailureDetails != nil {
+		b.FailureMessage = aws.ToString(backup.FailureDetails.Message)
+	}
+
+	if len(backup.Tags) > 0 {
+		b.Tags = make(map[string]string, len(backup.Tags))
+		for _, tag := range backup.Tags {
+			b.Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+	}
+
+	if fs := backup.FileSystem; fs != nil {
+		b.FileSystemId = aws.ToString(fs.FileSystemId)
+		b.CapacityGiB = aws.ToInt32(fs.StorageCapacity)
+		b.StorageType = string(fs.StorageType)
+		if fs.LustreConfiguration != nil {
+			b.DeploymentType = string(fs.LustreConfiguration.DeploymentType)
+			b.PerUnitStorageThroughput = aws.ToInt32(fs.LustreConfiguration.PerUnitStorageThroughput)
+		}
+	}
+
+	return b
 }
 
 func (c *cloud) FindFileSystemByVolumeName(ctx context.Context, volumeName string) (*FileSystem, error) {
@@ -486,6 +1705,22 @@ func (c *cloud) FindFileSystemByVolumeName(ctx context.Context, volumeName strin
 	return nil, ErrNotFound
 }
 
+// ListFileSystems returns the filesystems created by the driver from the cache, ordered by filesystem ID.
+func (c *cloud) ListFileSystems(ctx context.Context) ([]*FileSystem, error) {
+	c.cacheMutex.RLock()
+	defer c.cacheMutex.RUnlock()
+
+	fileSystems := make([]*FileSystem, 0, len(c.volumeCache))
+	for _, fs := range c.volumeCache {
+		fileSystems = append(fileSystems, fs)
+	}
+	sort.Slice(fileSystems, func(i, j int) bool {
+		return fileSystems[i].FileSystemId < fileSystems[j].FileSystemId
+	})
+
+	return fileSystems, nil
+}
+
 func (c *cloud) pollFileSystems() {
 	for {
 		newCache := make(map[string]*FileSystem)
@@ -502,7 +1737,9 @@ func (c *cloud) pollFileSystems() {
 
 			output, err := c.fsx.DescribeFileSystems(ctx, input)
 			if err != nil {
-				klog.ErrorS(err, "pollFileSystems: failed to describe filesystems")
+				klog.ErrorS(err, "pollFileSystems: failed to describe filesystems, keeping the previous cache")
+				// a partial cache would drop the filesystems of the remaining pages
+				newCache = nil
 				break // break inner loop, sleep and retry
 			}
 
@@ -521,25 +1758,7 @@ func (c *cloud) pollFileSystems() {
 				}
 
 				if volumeName != "" {
-					mountName := "fsx"
-					if fs.LustreConfiguration.MountName != nil {
-						mountName = *fs.LustreConfiguration.MountName
-					}
-
-					perUnitStorageThroughput := int32(0)
-					if fs.LustreConfiguration.PerUnitStorageThroughput != nil {
-						perUnitStorageThroughput = *fs.LustreConfiguration.PerUnitStorageThroughput
-					}
-
-					newCache[volumeName] = &FileSystem{
-						FileSystemId:             *fs.FileSystemId,
-						CapacityGiB:              *fs.StorageCapacity,
-						DnsName:                  *fs.DNSName,
-						MountName:                mountName,
-						StorageType:              string(fs.StorageType),
-						DeploymentType:           string(fs.LustreConfiguration.DeploymentType),
-						PerUnitStorageThroughput: perUnitStorageThroughput,
-					}
+					newCache[volumeName] = newFileSystem(&fs)
 				}
 			}
 
@@ -549,10 +1768,12 @@ func (c *cloud) pollFileSystems() {
 			nextToken = output.NextToken
 		}
 
-		c.cacheMutex.Lock()
-		c.volumeCache = newCache
-		c.cacheMutex.Unlock()
-		klog.V(4).InfoS("pollFileSystems: cache updated", "itemCount", len(newCache))
+		if newCache != nil {
+			c.cacheMutex.Lock()
+			c.volumeCache = newCache
+			c.cacheMutex.Unlock()
+			klog.V(4).InfoS("pollFileSystems: cache updated", "itemCount", len(newCache))
+		}
 
 		time.Sleep(CachePollInterval)
 	}
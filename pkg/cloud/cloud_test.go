@@ -21,10 +21,14 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/aws/aws-sdk-go-v2/service/fsx"
 	"github.com/aws/aws-sdk-go-v2/service/fsx/types"
+	"github.com/aws/smithy-go"
 	"go.uber.org/mock/gomock"
 	"sigs.k8s.io/aws-fsx-csi-driver/pkg/cloud/mocks"
 )
@@ -1028,6 +1032,7 @@ func TestCreateFileSystem(t *testing.T) {
 func TestDeleteFileSystem(t *testing.T) {
 	var (
 		fileSystemId = "fs-1234"
+		backupId     = "backup-1234"
 	)
 	testCases := []struct {
 		name     string
@@ -1046,11 +1051,15 @@ func TestDeleteFileSystem(t *testing.T) {
 				output := &fsx.DeleteFileSystemOutput{}
 				ctx := context.Background()
 				mockFSx.EXPECT().DeleteFileSystem(gomock.Eq(ctx), gomock.Any()).Return(output, nil)
-				err := c.DeleteFileSystem(ctx, fileSystemId)
+				backupId, err := c.DeleteFileSystem(ctx, fileSystemId, nil)
 				if err != nil {
 					t.Fatalf("DeleteFileSystem is failed: %v", err)
 				}
 
+				if backupId != "" {
+					t.Fatalf("Unexpected final backup: %v", backupId)
+				}
+
 				mockCtl.Finish()
 			},
 		},
@@ -1066,11 +1075,77 @@ func TestDeleteFileSystem(t *testing.T) {
 
 				ctx := context.Background()
 				mockFSx.EXPECT().DeleteFileSystem(gomock.Eq(ctx), gomock.Any()).Return(nil, errors.New("DeleteFileSystemWithContext failed"))
-				err := c.DeleteFileSystem(ctx, fileSystemId)
+				_, err := c.DeleteFileSystem(ctx, fileSystemId, nil)
 				if err == nil {
 					t.Fatal("DeleteFileSystem is not failed")
 				}
 
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: final backup",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx:         mockFSx,
+					volumeCache: make(map[string]*FileSystem),
+				}
+
+				deleteOptions := &DeleteFileSystemOptions{
+					FinalBackup:     true,
+					FinalBackupTags: []string{"retention=30d"},
+				}
+				expectedInput := &fsx.DeleteFileSystemInput{
+					FileSystemId: aws.String(fileSystemId),
+					LustreConfiguration: &types.DeleteFileSystemLustreConfiguration{
+						SkipFinalBackup: aws.Bool(false),
+						FinalBackupTags: []types.Tag{
+							{
+								Key:   aws.String("retention"),
+								Value: aws.String("30d"),
+							},
+						},
+					},
+				}
+				output := &fsx.DeleteFileSystemOutput{
+					FileSystemId: aws.String(fileSystemId),
+					LustreResponse: &types.DeleteFileSystemLustreResponse{
+						FinalBackupId: aws.String(backupId),
+					},
+				}
+				ctx := context.Background()
+				mockFSx.EXPECT().DeleteFileSystem(gomock.Eq(ctx), gomock.Eq(expectedInput)).Return(output, nil)
+				actualBackupId, err := c.DeleteFileSystem(ctx, fileSystemId, deleteOptions)
+				if err != nil {
+					t.Fatalf("DeleteFileSystem is failed: %v", err)
+				}
+
+				if actualBackupId != backupId {
+					t.Fatalf("BackupId mismatches. actual: %v expected: %v", actualBackupId, backupId)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: filesystem not found",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx:         mockFSx,
+					volumeCache: make(map[string]*FileSystem),
+				}
+
+				ctx := context.Background()
+				mockFSx.EXPECT().DeleteFileSystem(gomock.Eq(ctx), gomock.Any()).Return(nil, &types.FileSystemNotFound{})
+				_, err := c.DeleteFileSystem(ctx, fileSystemId, nil)
+				if !errors.Is(err, ErrNotFound) {
+					t.Fatalf("DeleteFileSystem is not ErrNotFound: %v", err)
+				}
+
 				mockCtl.Finish()
 			},
 		},
@@ -1170,6 +1245,95 @@ func TestDescribeFileSystem(t *testing.T) {
 				mockCtl.Finish()
 			},
 		},
+		{
+			name: "success: lifecycle and administrative actions",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				requestTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+				output := &fsx.DescribeFileSystemsOutput{
+					FileSystems: []types.FileSystem{
+						{
+							FileSystemId:    aws.String(fileSystemId),
+							StorageCapacity: aws.Int32(volumeSizeGiB),
+							StorageType:     types.StorageTypeSsd,
+							DNSName:         aws.String(dnsname),
+							Lifecycle:       types.FileSystemLifecycleUpdating,
+							LustreConfiguration: &types.LustreFileSystemConfiguration{
+								DeploymentType: types.LustreDeploymentTypeScratch1,
+								MountName:      aws.String(mountName),
+							},
+							AdministrativeActions: []types.AdministrativeAction{
+								{
+									AdministrativeActionType: types.AdministrativeActionTypeFileSystemUpdate,
+									Status:                   types.StatusFailed,
+									RequestTime:              aws.Time(requestTime),
+									FailureDetails: &types.AdministrativeActionFailureDetails{
+										Message: aws.String("update failed"),
+									},
+								},
+							},
+						},
+					},
+				}
+				ctx := context.Background()
+				mockFSx.EXPECT().DescribeFileSystems(gomock.Eq(ctx), gomock.Any()).Return(output, nil)
+				fs, err := c.DescribeFileSystem(ctx, fileSystemId)
+				if err != nil {
+					t.Fatalf("DescribeFileSystem is failed: %v", err)
+				}
+
+				if fs.Lifecycle != string(types.FileSystemLifecycleUpdating) {
+					t.Fatalf("Lifecycle mismatches. actual: %v expected: %v", fs.Lifecycle, types.FileSystemLifecycleUpdating)
+				}
+
+				if len(fs.AdministrativeActions) != 1 {
+					t.Fatalf("AdministrativeActions length mismatches. actual: %v expected: %v", len(fs.AdministrativeActions), 1)
+				}
+
+				action := fs.AdministrativeActions[0]
+				if action.ActionType != string(types.AdministrativeActionTypeFileSystemUpdate) {
+					t.Fatalf("ActionType mismatches. actual: %v expected: %v", action.ActionType, types.AdministrativeActionTypeFileSystemUpdate)
+				}
+
+				if action.Status != string(types.StatusFailed) {
+					t.Fatalf("Status mismatches. actual: %v expected: %v", action.Status, types.StatusFailed)
+				}
+
+				if !action.RequestTime.Equal(requestTime) {
+					t.Fatalf("RequestTime mismatches. actual: %v expected: %v", action.RequestTime, requestTime)
+				}
+
+				if action.FailureMessage != "update failed" {
+					t.Fatalf("FailureMessage mismatches. actual: %v expected: %v", action.FailureMessage, "update failed")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: filesystem not found",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				mockFSx.EXPECT().DescribeFileSystems(gomock.Eq(ctx), gomock.Any()).Return(nil, &types.FileSystemNotFound{Message: aws.String("test")})
+				_, err := c.DescribeFileSystem(ctx, fileSystemId)
+				if err != ErrNotFound {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
 		{
 			name: "fail: DescribeFileSystemWithContext return error",
 			testFunc: func(t *testing.T) {
@@ -1196,6 +1360,186 @@ func TestDescribeFileSystem(t *testing.T) {
 	}
 }
 
+func TestCreateFileSystemFromBackup(t *testing.T) {
+	var (
+		volumeName                     = "volumeName"
+		backupId                       = "backup-1234"
+		fileSystemId                   = "fs-1234"
+		volumeSizeGiB            int32 = 2400
+		subnetId                       = "subnet-056da83524edbe641"
+		securityGroupIds               = []string{"sg-086f61ea73388fb6b", "sg-0145e55e976000c9e"}
+		dnsname                        = "test.fsx.us-west-2.amazoawd.com"
+		mountName                      = "fsx"
+		kmsKeyId                       = "arn:aws:kms:us-east-1:215474938041:key/48313a27-7d88-4b51-98a4-fdf5bc80dbbe"
+		perUnitStorageThroughput int32 = 500
+		extraTags                      = []string{"key1=value1", "key2=value2"}
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: normal",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx:         mockFSx,
+					volumeCache: make(map[string]*FileSystem),
+				}
+
+				req := &FileSystemOptions{
+					CapacityGiB:              volumeSizeGiB,
+					SubnetId:                 subnetId,
+					SecurityGroupIds:         securityGroupIds,
+					DeploymentType:           string(types.LustreDeploymentTypePersistent1),
+					StorageType:              string(types.StorageTypeSsd),
+					KmsKeyId:                 kmsKeyId,
+					PerUnitStorageThroughput: perUnitStorageThroughput,
+					ExtraTags:                extraTags,
+				}
+
+				output := &fsx.CreateFileSystemFromBackupOutput{
+					FileSystem: &types.FileSystem{
+						FileSystemId:    aws.String(fileSystemId),
+						StorageCapacity: aws.Int32(volumeSizeGiB),
+						StorageType:     types.StorageTypeSsd,
+						DNSName:         aws.String(dnsname),
+						LustreConfiguration: &types.LustreFileSystemConfiguration{
+							DeploymentType:           types.LustreDeploymentTypePersistent1,
+							MountName:                aws.String(mountName),
+							PerUnitStorageThroughput: aws.Int32(perUnitStorageThroughput),
+						},
+					},
+				}
+				ctx := context.Background()
+				mockFSx.EXPECT().CreateFileSystemFromBackup(gomock.Eq(ctx), gomock.Any()).DoAndReturn(
+					func(_ context.Context, input *fsx.CreateFileSystemFromBackupInput, _ ...func(*fsx.Options)) (*fsx.CreateFileSystemFromBackupOutput, error) {
+						if aws.ToString(input.BackupId) != backupId {
+							t.Fatalf("BackupId mismatches. actual: %v expected: %v", aws.ToString(input.BackupId), backupId)
+						}
+						if aws.ToString(input.KmsKeyId) != kmsKeyId {
+							t.Fatalf("KmsKeyId mismatches. actual: %v expected: %v", aws.ToString(input.KmsKeyId), kmsKeyId)
+						}
+						if aws.ToInt32(input.LustreConfiguration.PerUnitStorageThroughput) != perUnitStorageThroughput {
+							t.Fatalf("PerUnitStorageThroughput mismatches. actual: %v expected: %v", aws.ToInt32(input.LustreConfiguration.PerUnitStorageThroughput), perUnitStorageThroughput)
+						}
+						if len(input.Tags) != 3 {
+							t.Fatalf("Tags length mismatches. actual: %v expected: %v", len(input.Tags), 3)
+						}
+						return output, nil
+					})
+				resp, err := c.CreateFileSystemFromBackup(ctx, volumeName, backupId, req)
+				if err != nil {
+					t.Fatalf("CreateFileSystemFromBackup is failed: %v", err)
+				}
+
+				if resp.FileSystemId != fileSystemId {
+					t.Fatalf("FileSystemId mismatches. actual: %v expected: %v", resp.FileSystemId, fileSystemId)
+				}
+
+				if resp.CapacityGiB != volumeSizeGiB {
+					t.Fatalf("CapacityGiB mismatches. actual: %v expected: %v", resp.CapacityGiB, volumeSizeGiB)
+				}
+
+				if resp.DnsName != dnsname {
+					t.Fatalf("DnsName mismatches. actual: %v expected: %v", resp.DnsName, dnsname)
+				}
+
+				if resp.MountName != mountName {
+					t.Fatalf("MountName mismatches. actual: %v expected: %v", resp.MountName, mountName)
+				}
+
+				if _, ok := c.volumeCache[volumeName]; !ok {
+					t.Fatal("filesystem was not added to the cache")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: missing subnet ID",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx:         mockFSx,
+					volumeCache: make(map[string]*FileSystem),
+				}
+
+				req := &FileSystemOptions{
+					CapacityGiB:      volumeSizeGiB,
+					SecurityGroupIds: securityGroupIds,
+				}
+
+				ctx := context.Background()
+				_, err := c.CreateFileSystemFromBackup(ctx, volumeName, backupId, req)
+				if err == nil {
+					t.Fatal("CreateFileSystemFromBackup is not failed")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: backup not found",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx:         mockFSx,
+					volumeCache: make(map[string]*FileSystem),
+				}
+
+				req := &FileSystemOptions{
+					CapacityGiB:      volumeSizeGiB,
+					SubnetId:         subnetId,
+					SecurityGroupIds: securityGroupIds,
+				}
+
+				ctx := context.Background()
+				mockFSx.EXPECT().CreateFileSystemFromBackup(gomock.Eq(ctx), gomock.Any()).Return(nil, &types.BackupNotFound{})
+				_, err := c.CreateFileSystemFromBackup(ctx, volumeName, backupId, req)
+				if !errors.Is(err, ErrNotFound) {
+					t.Fatalf("CreateFileSystemFromBackup returned unexpected error. actual: %v expected: %v", err, ErrNotFound)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: CreateFileSystemFromBackup return error",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx:         mockFSx,
+					volumeCache: make(map[string]*FileSystem),
+				}
+
+				req := &FileSystemOptions{
+					CapacityGiB:      volumeSizeGiB,
+					SubnetId:         subnetId,
+					SecurityGroupIds: securityGroupIds,
+				}
+
+				ctx := context.Background()
+				mockFSx.EXPECT().CreateFileSystemFromBackup(gomock.Eq(ctx), gomock.Any()).Return(nil, errors.New("CreateFileSystemFromBackup failed"))
+				_, err := c.CreateFileSystemFromBackup(ctx, volumeName, backupId, req)
+				if err == nil {
+					t.Fatal("CreateFileSystemFromBackup is not failed")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
 func TestResizeFileSystem(t *testing.T) {
 	var (
 		fileSystemId         = "fs-1234"
@@ -1673,40 +2017,2151 @@ func TestWaitForFileSystemResize(t *testing.T) {
 	}
 }
 
-func TestIsBadRequestUpdateInProgress(t *testing.T) {
+func TestUpdateFileSystem(t *testing.T) {
+	var (
+		fileSystemId                   = "fs-1234"
+		perUnitStorageThroughput int32 = 250
+		metadataIops             int32 = 3000
+	)
 	testCases := []struct {
 		name     string
 		testFunc func(t *testing.T)
 	}{
 		{
-			name: "success: BadRequest update in progress",
+			name: "success: normal",
 			testFunc: func(t *testing.T) {
-				errorInput := &types.BadRequest{
-					Message: aws.String("Unable to perform the storage capacity update. There is an update already in progress."),
-				}
-				if !isBadRequestUpdateInProgress(errorInput) {
-					t.Fatalf("isBadRequestUpdateInProgress returned false, expected true")
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
 				}
-			},
-		},
-		{
+
+				ctx := context.Background()
+				updateInput := &fsx.UpdateFileSystemInput{
+					FileSystemId: aws.String(fileSystemId),
+					LustreConfiguration: &types.UpdateFileSystemLustreConfiguration{
+						PerUnitStorageThroughput: aws.Int32(perUnitStorageThroughput),
+						DataCompressionType:      types.DataCompressionTypeLz4,
+						MetadataConfiguration: &types.UpdateFileSystemLustreMetadataConfiguration{
+							Mode: types.MetadataConfigurationModeUserProvisioned,
+							Iops: aws.Int32(metadataIops),
+						},
+					},
+				}
+
+				mockFSx.EXPECT().UpdateFileSystem(gomock.Eq(ctx), gomock.Eq(updateInput)).Return(&fsx.UpdateFileSystemOutput{}, nil)
+				err := c.UpdateFileSystem(ctx, fileSystemId, &FileSystemUpdateOptions{
+					PerUnitStorageThroughput:  perUnitStorageThroughput,
+					DataCompressionType:       "LZ4",
+					MetadataConfigurationMode: "USER_PROVISIONED",
+					MetadataIops:              metadataIops,
+				})
+				if err != nil {
+					t.Fatalf("UpdateFileSystem is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: backup and maintenance settings",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				updateInput := &fsx.UpdateFileSystemInput{
+					FileSystemId: aws.String(fileSystemId),
+					LustreConfiguration: &types.UpdateFileSystemLustreConfiguration{
+						AutomaticBackupRetentionDays:  aws.Int32(0),
+						DailyAutomaticBackupStartTime: aws.String("01:00"),
+						WeeklyMaintenanceStartTime:    aws.String("7:09:00"),
+					},
+				}
+
+				retentionDays := int32(0)
+				mockFSx.EXPECT().UpdateFileSystem(gomock.Eq(ctx), gomock.Eq(updateInput)).Return(&fsx.UpdateFileSystemOutput{}, nil)
+				err := c.UpdateFileSystem(ctx, fileSystemId, &FileSystemUpdateOptions{
+					AutomaticBackupRetentionDays:  &retentionDays,
+					DailyAutomaticBackupStartTime: "01:00",
+					WeeklyMaintenanceStartTime:    "7:09:00",
+				})
+				if err != nil {
+					t.Fatalf("UpdateFileSystem is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: matching update in progress",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				describeInput := &fsx.DescribeFileSystemsInput{
+					FileSystemIds: []string{fileSystemId},
+				}
+				describeOutput := &fsx.DescribeFileSystemsOutput{
+					FileSystems: []types.FileSystem{
+						{
+							FileSystemId: aws.String(fileSystemId),
+							AdministrativeActions: []types.AdministrativeAction{
+								{
+									AdministrativeActionType: types.AdministrativeActionTypeFileSystemUpdate,
+									Status:                   types.StatusInProgress,
+									TargetFileSystemValues: &types.FileSystem{
+										LustreConfiguration: &types.LustreFileSystemConfiguration{
+											PerUnitStorageThroughput: aws.Int32(perUnitStorageThroughput),
+										},
+									},
+								},
+							},
+						},
+					},
+				}
+				updateError := &types.BadRequest{
+					Message: aws.String("Unable to perform the update. There is an update already in progress."),
+				}
+
+				mockFSx.EXPECT().UpdateFileSystem(gomock.Eq(ctx), gomock.Any()).Return(nil, updateError)
+				mockFSx.EXPECT().DescribeFileSystems(gomock.Eq(ctx), gomock.Eq(describeInput)).Return(describeOutput, nil)
+				err := c.UpdateFileSystem(ctx, fileSystemId, &FileSystemUpdateOptions{
+					PerUnitStorageThroughput: perUnitStorageThroughput,
+				})
+				if err != nil {
+					t.Fatalf("UpdateFileSystem is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: applied backup settings with update in progress",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				describeInput := &fsx.DescribeFileSystemsInput{
+					FileSystemIds: []string{fileSystemId},
+				}
+				describeOutput := &fsx.DescribeFileSystemsOutput{
+					FileSystems: []types.FileSystem{
+						{
+							FileSystemId: aws.String(fileSystemId),
+							LustreConfiguration: &types.LustreFileSystemConfiguration{
+								WeeklyMaintenanceStartTime: aws.String("7:09:00"),
+							},
+						},
+					},
+				}
+				updateError := &types.BadRequest{
+					Message: aws.String("Unable to perform the update. There is an update already in progress."),
+				}
+
+				mockFSx.EXPECT().UpdateFileSystem(gomock.Eq(ctx), gomock.Any()).Return(nil, updateError)
+				mockFSx.EXPECT().DescribeFileSystems(gomock.Eq(ctx), gomock.Eq(describeInput)).Return(describeOutput, nil)
+				err := c.UpdateFileSystem(ctx, fileSystemId, &FileSystemUpdateOptions{
+					WeeklyMaintenanceStartTime: "7:09:00",
+				})
+				if err != nil {
+					t.Fatalf("UpdateFileSystem is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "failure: different update in progress",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				describeInput := &fsx.DescribeFileSystemsInput{
+					FileSystemIds: []string{fileSystemId},
+				}
+				describeOutput := &fsx.DescribeFileSystemsOutput{
+					FileSystems: []types.FileSystem{
+						{
+							FileSystemId: aws.String(fileSystemId),
+							AdministrativeActions: []types.AdministrativeAction{
+								{
+									AdministrativeActionType: types.AdministrativeActionTypeFileSystemUpdate,
+									Status:                   types.StatusInProgress,
+									TargetFileSystemValues: &types.FileSystem{
+										StorageCapacity: aws.Int32(2400),
+									},
+								},
+							},
+						},
+					},
+				}
+				updateError := &types.BadRequest{
+					Message: aws.String("Unable to perform the update. There is an update already in progress."),
+				}
+
+				mockFSx.EXPECT().UpdateFileSystem(gomock.Eq(ctx), gomock.Any()).Return(nil, updateError)
+				mockFSx.EXPECT().DescribeFileSystems(gomock.Eq(ctx), gomock.Eq(describeInput)).Return(describeOutput, nil)
+				err := c.UpdateFileSystem(ctx, fileSystemId, &FileSystemUpdateOptions{
+					PerUnitStorageThroughput: perUnitStorageThroughput,
+				})
+				if err == nil {
+					t.Fatal("UpdateFileSystem is not failed")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "failure: filesystem not found",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				mockFSx.EXPECT().UpdateFileSystem(gomock.Eq(ctx), gomock.Any()).Return(nil, &types.FileSystemNotFound{Message: aws.String("test")})
+				err := c.UpdateFileSystem(ctx, fileSystemId, &FileSystemUpdateOptions{
+					DataCompressionType: "LZ4",
+				})
+				if err != ErrNotFound {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestWaitForFileSystemUpdate(t *testing.T) {
+	var (
+		fileSystemId       = "fs-1234"
+		iops         int32 = 6000
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: update action completed",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				describeInput := &fsx.DescribeFileSystemsInput{
+					FileSystemIds: []string{fileSystemId},
+				}
+				describeOutput := &fsx.DescribeFileSystemsOutput{
+					FileSystems: []types.FileSystem{
+						{
+							FileSystemId: aws.String(fileSystemId),
+							AdministrativeActions: []types.AdministrativeAction{
+								{
+									AdministrativeActionType: types.AdministrativeActionTypeFileSystemUpdate,
+									Status:                   types.StatusCompleted,
+									TargetFileSystemValues: &types.FileSystem{
+										LustreConfiguration: &types.LustreFileSystemConfiguration{
+											MetadataConfiguration: &types.FileSystemLustreMetadataConfiguration{
+												Mode: types.MetadataConfigurationModeUserProvisioned,
+												Iops: aws.Int32(iops),
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				}
+
+				mockFSx.EXPECT().DescribeFileSystems(gomock.Eq(ctx), gomock.Eq(describeInput)).Return(describeOutput, nil)
+				err := c.WaitForFileSystemUpdate(ctx, fileSystemId, &FileSystemUpdateOptions{
+					MetadataConfigurationMode: "USER_PROVISIONED",
+					MetadataIops:              iops,
+				})
+				if err != nil {
+					t.Fatalf("WaitForFileSystemUpdate is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: backup settings applied without update action",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				describeInput := &fsx.DescribeFileSystemsInput{
+					FileSystemIds: []string{fileSystemId},
+				}
+				describeOutput := &fsx.DescribeFileSystemsOutput{
+					FileSystems: []types.FileSystem{
+						{
+							FileSystemId: aws.String(fileSystemId),
+							LustreConfiguration: &types.LustreFileSystemConfiguration{
+								AutomaticBackupRetentionDays:  aws.Int32(30),
+								DailyAutomaticBackupStartTime: aws.String("01:00"),
+							},
+						},
+					},
+				}
+
+				retentionDays := int32(30)
+				// backup settings are applied without an administrative action, so the filesystem is described once
+				mockFSx.EXPECT().DescribeFileSystems(gomock.Eq(ctx), gomock.Eq(describeInput)).Return(describeOutput, nil)
+				err := c.WaitForFileSystemUpdate(ctx, fileSystemId, &FileSystemUpdateOptions{
+					AutomaticBackupRetentionDays:  &retentionDays,
+					DailyAutomaticBackupStartTime: "01:00",
+				})
+				if err != nil {
+					t.Fatalf("WaitForFileSystemUpdate is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "failure: backup settings not applied",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				describeInput := &fsx.DescribeFileSystemsInput{
+					FileSystemIds: []string{fileSystemId},
+				}
+				describeOutput := &fsx.DescribeFileSystemsOutput{
+					FileSystems: []types.FileSystem{
+						{
+							FileSystemId: aws.String(fileSystemId),
+							LustreConfiguration: &types.LustreFileSystemConfiguration{
+								AutomaticBackupRetentionDays:  aws.Int32(7),
+								DailyAutomaticBackupStartTime: aws.String("01:00"),
+							},
+						},
+					},
+				}
+
+				retentionDays := int32(30)
+				// backup settings are applied without an administrative action, so the filesystem is described once
+				mockFSx.EXPECT().DescribeFileSystems(gomock.Eq(ctx), gomock.Eq(describeInput)).Return(describeOutput, nil)
+				err := c.WaitForFileSystemUpdate(ctx, fileSystemId, &FileSystemUpdateOptions{
+					AutomaticBackupRetentionDays:  &retentionDays,
+					DailyAutomaticBackupStartTime: "01:00",
+				})
+				if err == nil {
+					t.Fatal("WaitForFileSystemUpdate is not failed")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "failure: update action failed",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				describeInput := &fsx.DescribeFileSystemsInput{
+					FileSystemIds: []string{fileSystemId},
+				}
+				describeOutput := &fsx.DescribeFileSystemsOutput{
+					FileSystems: []types.FileSystem{
+						{
+							FileSystemId: aws.String(fileSystemId),
+							AdministrativeActions: []types.AdministrativeAction{
+								{
+									AdministrativeActionType: types.AdministrativeActionTypeFileSystemUpdate,
+									Status:                   types.StatusFailed,
+									TargetFileSystemValues: &types.FileSystem{
+										LustreConfiguration: &types.LustreFileSystemConfiguration{
+											DataCompressionType: types.DataCompressionTypeLz4,
+										},
+									},
+									FailureDetails: &types.AdministrativeActionFailureDetails{
+										Message: aws.String("failure"),
+									},
+								},
+							},
+						},
+					},
+				}
+
+				mockFSx.EXPECT().DescribeFileSystems(gomock.Eq(ctx), gomock.Eq(describeInput)).Return(describeOutput, nil)
+				err := c.WaitForFileSystemUpdate(ctx, fileSystemId, &FileSystemUpdateOptions{
+					DataCompressionType: "LZ4",
+				})
+				if err == nil {
+					t.Fatal("WaitForFileSystemUpdate is not failed")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "failure: no matching update action",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				describeInput := &fsx.DescribeFileSystemsInput{
+					FileSystemIds: []string{fileSystemId},
+				}
+				describeOutput := &fsx.DescribeFileSystemsOutput{
+					FileSystems: []types.FileSystem{
+						{
+							FileSystemId: aws.String(fileSystemId),
+							AdministrativeActions: []types.AdministrativeAction{
+								{
+									AdministrativeActionType: types.AdministrativeActionTypeFileSystemUpdate,
+									Status:                   types.StatusCompleted,
+									TargetFileSystemValues: &types.FileSystem{
+										LustreConfiguration: &types.LustreFileSystemConfiguration{
+											DataCompressionType: types.DataCompressionTypeNone,
+										},
+									},
+								},
+							},
+						},
+					},
+				}
+
+				mockFSx.EXPECT().DescribeFileSystems(gomock.Eq(ctx), gomock.Eq(describeInput)).Return(describeOutput, nil)
+				err := c.WaitForFileSystemUpdate(ctx, fileSystemId, &FileSystemUpdateOptions{
+					DataCompressionType: "LZ4",
+				})
+				if err == nil {
+					t.Fatal("WaitForFileSystemUpdate is not failed")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestIsBadRequestUpdateInProgress(t *testing.T) {
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: BadRequest update in progress",
+			testFunc: func(t *testing.T) {
+				errorInput := &types.BadRequest{
+					Message: aws.String("Unable to perform the storage capacity update. There is an update already in progress."),
+				}
+				if !isBadRequestUpdateInProgress(errorInput) {
+					t.Fatalf("isBadRequestUpdateInProgress returned false, expected true")
+				}
+			},
+		},
+		{
+			name: "success: BadRequest update of other settings in progress",
+			testFunc: func(t *testing.T) {
+				errorInput := &types.BadRequest{
+					Message: aws.String("Unable to perform the update. There is an update already in progress."),
+				}
+				if !isBadRequestUpdateInProgress(errorInput) {
+					t.Fatalf("isBadRequestUpdateInProgress returned false, expected true")
+				}
+			},
+		},
+		{
 			name: "failure: AWS error, different type",
 			testFunc: func(t *testing.T) {
-				errorInput := &types.FileSystemNotFound{
-					Message: aws.String("test"),
+				errorInput := &types.FileSystemNotFound{
+					Message: aws.String("test"),
+				}
+				if isBadRequestUpdateInProgress(errorInput) {
+					t.Fatalf("isBadRequestUpdateInProgress returned true, expected false")
+				}
+			},
+		},
+		{
+			name: "failure: not AWS error",
+			testFunc: func(t *testing.T) {
+				errorInput := errors.New("test")
+				if isBadRequestUpdateInProgress(errorInput) {
+					t.Fatalf("isBadRequestUpdateInProgress returned true, expected false")
+				}
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestCreateBackup(t *testing.T) {
+	var (
+		snapshotName        = "snapshotName"
+		backupId            = "backup-1234"
+		fileSystemId        = "fs-1234"
+		volumeSizeGiB int32 = 1200
+		extraTags           = []string{"key1=value1", "key2=value2"}
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: normal",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				req := &BackupOptions{
+					FileSystemId: fileSystemId,
+					ExtraTags:    extraTags,
+				}
+
+				output := &fsx.CreateBackupOutput{
+					Backup: &types.Backup{
+						BackupId:  aws.String(backupId),
+						Lifecycle: types.BackupLifecycleCreating,
+						FileSystem: &types.FileSystem{
+							FileSystemId:    aws.String(fileSystemId),
+							StorageCapacity: aws.Int32(volumeSizeGiB),
+							StorageType:     types.StorageTypeSsd,
+							LustreConfiguration: &types.LustreFileSystemConfiguration{
+								DeploymentType: types.LustreDeploymentTypePersistent1,
+							},
+						},
+					},
+				}
+				ctx := context.Background()
+				mockFSx.EXPECT().CreateBackup(gomock.Eq(ctx), gomock.Any()).DoAndReturn(
+					func(_ context.Context, input *fsx.CreateBackupInput, _ ...func(*fsx.Options)) (*fsx.CreateBackupOutput, error) {
+						if aws.ToString(input.ClientRequestToken) != snapshotName {
+							t.Fatalf("ClientRequestToken mismatches. actual: %v expected: %v", aws.ToString(input.ClientRequestToken), snapshotName)
+						}
+						if len(input.Tags) != 3 {
+							t.Fatalf("Tags length mismatches. actual: %v expected: %v", len(input.Tags), 3)
+						}
+						return output, nil
+					})
+				resp, err := c.CreateBackup(ctx, snapshotName, req)
+				if err != nil {
+					t.Fatalf("CreateBackup is failed: %v", err)
+				}
+
+				if resp == nil {
+					t.Fatal("resp is nil")
+				}
+
+				if resp.BackupId != backupId {
+					t.Fatalf("BackupId mismatches. actual: %v expected: %v", resp.BackupId, backupId)
+				}
+
+				if resp.FileSystemId != fileSystemId {
+					t.Fatalf("FileSystemId mismatches. actual: %v expected: %v", resp.FileSystemId, fileSystemId)
+				}
+
+				if resp.CapacityGiB != volumeSizeGiB {
+					t.Fatalf("CapacityGiB mismatches. actual: %v expected: %v", resp.CapacityGiB, volumeSizeGiB)
+				}
+
+				if resp.Lifecycle != string(types.BackupLifecycleCreating) {
+					t.Fatalf("Lifecycle mismatches. actual: %v expected: %v", resp.Lifecycle, types.BackupLifecycleCreating)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: intermediate backup of a clone",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				req := &BackupOptions{
+					FileSystemId:    fileSystemId,
+					CloneVolumeName: "volumeName",
+				}
+
+				output := &fsx.CreateBackupOutput{
+					Backup: &types.Backup{
+						BackupId:  aws.String(backupId),
+						Lifecycle: types.BackupLifecycleCreating,
+						Tags:      []types.Tag{{Key: aws.String(CloneVolumeNameTagKey), Value: aws.String("volumeName")}},
+					},
+				}
+				ctx := context.Background()
+				mockFSx.EXPECT().CreateBackup(gomock.Eq(ctx), gomock.Any()).DoAndReturn(
+					func(_ context.Context, input *fsx.CreateBackupInput, _ ...func(*fsx.Options)) (*fsx.CreateBackupOutput, error) {
+						// the backup is not tagged with a snapshot name, which leaves it out of the snapshots
+						if len(input.Tags) != 1 || aws.ToString(input.Tags[0].Key) != CloneVolumeNameTagKey || aws.ToString(input.Tags[0].Value) != "volumeName" {
+							t.Fatalf("Tags mismatches. actual: %v expected: %v=%v", input.Tags, CloneVolumeNameTagKey, "volumeName")
+						}
+						return output, nil
+					})
+				resp, err := c.CreateBackup(ctx, "volumeName-clone", req)
+				if err != nil {
+					t.Fatalf("CreateBackup is failed: %v", err)
+				}
+
+				if resp.Tags[CloneVolumeNameTagKey] != "volumeName" {
+					t.Fatalf("Tags mismatches. actual: %v expected: %v", resp.Tags, map[string]string{CloneVolumeNameTagKey: "volumeName"})
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: missing filesystem ID",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				_, err := c.CreateBackup(ctx, snapshotName, &BackupOptions{})
+				if err == nil {
+					t.Fatal("CreateBackup is not failed")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: filesystem not found",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				mockFSx.EXPECT().CreateBackup(gomock.Eq(ctx), gomock.Any()).Return(nil, &types.FileSystemNotFound{})
+				_, err := c.CreateBackup(ctx, snapshotName, &BackupOptions{FileSystemId: fileSystemId})
+				if !errors.Is(err, ErrNotFound) {
+					t.Fatalf("CreateBackup returned unexpected error. actual: %v expected: %v", err, ErrNotFound)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: backup exists with different filesystem",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				mockFSx.EXPECT().CreateBackup(gomock.Eq(ctx), gomock.Any()).Return(nil, &types.IncompatibleParameterError{})
+				_, err := c.CreateBackup(ctx, snapshotName, &BackupOptions{FileSystemId: fileSystemId})
+				if !errors.Is(err, ErrBackupExistsDiffFs) {
+					t.Fatalf("CreateBackup returned unexpected error. actual: %v expected: %v", err, ErrBackupExistsDiffFs)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: CreateBackup return error",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				mockFSx.EXPECT().CreateBackup(gomock.Eq(ctx), gomock.Any()).Return(nil, errors.New("CreateBackup failed"))
+				_, err := c.CreateBackup(ctx, snapshotName, &BackupOptions{FileSystemId: fileSystemId})
+				if err == nil {
+					t.Fatal("CreateBackup is not failed")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestDeleteBackup(t *testing.T) {
+	var (
+		backupId = "backup-1234"
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: normal",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				output := &fsx.DeleteBackupOutput{}
+				ctx := context.Background()
+				mockFSx.EXPECT().DeleteBackup(gomock.Eq(ctx), gomock.Any()).Return(output, nil)
+				err := c.DeleteBackup(ctx, backupId)
+				if err != nil {
+					t.Fatalf("DeleteBackup is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: backup not found",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				mockFSx.EXPECT().DeleteBackup(gomock.Eq(ctx), gomock.Any()).Return(nil, &types.BackupNotFound{})
+				err := c.DeleteBackup(ctx, backupId)
+				if !errors.Is(err, ErrNotFound) {
+					t.Fatalf("DeleteBackup returned unexpected error. actual: %v expected: %v", err, ErrNotFound)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: DeleteBackup return error",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				mockFSx.EXPECT().DeleteBackup(gomock.Eq(ctx), gomock.Any()).Return(nil, errors.New("DeleteBackup failed"))
+				err := c.DeleteBackup(ctx, backupId)
+				if err == nil {
+					t.Fatal("DeleteBackup is not failed")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestDescribeBackup(t *testing.T) {
+	var (
+		backupId             = "backup-1234"
+		fileSystemId         = "fs-1234"
+		volumeSizeGiB  int32 = 1200
+		failureMessage       = "backup failed"
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: normal",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				output := &fsx.DescribeBackupsOutput{
+					Backups: []types.Backup{
+						{
+							BackupId:  aws.String(backupId),
+							Lifecycle: types.BackupLifecycleAvailable,
+							FileSystem: &types.FileSystem{
+								FileSystemId:    aws.String(fileSystemId),
+								StorageCapacity: aws.Int32(volumeSizeGiB),
+							},
+						},
+					},
+				}
+				ctx := context.Background()
+				mockFSx.EXPECT().DescribeBackups(gomock.Eq(ctx), gomock.Any()).Return(output, nil)
+				resp, err := c.DescribeBackup(ctx, backupId)
+				if err != nil {
+					t.Fatalf("DescribeBackup is failed: %v", err)
+				}
+
+				if resp.Lifecycle != string(types.BackupLifecycleAvailable) {
+					t.Fatalf("Lifecycle mismatches. actual: %v expected: %v", resp.Lifecycle, types.BackupLifecycleAvailable)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: failed backup",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				output := &fsx.DescribeBackupsOutput{
+					Backups: []types.Backup{
+						{
+							BackupId:  aws.String(backupId),
+							Lifecycle: types.BackupLifecycleFailed,
+							FailureDetails: &types.BackupFailureDetails{
+								Message: aws.String(failureMessage),
+							},
+						},
+					},
+				}
+				ctx := context.Background()
+				mockFSx.EXPECT().DescribeBackups(gomock.Eq(ctx), gomock.Any()).Return(output, nil)
+				resp, err := c.DescribeBackup(ctx, backupId)
+				if err != nil {
+					t.Fatalf("DescribeBackup is failed: %v", err)
+				}
+
+				if resp.FailureMessage != failureMessage {
+					t.Fatalf("FailureMessage mismatches. actual: %v expected: %v", resp.FailureMessage, failureMessage)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: backup not found",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				mockFSx.EXPECT().DescribeBackups(gomock.Eq(ctx), gomock.Any()).Return(nil, &types.BackupNotFound{})
+				_, err := c.DescribeBackup(ctx, backupId)
+				if !errors.Is(err, ErrNotFound) {
+					t.Fatalf("DescribeBackup returned unexpected error. actual: %v expected: %v", err, ErrNotFound)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: DescribeBackups return error",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				mockFSx.EXPECT().DescribeBackups(gomock.Eq(ctx), gomock.Any()).Return(nil, errors.New("DescribeBackups failed"))
+				_, err := c.DescribeBackup(ctx, backupId)
+				if err == nil {
+					t.Fatal("DescribeBackup is not failed")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestWaitForBackupAvailable(t *testing.T) {
+	var (
+		backupId = "backup-1234"
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: backup available",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				describeInput := &fsx.DescribeBackupsInput{
+					BackupIds: []string{backupId},
+				}
+				describeOutput := &fsx.DescribeBackupsOutput{
+					Backups: []types.Backup{
+						{
+							BackupId:  aws.String(backupId),
+							Lifecycle: types.BackupLifecycleAvailable,
+						},
+					},
+				}
+
+				mockFSx.EXPECT().DescribeBackups(gomock.Eq(ctx), gomock.Eq(describeInput)).Return(describeOutput, nil)
+				err := c.WaitForBackupAvailable(ctx, backupId)
+				if err != nil {
+					t.Fatalf("WaitForBackupAvailable is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "failure: backup failed",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				describeInput := &fsx.DescribeBackupsInput{
+					BackupIds: []string{backupId},
+				}
+				describeOutput := &fsx.DescribeBackupsOutput{
+					Backups: []types.Backup{
+						{
+							BackupId:  aws.String(backupId),
+							Lifecycle: types.BackupLifecycleFailed,
+						},
+					},
+				}
+
+				mockFSx.EXPECT().DescribeBackups(gomock.Eq(ctx), gomock.Eq(describeInput)).Return(describeOutput, nil)
+				err := c.WaitForBackupAvailable(ctx, backupId)
+				if err == nil {
+					t.Fatal("WaitForBackupAvailable is not failed")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "failure: backup deleted",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				describeInput := &fsx.DescribeBackupsInput{
+					BackupIds: []string{backupId},
+				}
+				describeOutput := &fsx.DescribeBackupsOutput{
+					Backups: []types.Backup{
+						{
+							BackupId:  aws.String(backupId),
+							Lifecycle: types.BackupLifecycleDeleted,
+						},
+					},
+				}
+
+				mockFSx.EXPECT().DescribeBackups(gomock.Eq(ctx), gomock.Eq(describeInput)).Return(describeOutput, nil)
+				err := c.WaitForBackupAvailable(ctx, backupId)
+				if err == nil {
+					t.Fatal("WaitForBackupAvailable is not failed")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestListBackups(t *testing.T) {
+	var (
+		fileSystemId       = "fs-1234"
+		maxResults   int32 = 2
+		nextToken          = "token"
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: filter by filesystem",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				output := &fsx.DescribeBackupsOutput{
+					Backups: []types.Backup{
+						{BackupId: aws.String("backup-1")},
+						{BackupId: aws.String("backup-2")},
+					},
+					NextToken: aws.String("next"),
+				}
+				ctx := context.Background()
+				mockFSx.EXPECT().DescribeBackups(gomock.Eq(ctx), gomock.Any()).DoAndReturn(
+					func(_ context.Context, input *fsx.DescribeBackupsInput, _ ...func(*fsx.Options)) (*fsx.DescribeBackupsOutput, error) {
+						if len(input.Filters) != 3 {
+							t.Fatalf("Filters length mismatches. actual: %v expected: %v", len(input.Filters), 3)
+						}
+						if aws.ToInt32(input.MaxResults) != maxResults {
+							t.Fatalf("MaxResults mismatches. actual: %v expected: %v", aws.ToInt32(input.MaxResults), maxResults)
+						}
+						if aws.ToString(input.NextToken) != nextToken {
+							t.Fatalf("NextToken mismatches. actual: %v expected: %v", aws.ToString(input.NextToken), nextToken)
+						}
+						return output, nil
+					})
+				backups, token, err := c.ListBackups(ctx, fileSystemId, maxResults, nextToken)
+				if err != nil {
+					t.Fatalf("ListBackups is failed: %v", err)
+				}
+
+				if len(backups) != 2 {
+					t.Fatalf("Backups length mismatches. actual: %v expected: %v", len(backups), 2)
+				}
+
+				if token != "next" {
+					t.Fatalf("NextToken mismatches. actual: %v expected: %v", token, "next")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: no filesystem filter",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				mockFSx.EXPECT().DescribeBackups(gomock.Eq(ctx), gomock.Any()).DoAndReturn(
+					func(_ context.Context, input *fsx.DescribeBackupsInput, _ ...func(*fsx.Options)) (*fsx.DescribeBackupsOutput, error) {
+						if len(input.Filters) != 2 {
+							t.Fatalf("Filters length mismatches. actual: %v expected: %v", len(input.Filters), 2)
+						}
+						if input.MaxResults != nil || input.NextToken != nil {
+							t.Fatal("MaxResults and NextToken should not be set")
+						}
+						return &fsx.DescribeBackupsOutput{}, nil
+					})
+				backups, token, err := c.ListBackups(ctx, "", 0, "")
+				if err != nil {
+					t.Fatalf("ListBackups is failed: %v", err)
+				}
+
+				if len(backups) != 0 || token != "" {
+					t.Fatalf("Unexpected response. backups: %v token: %v", backups, token)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: DescribeBackups return error",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				mockFSx.EXPECT().DescribeBackups(gomock.Eq(ctx), gomock.Any()).Return(nil, errors.New("DescribeBackups failed"))
+				_, _, err := c.ListBackups(ctx, fileSystemId, maxResults, nextToken)
+				if err == nil {
+					t.Fatal("ListBackups is not failed")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestFindCloneBackup(t *testing.T) {
+	var (
+		fileSystemId = "fs-1234"
+		volumeName   = "volumeName"
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: found on second page",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				first := &fsx.DescribeBackupsOutput{
+					Backups: []types.Backup{
+						{
+							BackupId: aws.String("backup-1"),
+							Tags:     []types.Tag{{Key: aws.String(CloneVolumeNameTagKey), Value: aws.String("other")}},
+						},
+					},
+					NextToken: aws.String("next"),
+				}
+				second := &fsx.DescribeBackupsOutput{
+					Backups: []types.Backup{
+						{
+							BackupId: aws.String("backup-2"),
+							Tags:     []types.Tag{{Key: aws.String(CloneVolumeNameTagKey), Value: aws.String(volumeName)}},
+						},
+					},
+				}
+				gomock.InOrder(
+					mockFSx.EXPECT().DescribeBackups(gomock.Eq(ctx), gomock.Any()).DoAndReturn(
+						func(_ context.Context, input *fsx.DescribeBackupsInput, _ ...func(*fsx.Options)) (*fsx.DescribeBackupsOutput, error) {
+							if input.Filters[0].Values[0] != fileSystemId {
+								t.Fatalf("FileSystemId filter mismatches. actual: %v expected: %v", input.Filters[0].Values[0], fileSystemId)
+							}
+							return first, nil
+						}),
+					mockFSx.EXPECT().DescribeBackups(gomock.Eq(ctx), gomock.Any()).DoAndReturn(
+						func(_ context.Context, input *fsx.DescribeBackupsInput, _ ...func(*fsx.Options)) (*fsx.DescribeBackupsOutput, error) {
+							if aws.ToString(input.NextToken) != "next" {
+								t.Fatalf("NextToken mismatches. actual: %v expected: %v", aws.ToString(input.NextToken), "next")
+							}
+							return second, nil
+						}),
+				)
+				backup, err := c.FindCloneBackup(ctx, fileSystemId, volumeName)
+				if err != nil {
+					t.Fatalf("FindCloneBackup is failed: %v", err)
+				}
+
+				if backup.BackupId != "backup-2" {
+					t.Fatalf("BackupId mismatches. actual: %v expected: %v", backup.BackupId, "backup-2")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: not found",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				mockFSx.EXPECT().DescribeBackups(gomock.Eq(ctx), gomock.Any()).Return(&fsx.DescribeBackupsOutput{}, nil)
+				_, err := c.FindCloneBackup(ctx, fileSystemId, volumeName)
+				if !errors.Is(err, ErrNotFound) {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: DescribeBackups return error",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				mockFSx.EXPECT().DescribeBackups(gomock.Eq(ctx), gomock.Any()).Return(nil, errors.New("DescribeBackups failed"))
+				_, err := c.FindCloneBackup(ctx, fileSystemId, volumeName)
+				if err == nil || errors.Is(err, ErrNotFound) {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestCreateDataRepositoryAssociation(t *testing.T) {
+	var (
+		fileSystemId                = "fs-1234"
+		associationId               = "dra-1234"
+		fileSystemPath              = "/training"
+		dataRepositoryPath          = "s3://bucket/training"
+		importedFileChunkSize int32 = 1024
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: normal",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				associationOptions := &DataRepositoryAssociationOptions{
+					FileSystemPath:              fileSystemPath,
+					DataRepositoryPath:          dataRepositoryPath,
+					AutoImportEvents:            []string{"NEW", "CHANGED"},
+					AutoExportEvents:            []string{"DELETED"},
+					BatchImportMetaDataOnCreate: true,
+					ImportedFileChunkSize:       importedFileChunkSize,
+				}
+				output := &fsx.CreateDataRepositoryAssociationOutput{
+					Association: &types.DataRepositoryAssociation{
+						AssociationId:      aws.String(associationId),
+						FileSystemId:       aws.String(fileSystemId),
+						FileSystemPath:     aws.String(fileSystemPath),
+						DataRepositoryPath: aws.String(dataRepositoryPath),
+						Lifecycle:          types.DataRepositoryLifecycleCreating,
+					},
+				}
+				ctx := context.Background()
+				mockFSx.EXPECT().CreateDataRepositoryAssociation(gomock.Eq(ctx), gomock.Any()).DoAndReturn(
+					func(_ context.Context, input *fsx.CreateDataRepositoryAssociationInput, _ ...func(*fsx.Options)) (*fsx.CreateDataRepositoryAssociationOutput, error) {
+						if aws.ToString(input.FileSystemPath) != fileSystemPath {
+							t.Fatalf("FileSystemPath mismatches. actual: %v expected: %v", aws.ToString(input.FileSystemPath), fileSystemPath)
+						}
+						if !aws.ToBool(input.BatchImportMetaDataOnCreate) {
+							t.Fatalf("BatchImportMetaDataOnCreate is not set")
+						}
+						if aws.ToInt32(input.ImportedFileChunkSize) != importedFileChunkSize {
+							t.Fatalf("ImportedFileChunkSize mismatches. actual: %v expected: %v", aws.ToInt32(input.ImportedFileChunkSize), importedFileChunkSize)
+						}
+						if len(input.S3.AutoImportPolicy.Events) != 2 {
+							t.Fatalf("AutoImportPolicy events length mismatches. actual: %v expected: %v", len(input.S3.AutoImportPolicy.Events), 2)
+						}
+						if input.S3.AutoExportPolicy.Events[0] != types.EventTypeDeleted {
+							t.Fatalf("AutoExportPolicy events mismatch. actual: %v expected: %v", input.S3.AutoExportPolicy.Events, []types.EventType{types.EventTypeDeleted})
+						}
+						return output, nil
+					})
+				association, err := c.CreateDataRepositoryAssociation(ctx, fileSystemId, associationOptions)
+				if err != nil {
+					t.Fatalf("CreateDataRepositoryAssociation is failed: %v", err)
+				}
+
+				if association.AssociationId != associationId {
+					t.Fatalf("AssociationId mismatches. actual: %v expected: %v", association.AssociationId, associationId)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: no automatic import or export",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				associationOptions := &DataRepositoryAssociationOptions{
+					FileSystemPath:     fileSystemPath,
+					DataRepositoryPath: dataRepositoryPath,
+				}
+				output := &fsx.CreateDataRepositoryAssociationOutput{
+					Association: &types.DataRepositoryAssociation{
+						AssociationId: aws.String(associationId),
+					},
+				}
+				ctx := context.Background()
+				mockFSx.EXPECT().CreateDataRepositoryAssociation(gomock.Eq(ctx), gomock.Any()).DoAndReturn(
+					func(_ context.Context, input *fsx.CreateDataRepositoryAssociationInput, _ ...func(*fsx.Options)) (*fsx.CreateDataRepositoryAssociationOutput, error) {
+						if input.S3 != nil || input.BatchImportMetaDataOnCreate != nil || input.ImportedFileChunkSize != nil {
+							t.Fatalf("Optional settings are unexpectedly set: %+v", input)
+						}
+						return output, nil
+					})
+				_, err := c.CreateDataRepositoryAssociation(ctx, fileSystemId, associationOptions)
+				if err != nil {
+					t.Fatalf("CreateDataRepositoryAssociation is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: CreateDataRepositoryAssociation return error",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				associationOptions := &DataRepositoryAssociationOptions{
+					FileSystemPath:     fileSystemPath,
+					DataRepositoryPath: dataRepositoryPath,
+				}
+				ctx := context.Background()
+				mockFSx.EXPECT().CreateDataRepositoryAssociation(gomock.Eq(ctx), gomock.Any()).Return(nil, errors.New("CreateDataRepositoryAssociation failed"))
+				_, err := c.CreateDataRepositoryAssociation(ctx, fileSystemId, associationOptions)
+				if err == nil {
+					t.Fatalf("CreateDataRepositoryAssociation is not failed")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestWaitForDataRepositoryAssociationAvailable(t *testing.T) {
+	var (
+		associationId = "dra-1234"
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: association available",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				describeInput := &fsx.DescribeDataRepositoryAssociationsInput{
+					AssociationIds: []string{associationId},
+				}
+				describeOutput := &fsx.DescribeDataRepositoryAssociationsOutput{
+					Associations: []types.DataRepositoryAssociation{
+						{
+							AssociationId: aws.String(associationId),
+							Lifecycle:     types.DataRepositoryLifecycleAvailable,
+						},
+					},
+				}
+
+				mockFSx.EXPECT().DescribeDataRepositoryAssociations(gomock.Eq(ctx), gomock.Eq(describeInput)).Return(describeOutput, nil)
+				err := c.WaitForDataRepositoryAssociationAvailable(ctx, associationId)
+				if err != nil {
+					t.Fatalf("WaitForDataRepositoryAssociationAvailable is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "failure: association misconfigured",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				describeOutput := &fsx.DescribeDataRepositoryAssociationsOutput{
+					Associations: []types.DataRepositoryAssociation{
+						{
+							AssociationId: aws.String(associationId),
+							Lifecycle:     types.DataRepositoryLifecycleMisconfigured,
+							FailureDetails: &types.DataRepositoryFailureDetails{
+								Message: aws.String("Access denied to the S3 bucket"),
+							},
+						},
+					},
+				}
+
+				mockFSx.EXPECT().DescribeDataRepositoryAssociations(gomock.Eq(ctx), gomock.Any()).Return(describeOutput, nil)
+				err := c.WaitForDataRepositoryAssociationAvailable(ctx, associationId)
+				if err == nil {
+					t.Fatalf("WaitForDataRepositoryAssociationAvailable is not failed")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "failure: association not found",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				mockFSx.EXPECT().DescribeDataRepositoryAssociations(gomock.Eq(ctx), gomock.Any()).Return(nil, &types.DataRepositoryAssociationNotFound{})
+				err := c.WaitForDataRepositoryAssociationAvailable(ctx, associationId)
+				if !errors.Is(err, ErrNotFound) {
+					t.Fatalf("WaitForDataRepositoryAssociationAvailable is not ErrNotFound: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestListDataRepositoryAssociations(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	mockFSx := mocks.NewMockFSx(mockCtl)
+	c := &cloud{
+		fsx: mockFSx,
+	}
+
+	ctx := context.Background()
+	mockFSx.EXPECT().DescribeDataRepositoryAssociations(gomock.Eq(ctx), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input *fsx.DescribeDataRepositoryAssociationsInput, _ ...func(*fsx.Options)) (*fsx.DescribeDataRepositoryAssociationsOutput, error) {
+			if len(input.Filters) != 1 || input.Filters[0].Values[0] != "fs-1234" {
+				t.Fatalf("Filters mismatch. actual: %+v", input.Filters)
+			}
+			return &fsx.DescribeDataRepositoryAssociationsOutput{
+				Associations: []types.DataRepositoryAssociation{
+					{AssociationId: aws.String("dra-1"), FileSystemPath: aws.String("/training")},
+					{AssociationId: aws.String("dra-2"), FileSystemPath: aws.String("/results")},
+				},
+			}, nil
+		})
+	associations, err := c.ListDataRepositoryAssociations(ctx, "fs-1234")
+	if err != nil {
+		t.Fatalf("ListDataRepositoryAssociations is failed: %v", err)
+	}
+
+	if len(associations) != 2 {
+		t.Fatalf("Associations length mismatches. actual: %v expected: %v", len(associations), 2)
+	}
+
+	mockCtl.Finish()
+}
+
+func TestCreateDataRepositoryTask(t *testing.T) {
+	var (
+		fileSystemId = "fs-1234"
+		taskId       = "task-1234"
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: normal",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				output := &fsx.CreateDataRepositoryTaskOutput{
+					DataRepositoryTask: &types.DataRepositoryTask{
+						TaskId:       aws.String(taskId),
+						FileSystemId: aws.String(fileSystemId),
+						Type:         types.DataRepositoryTaskTypeExport,
+						Lifecycle:    types.DataRepositoryTaskLifecyclePending,
+					},
+				}
+				ctx := context.Background()
+				mockFSx.EXPECT().CreateDataRepositoryTask(gomock.Eq(ctx), gomock.Any()).DoAndReturn(
+					func(_ context.Context, input *fsx.CreateDataRepositoryTaskInput, _ ...func(*fsx.Options)) (*fsx.CreateDataRepositoryTaskOutput, error) {
+						if input.Type != types.DataRepositoryTaskTypeExport {
+							t.Fatalf("Type mismatches. actual: %v expected: %v", input.Type, types.DataRepositoryTaskTypeExport)
+						}
+						if aws.ToBool(input.Report.Enabled) {
+							t.Fatalf("Report is unexpectedly enabled")
+						}
+						return output, nil
+					})
+				task, err := c.CreateDataRepositoryTask(ctx, fileSystemId, string(types.DataRepositoryTaskTypeExport))
+				if err != nil {
+					t.Fatalf("CreateDataRepositoryTask is failed: %v", err)
+				}
+
+				if task.TaskId != taskId {
+					t.Fatalf("TaskId mismatches. actual: %v expected: %v", task.TaskId, taskId)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: filesystem not found",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				mockFSx.EXPECT().CreateDataRepositoryTask(gomock.Eq(ctx), gomock.Any()).Return(nil, &types.FileSystemNotFound{})
+				_, err := c.CreateDataRepositoryTask(ctx, fileSystemId, string(types.DataRepositoryTaskTypeExport))
+				if !errors.Is(err, ErrNotFound) {
+					t.Fatalf("CreateDataRepositoryTask is not ErrNotFound: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestWaitForDataRepositoryTask(t *testing.T) {
+	var (
+		taskId = "task-1234"
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: task succeeded",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				describeInput := &fsx.DescribeDataRepositoryTasksInput{
+					TaskIds: []string{taskId},
+				}
+				describeOutput := &fsx.DescribeDataRepositoryTasksOutput{
+					DataRepositoryTasks: []types.DataRepositoryTask{
+						{
+							TaskId:    aws.String(taskId),
+							Lifecycle: types.DataRepositoryTaskLifecycleSucceeded,
+						},
+					},
+				}
+
+				mockFSx.EXPECT().DescribeDataRepositoryTasks(gomock.Eq(ctx), gomock.Eq(describeInput)).Return(describeOutput, nil)
+				err := c.WaitForDataRepositoryTask(ctx, taskId)
+				if err != nil {
+					t.Fatalf("WaitForDataRepositoryTask is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "failure: task failed",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockFSx := mocks.NewMockFSx(mockCtl)
+				c := &cloud{
+					fsx: mockFSx,
+				}
+
+				ctx := context.Background()
+				describeOutput := &fsx.DescribeDataRepositoryTasksOutput{
+					DataRepositoryTasks: []types.DataRepositoryTask{
+						{
+							TaskId:    aws.String(taskId),
+							Lifecycle: types.DataRepositoryTaskLifecycleFailed,
+							FailureDetails: &types.DataRepositoryTaskFailureDetails{
+								Message: aws.String("Access denied to the S3 bucket"),
+							},
+						},
+					},
+				}
+
+				mockFSx.EXPECT().DescribeDataRepositoryTasks(gomock.Eq(ctx), gomock.Any()).Return(describeOutput, nil)
+				err := c.WaitForDataRepositoryTask(ctx, taskId)
+				if err == nil {
+					t.Fatalf("WaitForDataRepositoryTask is not failed")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestDescribeSubnet(t *testing.T) {
+	var (
+		subnetId         = "subnet-0eabfaa81fb22bcaf"
+		availabilityZone = "us-east-1a"
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: normal",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockEC2 := mocks.NewMockEC2(mockCtl)
+				c := &cloud{
+					ec2: mockEC2,
+				}
+
+				output := &ec2.DescribeSubnetsOutput{
+					Subnets: []ec2types.Subnet{
+						{
+							SubnetId:         aws.String(subnetId),
+							AvailabilityZone: aws.String(availabilityZone),
+						},
+					},
+				}
+				ctx := context.Background()
+				mockEC2.EXPECT().DescribeSubnets(gomock.Eq(ctx), gomock.Any()).Return(output, nil)
+				subnet, err := c.DescribeSubnet(ctx, subnetId)
+				if err != nil {
+					t.Fatalf("DescribeSubnet is failed: %v", err)
+				}
+
+				if subnet.SubnetId != subnetId {
+					t.Fatalf("SubnetId mismatches. actual: %v expected: %v", subnet.SubnetId, subnetId)
+				}
+
+				if subnet.AvailabilityZone != availabilityZone {
+					t.Fatalf("AvailabilityZone mismatches. actual: %v expected: %v", subnet.AvailabilityZone, availabilityZone)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: subnet not found",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockEC2 := mocks.NewMockEC2(mockCtl)
+				c := &cloud{
+					ec2: mockEC2,
 				}
-				if isBadRequestUpdateInProgress(errorInput) {
-					t.Fatalf("isBadRequestUpdateInProgress returned true, expected false")
+
+				ctx := context.Background()
+				mockEC2.EXPECT().DescribeSubnets(gomock.Eq(ctx), gomock.Any()).Return(nil, &smithy.GenericAPIError{Code: "InvalidSubnetID.NotFound"})
+				_, err := c.DescribeSubnet(ctx, subnetId)
+				if !errors.Is(err, ErrNotFound) {
+					t.Fatalf("DescribeSubnet is not ErrNotFound: %v", err)
 				}
+
+				mockCtl.Finish()
 			},
 		},
 		{
-			name: "failure: not AWS error",
+			name: "fail: DescribeSubnets return error",
 			testFunc: func(t *testing.T) {
-				errorInput := errors.New("test")
-				if isBadRequestUpdateInProgress(errorInput) {
-					t.Fatalf("isBadRequestUpdateInProgress returned true, expected false")
+				mockCtl := gomock.NewController(t)
+				mockEC2 := mocks.NewMockEC2(mockCtl)
+				c := &cloud{
+					ec2: mockEC2,
+				}
+
+				ctx := context.Background()
+				mockEC2.EXPECT().DescribeSubnets(gomock.Eq(ctx), gomock.Any()).Return(nil, errors.New("DescribeSubnets failed"))
+				_, err := c.DescribeSubnet(ctx, subnetId)
+				if err == nil || errors.Is(err, ErrNotFound) {
+					t.Fatalf("DescribeSubnet is not failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestFindSubnetsByTags(t *testing.T) {
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: filter by tags",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockEC2 := mocks.NewMockEC2(mockCtl)
+				c := &cloud{
+					ec2: mockEC2,
+				}
+
+				ctx := context.Background()
+				mockEC2.EXPECT().DescribeSubnets(gomock.Eq(ctx), gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, input *ec2.DescribeSubnetsInput, _ ...func(*ec2.Options)) (*ec2.DescribeSubnetsOutput, error) {
+						filters := map[string][]string{}
+						for _, filter := range input.Filters {
+							filters[aws.ToString(filter.Name)] = filter.Values
+						}
+						if len(filters) != 2 {
+							t.Fatalf("Filters length mismatches. actual: %v expected: %v", len(filters), 2)
+						}
+						if values := filters["tag:Tier"]; len(values) != 1 || values[0] != "private" {
+							t.Fatalf("tag:Tier filter mismatches. actual: %v expected: %v", values, []string{"private"})
+						}
+						if values := filters["tag-key"]; len(values) != 1 || values[0] != "kubernetes.io/cluster/test" {
+							t.Fatalf("tag-key filter mismatches. actual: %v expected: %v", values, []string{"kubernetes.io/cluster/test"})
+						}
+						return &ec2.DescribeSubnetsOutput{
+							Subnets: []ec2types.Subnet{
+								{
+									SubnetId:                aws.String("subnet-1"),
+									AvailabilityZone:        aws.String("us-east-1a"),
+									AvailableIpAddressCount: aws.Int32(100),
+								},
+							},
+						}, nil
+					})
+				subnets, err := c.FindSubnetsByTags(ctx, map[string]string{"Tier": "private", "kubernetes.io/cluster/test": ""})
+				if err != nil {
+					t.Fatalf("FindSubnetsByTags is failed: %v", err)
+				}
+
+				if len(subnets) != 1 {
+					t.Fatalf("Subnets length mismatches. actual: %v expected: %v", len(subnets), 1)
+				}
+
+				if subnets[0].AvailableIpAddressCount != 100 {
+					t.Fatalf("AvailableIpAddressCount mismatches. actual: %v expected: %v", subnets[0].AvailableIpAddressCount, 100)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: multiple pages",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockEC2 := mocks.NewMockEC2(mockCtl)
+				c := &cloud{
+					ec2: mockEC2,
+				}
+
+				ctx := context.Background()
+				gomock.InOrder(
+					mockEC2.EXPECT().DescribeSubnets(gomock.Eq(ctx), gomock.Any(), gomock.Any()).Return(&ec2.DescribeSubnetsOutput{
+						Subnets:   []ec2types.Subnet{{SubnetId: aws.String("subnet-1")}},
+						NextToken: aws.String("next"),
+					}, nil),
+					mockEC2.EXPECT().DescribeSubnets(gomock.Eq(ctx), gomock.Any(), gomock.Any()).Return(&ec2.DescribeSubnetsOutput{
+						Subnets: []ec2types.Subnet{{SubnetId: aws.String("subnet-2")}},
+					}, nil),
+				)
+				subnets, err := c.FindSubnetsByTags(ctx, map[string]string{"Tier": "private"})
+				if err != nil {
+					t.Fatalf("FindSubnetsByTags is failed: %v", err)
+				}
+
+				if len(subnets) != 2 {
+					t.Fatalf("Subnets length mismatches. actual: %v expected: %v", len(subnets), 2)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: DescribeSubnets return error",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockEC2 := mocks.NewMockEC2(mockCtl)
+				c := &cloud{
+					ec2: mockEC2,
+				}
+
+				ctx := context.Background()
+				mockEC2.EXPECT().DescribeSubnets(gomock.Eq(ctx), gomock.Any(), gomock.Any()).Return(nil, errors.New("DescribeSubnets failed"))
+				_, err := c.FindSubnetsByTags(ctx, map[string]string{"Tier": "private"})
+				if err == nil {
+					t.Fatalf("FindSubnetsByTags is not failed")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestEnsureClusterSecurityGroup(t *testing.T) {
+	var (
+		clusterName          = "test-cluster"
+		vpcId                = "vpc-1234"
+		groupId              = "sg-cluster"
+		nodeSecurityGroupIds = []string{"sg-node"}
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: security group exists",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockEC2 := mocks.NewMockEC2(mockCtl)
+				c := &cloud{
+					ec2: mockEC2,
+				}
+
+				ctx := context.Background()
+				mockEC2.EXPECT().DescribeSecurityGroups(gomock.Eq(ctx), gomock.Any(), gomock.Any()).Return(&ec2.DescribeSecurityGroupsOutput{
+					SecurityGroups: []ec2types.SecurityGroup{{GroupId: aws.String(groupId)}},
+				}, nil)
+				mockEC2.EXPECT().CreateTags(gomock.Eq(ctx), gomock.Any()).DoAndReturn(
+					func(_ context.Context, input *ec2.CreateTagsInput, _ ...func(*ec2.Options)) (*ec2.CreateTagsOutput, error) {
+						if input.Resources[0] != groupId {
+							t.Fatalf("Resource mismatches. actual: %v expected: %v", input.Resources[0], groupId)
+						}
+						if aws.ToString(input.Tags[0].Key) != ClusterSecurityGroupLastUsedTagKey {
+							t.Fatalf("Tag key mismatches. actual: %v expected: %v", aws.ToString(input.Tags[0].Key), ClusterSecurityGroupLastUsedTagKey)
+						}
+						return &ec2.CreateTagsOutput{}, nil
+					})
+				id, err := c.EnsureClusterSecurityGroup(ctx, clusterName, vpcId, nodeSecurityGroupIds)
+				if err != nil {
+					t.Fatalf("EnsureClusterSecurityGroup is failed: %v", err)
+				}
+
+				if id != groupId {
+					t.Fatalf("GroupId mismatches. actual: %v expected: %v", id, groupId)
 				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: security group created",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockEC2 := mocks.NewMockEC2(mockCtl)
+				c := &cloud{
+					ec2: mockEC2,
+				}
+
+				ctx := context.Background()
+				mockEC2.EXPECT().DescribeSecurityGroups(gomock.Eq(ctx), gomock.Any(), gomock.Any()).Return(&ec2.DescribeSecurityGroupsOutput{}, nil)
+				mockEC2.EXPECT().CreateSecurityGroup(gomock.Eq(ctx), gomock.Any()).DoAndReturn(
+					func(_ context.Context, input *ec2.CreateSecurityGroupInput, _ ...func(*ec2.Options)) (*ec2.CreateSecurityGroupOutput, error) {
+						if aws.ToString(input.VpcId) != vpcId {
+							t.Fatalf("VpcId mismatches. actual: %v expected: %v", aws.ToString(input.VpcId), vpcId)
+						}
+						tag := input.TagSpecifications[0].Tags[0]
+						if aws.ToString(tag.Key) != ClusterSecurityGroupTagKey || aws.ToString(tag.Value) != clusterName {
+							t.Fatalf("Tag mismatches. actual: %v=%v expected: %v=%v", aws.ToString(tag.Key), aws.ToString(tag.Value), ClusterSecurityGroupTagKey, clusterName)
+						}
+						return &ec2.CreateSecurityGroupOutput{GroupId: aws.String(groupId)}, nil
+					})
+				mockEC2.EXPECT().AuthorizeSecurityGroupIngress(gomock.Eq(ctx), gomock.Any()).DoAndReturn(
+					func(_ context.Context, input *ec2.AuthorizeSecurityGroupIngressInput, _ ...func(*ec2.Options)) (*ec2.AuthorizeSecurityGroupIngressOutput, error) {
+						if len(input.IpPermissions) != 2 {
+							t.Fatalf("IpPermissions length mismatches. actual: %v expected: %v", len(input.IpPermissions), 2)
+						}
+						permission := input.IpPermissions[1]
+						if aws.ToInt32(permission.FromPort) != 1018 || aws.ToInt32(permission.ToPort) != 1023 {
+							t.Fatalf("Port range mismatches. actual: %v-%v expected: 1018-1023", aws.ToInt32(permission.FromPort), aws.ToInt32(permission.ToPort))
+						}
+						if len(permission.UserIdGroupPairs) != 2 {
+							t.Fatalf("UserIdGroupPairs length mismatches. actual: %v expected: %v", len(permission.UserIdGroupPairs), 2)
+						}
+						return &ec2.AuthorizeSecurityGroupIngressOutput{}, nil
+					})
+				id, err := c.EnsureClusterSecurityGroup(ctx, clusterName, vpcId, nodeSecurityGroupIds)
+				if err != nil {
+					t.Fatalf("EnsureClusterSecurityGroup is failed: %v", err)
+				}
+
+				if id != groupId {
+					t.Fatalf("GroupId mismatches. actual: %v expected: %v", id, groupId)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: security group created concurrently",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockEC2 := mocks.NewMockEC2(mockCtl)
+				c := &cloud{
+					ec2: mockEC2,
+				}
+
+				ctx := context.Background()
+				gomock.InOrder(
+					mockEC2.EXPECT().DescribeSecurityGroups(gomock.Eq(ctx), gomock.Any(), gomock.Any()).Return(&ec2.DescribeSecurityGroupsOutput{}, nil),
+					mockEC2.EXPECT().CreateSecurityGroup(gomock.Eq(ctx), gomock.Any()).Return(nil, &smithy.GenericAPIError{Code: "InvalidGroup.Duplicate"}),
+					mockEC2.EXPECT().DescribeSecurityGroups(gomock.Eq(ctx), gomock.Any(), gomock.Any()).Return(&ec2.DescribeSecurityGroupsOutput{
+						SecurityGroups: []ec2types.SecurityGroup{{GroupId: aws.String(groupId)}},
+					}, nil),
+					mockEC2.EXPECT().CreateTags(gomock.Eq(ctx), gomock.Any()).Return(&ec2.CreateTagsOutput{}, nil),
+				)
+				id, err := c.EnsureClusterSecurityGroup(ctx, clusterName, vpcId, nodeSecurityGroupIds)
+				if err != nil {
+					t.Fatalf("EnsureClusterSecurityGroup is failed: %v", err)
+				}
+
+				if id != groupId {
+					t.Fatalf("GroupId mismatches. actual: %v expected: %v", id, groupId)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: AuthorizeSecurityGroupIngress return error",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockEC2 := mocks.NewMockEC2(mockCtl)
+				c := &cloud{
+					ec2: mockEC2,
+				}
+
+				ctx := context.Background()
+				mockEC2.EXPECT().DescribeSecurityGroups(gomock.Eq(ctx), gomock.Any(), gomock.Any()).Return(&ec2.DescribeSecurityGroupsOutput{}, nil)
+				mockEC2.EXPECT().CreateSecurityGroup(gomock.Eq(ctx), gomock.Any()).Return(&ec2.CreateSecurityGroupOutput{GroupId: aws.String(groupId)}, nil)
+				mockEC2.EXPECT().AuthorizeSecurityGroupIngress(gomock.Eq(ctx), gomock.Any()).Return(nil, errors.New("AuthorizeSecurityGroupIngress failed"))
+				mockEC2.EXPECT().DeleteSecurityGroup(gomock.Eq(ctx), gomock.Any()).Return(&ec2.DeleteSecurityGroupOutput{}, nil)
+				_, err := c.EnsureClusterSecurityGroup(ctx, clusterName, vpcId, nodeSecurityGroupIds)
+				if err == nil {
+					t.Fatalf("EnsureClusterSecurityGroup is not failed")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestDeleteUnusedClusterSecurityGroups(t *testing.T) {
+	var (
+		clusterName = "test-cluster"
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: unused security group is deleted and used one is kept",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockEC2 := mocks.NewMockEC2(mockCtl)
+				c := &cloud{
+					ec2: mockEC2,
+				}
+
+				ctx := context.Background()
+				mockEC2.EXPECT().DescribeSecurityGroups(gomock.Eq(ctx), gomock.Any(), gomock.Any()).Return(&ec2.DescribeSecurityGroupsOutput{
+					SecurityGroups: []ec2types.SecurityGroup{
+						{GroupId: aws.String("sg-used")},
+						{
+							GroupId: aws.String("sg-unused"),
+							Tags:    []ec2types.Tag{{Key: aws.String(ClusterSecurityGroupLastUsedTagKey), Value: aws.String("2020-01-01T00:00:00Z")}},
+						},
+					},
+				}, nil)
+				mockEC2.EXPECT().DescribeNetworkInterfaces(gomock.Eq(ctx), gomock.Any()).DoAndReturn(
+					func(_ context.Context, input *ec2.DescribeNetworkInterfacesInput, _ ...func(*ec2.Options)) (*ec2.DescribeNetworkInterfacesOutput, error) {
+						if input.Filters[0].Values[0] == "sg-used" {
+							return &ec2.DescribeNetworkInterfacesOutput{
+								NetworkInterfaces: []ec2types.NetworkInterface{{NetworkInterfaceId: aws.String("eni-1234")}},
+							}, nil
+						}
+						return &ec2.DescribeNetworkInterfacesOutput{}, nil
+					}).Times(2)
+				mockEC2.EXPECT().DeleteSecurityGroup(gomock.Eq(ctx), gomock.Any()).DoAndReturn(
+					func(_ context.Context, input *ec2.DeleteSecurityGroupInput, _ ...func(*ec2.Options)) (*ec2.DeleteSecurityGroupOutput, error) {
+						if aws.ToString(input.GroupId) != "sg-unused" {
+							t.Fatalf("GroupId mismatches. actual: %v expected: %v", aws.ToString(input.GroupId), "sg-unused")
+						}
+						return &ec2.DeleteSecurityGroupOutput{}, nil
+					})
+				err := c.DeleteUnusedClusterSecurityGroups(ctx, clusterName)
+				if err != nil {
+					t.Fatalf("DeleteUnusedClusterSecurityGroups is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: recently used security group is kept",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockEC2 := mocks.NewMockEC2(mockCtl)
+				c := &cloud{
+					ec2: mockEC2,
+				}
+
+				ctx := context.Background()
+				lastUsed := time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+				mockEC2.EXPECT().DescribeSecurityGroups(gomock.Eq(ctx), gomock.Any(), gomock.Any()).Return(&ec2.DescribeSecurityGroupsOutput{
+					SecurityGroups: []ec2types.SecurityGroup{
+						{
+							GroupId: aws.String("sg-recent"),
+							Tags:    []ec2types.Tag{{Key: aws.String(ClusterSecurityGroupLastUsedTagKey), Value: aws.String(lastUsed)}},
+						},
+					},
+				}, nil)
+				err := c.DeleteUnusedClusterSecurityGroups(ctx, clusterName)
+				if err != nil {
+					t.Fatalf("DeleteUnusedClusterSecurityGroups is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: security group became used",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockEC2 := mocks.NewMockEC2(mockCtl)
+				c := &cloud{
+					ec2: mockEC2,
+				}
+
+				ctx := context.Background()
+				mockEC2.EXPECT().DescribeSecurityGroups(gomock.Eq(ctx), gomock.Any(), gomock.Any()).Return(&ec2.DescribeSecurityGroupsOutput{
+					SecurityGroups: []ec2types.SecurityGroup{{GroupId: aws.String("sg-unused")}},
+				}, nil)
+				mockEC2.EXPECT().DescribeNetworkInterfaces(gomock.Eq(ctx), gomock.Any()).Return(&ec2.DescribeNetworkInterfacesOutput{}, nil)
+				mockEC2.EXPECT().DeleteSecurityGroup(gomock.Eq(ctx), gomock.Any()).Return(nil, &smithy.GenericAPIError{Code: "DependencyViolation"})
+				err := c.DeleteUnusedClusterSecurityGroups(ctx, clusterName)
+				if err != nil {
+					t.Fatalf("DeleteUnusedClusterSecurityGroups is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: DeleteSecurityGroup return error",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockEC2 := mocks.NewMockEC2(mockCtl)
+				c := &cloud{
+					ec2: mockEC2,
+				}
+
+				ctx := context.Background()
+				mockEC2.EXPECT().DescribeSecurityGroups(gomock.Eq(ctx), gomock.Any(), gomock.Any()).Return(&ec2.DescribeSecurityGroupsOutput{
+					SecurityGroups: []ec2types.SecurityGroup{{GroupId: aws.String("sg-unused")}},
+				}, nil)
+				mockEC2.EXPECT().DescribeNetworkInterfaces(gomock.Eq(ctx), gomock.Any()).Return(&ec2.DescribeNetworkInterfacesOutput{}, nil)
+				mockEC2.EXPECT().DeleteSecurityGroup(gomock.Eq(ctx), gomock.Any()).Return(nil, errors.New("DeleteSecurityGroup failed"))
+				err := c.DeleteUnusedClusterSecurityGroups(ctx, clusterName)
+				if err == nil {
+					t.Fatalf("DeleteUnusedClusterSecurityGroups is not failed")
+				}
+
+				mockCtl.Finish()
 			},
 		},
 	}
@@ -1715,3 +4170,28 @@ func TestIsBadRequestUpdateInProgress(t *testing.T) {
 		t.Run(tc.name, tc.testFunc)
 	}
 }
+
+func TestListFileSystems(t *testing.T) {
+	c := &cloud{
+		volumeCache: map[string]*FileSystem{
+			"volume-b": {FileSystemId: "fs-2"},
+			"volume-a": {FileSystemId: "fs-3"},
+			"volume-c": {FileSystemId: "fs-1"},
+		},
+	}
+
+	fileSystems, err := c.ListFileSystems(context.Background())
+	if err != nil {
+		t.Fatalf("ListFileSystems is failed: %v", err)
+	}
+
+	if len(fileSystems) != 3 {
+		t.Fatalf("FileSystems length mismatches. actual: %v expected: %v", len(fileSystems), 3)
+	}
+
+	for i, expected := range []string{"fs-1", "fs-2", "fs-3"} {
+		if fileSystems[i].FileSystemId != expected {
+			t.Fatalf("FileSystemId mismatches. actual: %v expected: %v", fileSystems[i].FileSystemId, expected)
+		}
+	}
+}
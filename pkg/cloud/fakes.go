@@ -20,9 +20,15 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// FakeAvailabilityZone is the Availability Zone of every subnet of the fake cloud provider
+const FakeAvailabilityZone = "us-east-1a"
+
 var random *rand.Rand
 
 func init() {
@@ -30,14 +36,20 @@ func init() {
 }
 
 type FakeCloudProvider struct {
-	m           *Metadata
-	fileSystems map[string]*FileSystem
+	m            *Metadata
+	fileSystems  map[string]*FileSystem
+	backups      map[string]*Backup
+	associations map[string]*DataRepositoryAssociation
+	tasks        map[string]*DataRepositoryTask
 }
 
 func NewFakeCloudProvider() *FakeCloudProvider {
 	return &FakeCloudProvider{
-		m:           &Metadata{InstanceID: "InstanceID", InstanceType: "Region", Region: "az"},
-		fileSystems: make(map[string]*FileSystem),
+		m:            &Metadata{InstanceID: "InstanceID", InstanceType: "Region", Region: "az"},
+		fileSystems:  make(map[string]*FileSystem),
+		backups:      make(map[string]*Backup),
+		associations: make(map[string]*DataRepositoryAssociation),
+		tasks:        make(map[string]*DataRepositoryTask),
 	}
 }
 
@@ -60,33 +72,74 @@ func (c *FakeCloudProvider) CreateFileSystem(ctx context.Context, volumeName str
 		CapacityGiB:              fileSystemOptions.CapacityGiB,
 		DnsName:                  "test.us-east-1.fsx.amazonaws.com",
 		MountName:                "random",
+		SubnetId:                 fileSystemOptions.SubnetId,
 		StorageType:              fileSystemOptions.StorageType,
 		DeploymentType:           fileSystemOptions.DeploymentType,
 		PerUnitStorageThroughput: fileSystemOptions.PerUnitStorageThroughput,
+		Lifecycle:                "AVAILABLE",
+		Tags:                     newFakeTags(fileSystemOptions.ExtraTags),
 	}
 	c.fileSystems[volumeName] = fs
 	return fs, nil
 }
 
-func (c *FakeCloudProvider) ResizeFileSystem(ctx context.Context, volumeName string, newSizeGiB int32) (int32, error) {
+func (c *FakeCloudProvider) CreateFileSystemFromBackup(ctx context.Context, volumeName string, backupId string, fileSystemOptions *FileSystemOptions) (fs *FileSystem, err error) {
+	backup, err := c.DescribeBackup(ctx, backupId)
+	if err != nil {
+		return nil, err
+	}
+
 	fs, exists := c.fileSystems[volumeName]
-	if !exists {
-		return 0, ErrNotFound
+	if exists {
+		if fs.CapacityGiB == fileSystemOptions.CapacityGiB {
+			return fs, nil
+		} else {
+			return nil, ErrFsExistsDiffSize
+		}
 	}
 
-	fs.CapacityGiB = newSizeGiB
+	perUnitStorageThroughput := backup.PerUnitStorageThroughput
+	if fileSystemOptions.PerUnitStorageThroughput != 0 {
+		perUnitStorageThroughput = fileSystemOptions.PerUnitStorageThroughput
+	}
+
+	fs = &FileSystem{
+		FileSystemId:             fmt.Sprintf("fs-%d", random.Uint64()),
+		CapacityGiB:              fileSystemOptions.CapacityGiB,
+		DnsName:                  "test.us-east-1.fsx.amazonaws.com",
+		MountName:                "random",
+		SubnetId:                 fileSystemOptions.SubnetId,
+		StorageType:              backup.StorageType,
+		DeploymentType:           backup.DeploymentType,
+		PerUnitStorageThroughput: perUnitStorageThroughput,
+		Lifecycle:                "AVAILABLE",
+		Tags:                     newFakeTags(fileSystemOptions.ExtraTags),
+	}
 	c.fileSystems[volumeName] = fs
+	return fs, nil
+}
+
+func (c *FakeCloudProvider) ResizeFileSystem(ctx context.Context, fileSystemId string, newSizeGiB int32) (int32, error) {
+	fs, err := c.DescribeFileSystem(ctx, fileSystemId)
+	if err != nil {
+		return 0, err
+	}
+
+	fs.CapacityGiB = newSizeGiB
 	return newSizeGiB, nil
 }
 
-func (c *FakeCloudProvider) DeleteFileSystem(ctx context.Context, volumeID string) (err error) {
+func (c *FakeCloudProvider) DeleteFileSystem(ctx context.Context, volumeID string, deleteOptions *DeleteFileSystemOptions) (backupId string, err error) {
 	delete(c.fileSystems, volumeID)
 	for name, fs := range c.fileSystems {
 		if fs.FileSystemId == volumeID {
 			delete(c.fileSystems, name)
 		}
 	}
-	return nil
+	if deleteOptions != nil && deleteOptions.FinalBackup {
+		backupId = fmt.Sprintf("backup-%d", random.Uint64())
+	}
+	return backupId, nil
 }
 
 func (c *FakeCloudProvider) DescribeFileSystem(ctx context.Context, volumeID string) (fs *FileSystem, err error) {
@@ -106,6 +159,40 @@ func (c *FakeCloudProvider) WaitForFileSystemResize(ctx context.Context, fileSys
 	return nil
 }
 
+func (c *FakeCloudProvider) UpdateFileSystem(ctx context.Context, fileSystemId string, updateOptions *FileSystemUpdateOptions) error {
+	fs, err := c.DescribeFileSystem(ctx, fileSystemId)
+	if err != nil {
+		return err
+	}
+
+	if updateOptions.PerUnitStorageThroughput != 0 {
+		fs.PerUnitStorageThroughput = updateOptions.PerUnitStorageThroughput
+	}
+	if updateOptions.DataCompressionType != "" {
+		fs.DataCompressionType = updateOptions.DataCompressionType
+	}
+	if updateOptions.MetadataConfigurationMode != "" {
+		fs.MetadataConfigurationMode = updateOptions.MetadataConfigurationMode
+	}
+	if updateOptions.MetadataIops != 0 {
+		fs.MetadataIops = updateOptions.MetadataIops
+	}
+	if updateOptions.AutomaticBackupRetentionDays != nil {
+		fs.AutomaticBackupRetentionDays = *updateOptions.AutomaticBackupRetentionDays
+	}
+	if updateOptions.DailyAutomaticBackupStartTime != "" {
+		fs.DailyAutomaticBackupStartTime = updateOptions.DailyAutomaticBackupStartTime
+	}
+	if updateOptions.WeeklyMaintenanceStartTime != "" {
+		fs.WeeklyMaintenanceStartTime = updateOptions.WeeklyMaintenanceStartTime
+	}
+	return nil
+}
+
+func (c *FakeCloudProvider) WaitForFileSystemUpdate(ctx context.Context, fileSystemId string, updateOptions *FileSystemUpdateOptions) error {
+	return nil
+}
+
 func (c *FakeCloudProvider) FindFileSystemByVolumeName(ctx context.Context, volumeName string) (*FileSystem, error) {
 	// Check if filesystem exists for this volume name
 	fs, exists := c.fileSystems[volumeName]
@@ -114,3 +201,217 @@ func (c *FakeCloudProvider) FindFileSystemByVolumeName(ctx context.Context, volu
 	}
 	return nil, ErrNotFound
 }
+
+func (c *FakeCloudProvider) ListFileSystems(ctx context.Context) ([]*FileSystem, error) {
+	fileSystems := make([]*FileSystem, 0, len(c.fileSystems))
+	for _, fs := range c.fileSystems {
+		fileSystems = append(fileSystems, fs)
+	}
+	sort.Slice(fileSystems, func(i, j int) bool {
+		return fileSystems[i].FileSystemId < fileSystems[j].FileSystemId
+	})
+	return fileSystems, nil
+}
+
+func (c *FakeCloudProvider) CreateBackup(ctx context.Context, snapshotName string, backupOptions *BackupOptions) (*Backup, error) {
+	backup, exists := c.backups[snapshotName]
+	if exists {
+		if backup.FileSystemId == backupOptions.FileSystemId {
+			return backup, nil
+		} else {
+			return nil, ErrBackupExistsDiffFs
+		}
+	}
+
+	fs, err := c.DescribeFileSystem(ctx, backupOptions.FileSystemId)
+	if err != nil {
+		return nil, err
+	}
+
+	backup = &Backup{
+		BackupId:                 fmt.Sprintf("backup-%d", random.Uint64()),
+		FileSystemId:             fs.FileSystemId,
+		CapacityGiB:              fs.CapacityGiB,
+		StorageType:              fs.StorageType,
+		DeploymentType:           fs.DeploymentType,
+		PerUnitStorageThroughput: fs.PerUnitStorageThroughput,
+		CreationTime:             time.Now(),
+		Lifecycle:                "AVAILABLE",
+		Tags:                     newFakeTags(backupOptions.ExtraTags),
+	}
+	if backupOptions.CloneVolumeName != "" {
+		backup.Tags[CloneVolumeNameTagKey] = backupOptions.CloneVolumeName
+	} else {
+		backup.Tags[SnapshotNameTagKey] = snapshotName
+	}
+	c.backups[snapshotName] = backup
+	return backup, nil
+}
+
+func (c *FakeCloudProvider) DeleteBackup(ctx context.Context, backupId string) error {
+	for name, backup := range c.backups {
+		if backup.BackupId == backupId {
+			delete(c.backups, name)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (c *FakeCloudProvider) DescribeBackup(ctx context.Context, backupId string) (*Backup, error) {
+	for _, backup := range c.backups {
+		if backup.BackupId == backupId {
+			return backup, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (c *FakeCloudProvider) WaitForBackupAvailable(ctx context.Context, backupId string) error {
+	return nil
+}
+
+func (c *FakeCloudProvider) ListBackups(ctx context.Context, fileSystemId string, maxResults int32, nextToken string) ([]*Backup, string, error) {
+	var backups []*Backup
+	for _, backup := range c.backups {
+		if fileSystemId == "" || backup.FileSystemId == fileSystemId {
+			backups = append(backups, backup)
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].BackupId < backups[j].BackupId
+	})
+
+	start := 0
+	if nextToken != "" {
+		n, err := strconv.Atoi(nextToken)
+		if err != nil || n > len(backups) {
+			return nil, "", fmt.Errorf("invalid next token %q", nextToken)
+		}
+		start = n
+	}
+
+	end := len(backups)
+	if maxResults > 0 && start+int(maxResults) < end {
+		end = start + int(maxResults)
+	}
+
+	if end < len(backups) {
+		return backups[start:end], strconv.Itoa(end), nil
+	}
+	return backups[start:end], "", nil
+}
+
+func (c *FakeCloudProvider) FindCloneBackup(ctx context.Context, fileSystemId string, volumeName string) (*Backup, error) {
+	for _, backup := range c.backups {
+		if backup.FileSystemId == fileSystemId && backup.Tags[CloneVolumeNameTagKey] == volumeName {
+			return backup, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (c *FakeCloudProvider) DescribeSubnet(ctx context.Context, subnetId string) (*Subnet, error) {
+	return &Subnet{
+		SubnetId:         subnetId,
+		VpcId:            "vpc-0123456789abcdef0",
+		AvailabilityZone: FakeAvailabilityZone,
+	}, nil
+}
+
+func (c *FakeCloudProvider) FindSubnetsByTags(ctx context.Context, tags map[string]string) ([]*Subnet, error) {
+	return []*Subnet{
+		{
+			SubnetId:                "subnet-0123456789abcdef0",
+			VpcId:                   "vpc-0123456789abcdef0",
+			AvailabilityZone:        FakeAvailabilityZone,
+			AvailableIpAddressCount: 251,
+		},
+	}, nil
+}
+
+func (c *FakeCloudProvider) FindSecurityGroupsByTags(ctx context.Context, vpcId string, tags map[string]string) ([]string, error) {
+	return []string{"sg-0123456789abcdef0"}, nil
+}
+
+func (c *FakeCloudProvider) EnsureClusterSecurityGroup(ctx context.Context, clusterName string, vpcId string, sourceSecurityGroupIds []string) (string, error) {
+	return "sg-0fedcba9876543210", nil
+}
+
+func (c *FakeCloudProvider) DeleteUnusedClusterSecurityGroups(ctx context.Context, clusterName string) error {
+	return nil
+}
+
+func (c *FakeCloudProvider) CreateDataRepositoryAssociation(ctx context.Context, fileSystemId string, associationOptions *DataRepositoryAssociationOptions) (*DataRepositoryAssociation, error) {
+	association := &DataRepositoryAssociation{
+		AssociationId:      fmt.Sprintf("dra-%d", random.Uint64()),
+		FileSystemId:       fileSystemId,
+		FileSystemPath:     associationOptions.FileSystemPath,
+		DataRepositoryPath: associationOptions.DataRepositoryPath,
+		Lifecycle:          "AVAILABLE",
+	}
+	c.associations[association.AssociationId] = association
+	return association, nil
+}
+
+func (c *FakeCloudProvider) DescribeDataRepositoryAssociation(ctx context.Context, associationId string) (*DataRepositoryAssociation, error) {
+	if association, ok := c.associations[associationId]; ok {
+		return association, nil
+	}
+	return nil, ErrNotFound
+}
+
+func (c *FakeCloudProvider) WaitForDataRepositoryAssociationAvailable(ctx context.Context, associationId string) error {
+	return nil
+}
+
+func (c *FakeCloudProvider) ListDataRepositoryAssociations(ctx context.Context, fileSystemId string) ([]*DataRepositoryAssociation, error) {
+	var associations []*DataRepositoryAssociation
+	for _, association := range c.associations {
+		if association.FileSystemId == fileSystemId {
+			associations = append(associations, association)
+		}
+	}
+	return associations, nil
+}
+
+func (c *FakeCloudProvider) CreateDataRepositoryTask(ctx context.Context, fileSystemId string, taskType string) (*DataRepositoryTask, error) {
+	task := &DataRepositoryTask{
+		TaskId:       fmt.Sprintf("task-%d", random.Uint64()),
+		FileSystemId: fileSystemId,
+		Type:         taskType,
+		Lifecycle:    "SUCCEEDED",
+	}
+	c.tasks[task.TaskId] = task
+	return task, nil
+}
+
+func (c *FakeCloudProvider) DescribeDataRepositoryTask(ctx context.Context, taskId string) (*DataRepositoryTask, error) {
+	if task, ok := c.tasks[taskId]; ok {
+		return task, nil
+	}
+	return nil, ErrNotFound
+}
+
+func (c *FakeCloudProvider) WaitForDataRepositoryTask(ctx context.Context, taskId string) error {
+	return nil
+}
+
+func (c *FakeCloudProvider) ListDataRepositoryTasks(ctx context.Context, fileSystemId string) ([]*DataRepositoryTask, error) {
+	var tasks []*DataRepositoryTask
+	for _, task := range c.tasks {
+		if task.FileSystemId == fileSystemId {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+func newFakeTags(extraTags []string) map[string]string {
+	tags := make(map[string]string, len(extraTags))
+	for _, extraTag := range extraTags {
+		key, value, _ := strings.Cut(extraTag, "=")
+		tags[key] = value
+	}
+	return tags
+}
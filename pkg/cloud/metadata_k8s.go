@@ -7,6 +7,7 @@ import (
 	"regexp"
 	"strings"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -47,12 +48,19 @@ func KubernetesAPIInstanceInfo(clientset kubernetes.Interface) (*Metadata, error
 		return nil, fmt.Errorf("node providerID empty, cannot parse")
 	}
 
+	var instanceInfo *Metadata
 	if strings.HasPrefix(providerID, eksHybridPrefix) {
-		return metadataForHybridNode(providerID)
+		instanceInfo, err = metadataForHybridNode(providerID)
+	} else {
+		// if not hybrid, assume AWS EC2
+		instanceInfo, err = metadataForEC2Node(providerID)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	// if not hybrid, assume AWS EC2
-	return metadataForEC2Node(providerID)
+	instanceInfo.AvailabilityZone = node.Labels[corev1.LabelTopologyZone]
+	return instanceInfo, nil
 }
 
 func metadataForEC2Node(providerID string) (*Metadata, error) {
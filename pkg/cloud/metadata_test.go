@@ -25,6 +25,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
 	"go.uber.org/mock/gomock"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
@@ -63,7 +64,6 @@ func TestNewMetadataService(t *testing.T) {
 			},
 			expectedErr: nil,
 		},
-		// TODO: Once topology is implemented, add test cases for kubernetes metadata
 		{
 			name:                   "failure: metadata not available, k8s client error",
 			ec2MetadataClientError: fmt.Errorf("foo"),
@@ -189,3 +189,86 @@ func TestNewMetadataService(t *testing.T) {
 		})
 	}
 }
+
+func TestKubernetesAPIInstanceInfo(t *testing.T) {
+	testCases := []struct {
+		name             string
+		node             v1.Node
+		expectedErr      error
+		expectedMetadata *Metadata
+	}{
+		{
+			name: "success: ec2 node with zone label",
+			node: v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   nodeName,
+					Labels: map[string]string{v1.LabelTopologyZone: stdAvailabilityZone},
+				},
+				Spec: v1.NodeSpec{ProviderID: "aws:///" + stdAvailabilityZone + "/" + stdInstanceID},
+			},
+			expectedMetadata: &Metadata{
+				InstanceID:       stdInstanceID,
+				AvailabilityZone: stdAvailabilityZone,
+			},
+		},
+		{
+			name: "success: hybrid node with zone label",
+			node: v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   nodeName,
+					Labels: map[string]string{v1.LabelTopologyZone: stdAvailabilityZone},
+				},
+				Spec: v1.NodeSpec{ProviderID: "eks-hybrid:///" + stdRegion + "/cluster/mi-0123456789abcdef0"},
+			},
+			expectedMetadata: &Metadata{
+				InstanceID:       "mi-0123456789abcdef0",
+				Region:           stdRegion,
+				AvailabilityZone: stdAvailabilityZone,
+			},
+		},
+		{
+			name: "success: node without zone label",
+			node: v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: nodeName,
+				},
+				Spec: v1.NodeSpec{ProviderID: "aws:///" + stdAvailabilityZone + "/" + stdInstanceID},
+			},
+			expectedMetadata: &Metadata{
+				InstanceID: stdInstanceID,
+			},
+		},
+		{
+			name: "fail: empty providerID",
+			node: v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: nodeName,
+				},
+			},
+			expectedErr: fmt.Errorf("node providerID empty, cannot parse"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("CSI_NODE_NAME", nodeName)
+			clientset := fake.NewSimpleClientset(&tc.node)
+
+			m, err := KubernetesAPIInstanceInfo(clientset)
+			if err != nil {
+				if tc.expectedErr == nil {
+					t.Fatalf("got error %q, expected no error", err)
+				} else if err.Error() != tc.expectedErr.Error() {
+					t.Fatalf("got error %q, expected %q", err, tc.expectedErr)
+				}
+				return
+			}
+			if tc.expectedErr != nil {
+				t.Fatalf("got no error, expected %q", tc.expectedErr)
+			}
+			if *m != *tc.expectedMetadata {
+				t.Fatalf("KubernetesAPIInstanceInfo() failed: got %+v, expected %+v", *m, *tc.expectedMetadata)
+			}
+		})
+	}
+}
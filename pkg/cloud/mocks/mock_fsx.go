@@ -41,6 +41,66 @@ func (m *MockFSx) EXPECT() *MockFSxMockRecorder {
 	return m.recorder
 }
 
+// CreateBackup mocks base method.
+func (m *MockFSx) CreateBackup(arg0 context.Context, arg1 *fsx.CreateBackupInput, arg2 ...func(*fsx.Options)) (*fsx.CreateBackupOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateBackup", varargs...)
+	ret0, _ := ret[0].(*fsx.CreateBackupOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBackup indicates an expected call of CreateBackup.
+func (mr *MockFSxMockRecorder) CreateBackup(arg0, arg1 any, arg2 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBackup", reflect.TypeOf((*MockFSx)(nil).CreateBackup), varargs...)
+}
+
+// CreateDataRepositoryAssociation mocks base method.
+func (m *MockFSx) CreateDataRepositoryAssociation(arg0 context.Context, arg1 *fsx.CreateDataRepositoryAssociationInput, arg2 ...func(*fsx.Options)) (*fsx.CreateDataRepositoryAssociationOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateDataRepositoryAssociation", varargs...)
+	ret0, _ := ret[0].(*fsx.CreateDataRepositoryAssociationOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDataRepositoryAssociation indicates an expected call of CreateDataRepositoryAssociation.
+func (mr *MockFSxMockRecorder) CreateDataRepositoryAssociation(arg0, arg1 any, arg2 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDataRepositoryAssociation", reflect.TypeOf((*MockFSx)(nil).CreateDataRepositoryAssociation), varargs...)
+}
+
+// CreateDataRepositoryTask mocks base method.
+func (m *MockFSx) CreateDataRepositoryTask(arg0 context.Context, arg1 *fsx.CreateDataRepositoryTaskInput, arg2 ...func(*fsx.Options)) (*fsx.CreateDataRepositoryTaskOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateDataRepositoryTask", varargs...)
+	ret0, _ := ret[0].(*fsx.CreateDataRepositoryTaskOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDataRepositoryTask indicates an expected call of CreateDataRepositoryTask.
+func (mr *MockFSxMockRecorder) CreateDataRepositoryTask(arg0, arg1 any, arg2 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDataRepositoryTask", reflect.TypeOf((*MockFSx)(nil).CreateDataRepositoryTask), varargs...)
+}
+
 // CreateFileSystem mocks base method.
 func (m *MockFSx) CreateFileSystem(arg0 context.Context, arg1 *fsx.CreateFileSystemInput, arg2 ...func(*fsx.Options)) (*fsx.CreateFileSystemOutput, error) {
 	m.ctrl.T.Helper()
@@ -61,6 +121,46 @@ func (mr *MockFSxMockRecorder) CreateFileSystem(arg0, arg1 any, arg2 ...any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFileSystem", reflect.TypeOf((*MockFSx)(nil).CreateFileSystem), varargs...)
 }
 
+// CreateFileSystemFromBackup mocks base method.
+func (m *MockFSx) CreateFileSystemFromBackup(arg0 context.Context, arg1 *fsx.CreateFileSystemFromBackupInput, arg2 ...func(*fsx.Options)) (*fsx.CreateFileSystemFromBackupOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateFileSystemFromBackup", varargs...)
+	ret0, _ := ret[0].(*fsx.CreateFileSystemFromBackupOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFileSystemFromBackup indicates an expected call of CreateFileSystemFromBackup.
+func (mr *MockFSxMockRecorder) CreateFileSystemFromBackup(arg0, arg1 any, arg2 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFileSystemFromBackup", reflect.TypeOf((*MockFSx)(nil).CreateFileSystemFromBackup), varargs...)
+}
+
+// DeleteBackup mocks base method.
+func (m *MockFSx) DeleteBackup(arg0 context.Context, arg1 *fsx.DeleteBackupInput, arg2 ...func(*fsx.Options)) (*fsx.DeleteBackupOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DeleteBackup", varargs...)
+	ret0, _ := ret[0].(*fsx.DeleteBackupOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteBackup indicates an expected call of DeleteBackup.
+func (mr *MockFSxMockRecorder) DeleteBackup(arg0, arg1 any, arg2 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBackup", reflect.TypeOf((*MockFSx)(nil).DeleteBackup), varargs...)
+}
+
 // DeleteFileSystem mocks base method.
 func (m *MockFSx) DeleteFileSystem(arg0 context.Context, arg1 *fsx.DeleteFileSystemInput, arg2 ...func(*fsx.Options)) (*fsx.DeleteFileSystemOutput, error) {
 	m.ctrl.T.Helper()
@@ -81,6 +181,66 @@ func (mr *MockFSxMockRecorder) DeleteFileSystem(arg0, arg1 any, arg2 ...any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFileSystem", reflect.TypeOf((*MockFSx)(nil).DeleteFileSystem), varargs...)
 }
 
+// DescribeBackups mocks base method.
+func (m *MockFSx) DescribeBackups(arg0 context.Context, arg1 *fsx.DescribeBackupsInput, arg2 ...func(*fsx.Options)) (*fsx.DescribeBackupsOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DescribeBackups", varargs...)
+	ret0, _ := ret[0].(*fsx.DescribeBackupsOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeBackups indicates an expected call of DescribeBackups.
+func (mr *MockFSxMockRecorder) DescribeBackups(arg0, arg1 any, arg2 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeBackups", reflect.TypeOf((*MockFSx)(nil).DescribeBackups), varargs...)
+}
+
+// DescribeDataRepositoryAssociations mocks base method.
+func (m *MockFSx) DescribeDataRepositoryAssociations(arg0 context.Context, arg1 *fsx.DescribeDataRepositoryAssociationsInput, arg2 ...func(*fsx.Options)) (*fsx.DescribeDataRepositoryAssociationsOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DescribeDataRepositoryAssociations", varargs...)
+	ret0, _ := ret[0].(*fsx.DescribeDataRepositoryAssociationsOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeDataRepositoryAssociations indicates an expected call of DescribeDataRepositoryAssociations.
+func (mr *MockFSxMockRecorder) DescribeDataRepositoryAssociations(arg0, arg1 any, arg2 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeDataRepositoryAssociations", reflect.TypeOf((*MockFSx)(nil).DescribeDataRepositoryAssociations), varargs...)
+}
+
+// DescribeDataRepositoryTasks mocks base method.
+func (m *MockFSx) DescribeDataRepositoryTasks(arg0 context.Context, arg1 *fsx.DescribeDataRepositoryTasksInput, arg2 ...func(*fsx.Options)) (*fsx.DescribeDataRepositoryTasksOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DescribeDataRepositoryTasks", varargs...)
+	ret0, _ := ret[0].(*fsx.DescribeDataRepositoryTasksOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeDataRepositoryTasks indicates an expected call of DescribeDataRepositoryTasks.
+func (mr *MockFSxMockRecorder) DescribeDataRepositoryTasks(arg0, arg1 any, arg2 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeDataRepositoryTasks", reflect.TypeOf((*MockFSx)(nil).DescribeDataRepositoryTasks), varargs...)
+}
+
 // DescribeFileSystems mocks base method.
 func (m *MockFSx) DescribeFileSystems(arg0 context.Context, arg1 *fsx.DescribeFileSystemsInput, arg2 ...func(*fsx.Options)) (*fsx.DescribeFileSystemsOutput, error) {
 	m.ctrl.T.Helper()
@@ -10,3 +10,16 @@ const (
 	// AgentNotReadyNodeTaintKey contains the key of taints to be removed on driver startup
 	AgentNotReadyNodeTaintKey = "fsx.csi.aws.com/agent-not-ready"
 )
+
+// constants for filesystem tags
+const (
+	// ExportOnDeleteTagKey marks a filesystem whose data is exported to its data repository before it is deleted
+	ExportOnDeleteTagKey = "fsx.csi.aws.com/export-on-delete"
+	// FinalBackupTagKey marks a filesystem that is backed up before it is deleted
+	FinalBackupTagKey = "fsx.csi.aws.com/final-backup"
+	// FinalBackupTagKeyPrefix prefixes the keys of the filesystem tags that are applied to its final backup
+	FinalBackupTagKeyPrefix = "fsx.csi.aws.com/final-backup-tag/"
+	// DeletionProtectionKey is the tag of a filesystem or the annotation of its PersistentVolume that prevents the
+	// filesystem from being deleted
+	DeletionProtectionKey = "fsx.csi.aws.com/deletion-protection"
+)
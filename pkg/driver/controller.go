@@ -18,15 +18,26 @@ package driver
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
+	"path"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/aws-fsx-csi-driver/pkg/cloud"
 	"sigs.k8s.io/aws-fsx-csi-driver/pkg/driver/internal"
@@ -48,14 +59,28 @@ var (
 	controllerCaps = []csi.ControllerServiceCapability_RPC_Type{
 		csi.ControllerServiceCapability_RPC_CREATE_DELETE_VOLUME,
 		csi.ControllerServiceCapability_RPC_EXPAND_VOLUME,
+		csi.ControllerServiceCapability_RPC_CREATE_DELETE_SNAPSHOT,
+		csi.ControllerServiceCapability_RPC_LIST_SNAPSHOTS,
+		csi.ControllerServiceCapability_RPC_CLONE_VOLUME,
+		csi.ControllerServiceCapability_RPC_LIST_VOLUMES,
+		csi.ControllerServiceCapability_RPC_GET_VOLUME,
+		csi.ControllerServiceCapability_RPC_VOLUME_CONDITION,
+		csi.ControllerServiceCapability_RPC_MODIFY_VOLUME,
 	}
 )
 
 const (
 	volumeContextDnsName                      = "dnsname"
 	volumeContextMountName                    = "mountname"
+	volumeContextSubpath                      = "subpath"
+	volumeContextCreateSubpath                = "createSubpath"
+	volumeContextSubpathUid                   = "subpathUid"
+	volumeContextSubpathGid                   = "subpathGid"
+	volumeContextSubpathMode                  = "subpathMode"
 	volumeParamsSubnetId                      = "subnetId"
+	volumeParamsSubnetSelector                = "subnetSelector"
 	volumeParamsSecurityGroupIds              = "securityGroupIds"
+	volumeParamsSecurityGroupSelector         = "securityGroupSelector"
 	volumeParamsAutoImportPolicy              = "autoImportPolicy"
 	volumeParamsS3ImportPath                  = "s3ImportPath"
 	volumeParamsS3ExportPath                  = "s3ExportPath"
@@ -74,13 +99,63 @@ const (
 	volumeParamsEfaEnabled                    = "efaEnabled"
 	volumeParamsMetadataConfigurationMode     = "metadataConfigurationMode"
 	volumeParamsMetadataIops                  = "metadataIops"
+	volumeParamsDeleteCloneBackup             = "deleteCloneBackup"
+	volumeParamsDataRepositoryAssociations    = "dataRepositoryAssociations"
+	volumeParamsExportOnDelete                = "exportOnDelete"
+	volumeParamsFinalBackup                   = "finalBackup"
+	volumeParamsFinalBackupTags               = "finalBackupTags"
+	volumeParamsDeletionProtection            = "deletionProtection"
+	volumeParamsFileSystemId                  = "fileSystemId"
+	volumeParamsBasePath                      = "basePath"
+	volumeParamsDirectoryPerms                = "directoryPerms"
+	volumeParamsUid                           = "uid"
+	volumeParamsGid                           = "gid"
+	volumeParamsProjectQuota                  = "projectQuota"
+)
+
+const (
+	backupLifecycleAvailable = "AVAILABLE"
+	backupLifecycleFailed    = "FAILED"
+	cloneBackupNameSuffix    = "-clone"
+)
+
+const (
+	dataRepositoryTaskTypeExport         = "EXPORT_TO_REPOSITORY"
+	dataRepositoryTaskLifecyclePending   = "PENDING"
+	dataRepositoryTaskLifecycleExecuting = "EXECUTING"
+)
+
+const (
+	fileSystemLifecycleFailed                   = "FAILED"
+	fileSystemLifecycleMisconfigured            = "MISCONFIGURED"
+	fileSystemLifecycleMisconfiguredUnavailable = "MISCONFIGURED_UNAVAILABLE"
+	administrativeActionTypeFileSystemUpdate    = "FILE_SYSTEM_UPDATE"
+	administrativeActionStatusFailed            = "FAILED"
+	administrativeActionStatusPending           = "PENDING"
+	administrativeActionStatusInProgress        = "IN_PROGRESS"
+	// fileSystemUpdateStuckTimeout is how long an update may stay pending or in progress before the volume is
+	// reported as abnormal
+	fileSystemUpdateStuckTimeout = 1 * time.Hour
 )
 
 // controllerService represents the controller service of CSI driver
 type controllerService struct {
-	cloud         cloud.Cloud
+	cloud cloud.Cloud
+	// mounter mounts filesystems to manage the subdirectories of subdirectory volumes
+	mounter Mounter
+	// lustre manages the project quotas of subdirectory volumes
+	lustre        Lustre
 	inFlight      *internal.InFlight
 	driverOptions *DriverOptions
+	// securityGroupLock is held for reading while volumes are created with the cluster security group and for writing
+	// while the cluster security group is garbage collected
+	securityGroupLock sync.RWMutex
+	// projectIdLocks serializes the allocation of project IDs to subdirectory volumes per filesystem, keyed by
+	// filesystem ID
+	projectIdLocks sync.Map
+	// kubeClient and recorder record events on the PersistentVolumes, kubeClient is nil outside of a cluster
+	kubeClient kubernetes.Interface
+	recorder   record.EventRecorder
 	csi.UnimplementedControllerServer
 }
 
@@ -104,10 +179,20 @@ func newControllerService(driverOptions *DriverOptions) controllerService {
 	if err != nil {
 		panic(err)
 	}
+	mounter, err := newNodeMounter()
+	if err != nil {
+		panic(err)
+	}
+
+	kubeClient, recorder := newEventRecorder(cloud.DefaultKubernetesAPIClient)
 	return controllerService{
 		cloud:         cloudSrv,
+		mounter:       mounter,
+		lustre:        newNodeLustre(),
 		inFlight:      internal.NewInFlight(),
 		driverOptions: driverOptions,
+		kubeClient:    kubeClient,
+		recorder:      recorder,
 	}
 }
 func abs(x int64) int64 {
@@ -133,6 +218,63 @@ func (d *controllerService) CreateVolume(ctx context.Context, req *csi.CreateVol
 		return nil, status.Error(codes.InvalidArgument, "Volume capabilities not supported")
 	}
 
+	tunables, err := parseLustreTunables(req.GetParameters())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if _, ok := req.GetParameters()[volumeParamsFileSystemId]; ok {
+		if ok := d.inFlight.Insert(volName); !ok {
+			return nil, status.Errorf(codes.Aborted, "Create volume request for %s is already in progress", volName)
+		}
+		defer d.inFlight.Delete(volName)
+		resp, err := d.createSubdirectoryVolume(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		setLustreTunables(resp.Volume, tunables)
+		return resp, nil
+	}
+
+	var backup *cloud.Backup
+	if snapshot := req.GetVolumeContentSource().GetSnapshot(); snapshot != nil {
+		var err error
+		backup, err = d.cloud.DescribeBackup(ctx, snapshot.GetSnapshotId())
+		if err != nil {
+			if errors.Is(err, cloud.ErrNotFound) {
+				return nil, status.Errorf(codes.NotFound, "Snapshot %s not found", snapshot.GetSnapshotId())
+			}
+			return nil, status.Errorf(codes.Internal, "Could not get snapshot %s: %v", snapshot.GetSnapshotId(), err)
+		}
+		if backup.Lifecycle != backupLifecycleAvailable {
+			return nil, status.Errorf(codes.Unavailable, "Snapshot %s is not ready to use, current lifecycle: %s", snapshot.GetSnapshotId(), backup.Lifecycle)
+		}
+	}
+
+	sourceVolume := req.GetVolumeContentSource().GetVolume()
+	deleteCloneBackup := true
+	if val, ok := req.GetParameters()[volumeParamsDeleteCloneBackup]; ok {
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "deleteCloneBackup must be a bool")
+		}
+		deleteCloneBackup = b
+	}
+
+	var associations []*cloud.DataRepositoryAssociationOptions
+	if val, ok := req.GetParameters()[volumeParamsDataRepositoryAssociations]; ok {
+		var err error
+		associations, err = parseDataRepositoryAssociations(val)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		for _, param := range []string{volumeParamsS3ImportPath, volumeParamsS3ExportPath, volumeParamsAutoImportPolicy} {
+			if _, ok := req.GetParameters()[param]; ok {
+				return nil, status.Errorf(codes.InvalidArgument, "Parameters %q and %q are mutually exclusive", volumeParamsDataRepositoryAssociations, param)
+			}
+		}
+	}
+
 	// check if a request is already in-flight
 	if ok := d.inFlight.Insert(volName); !ok {
 		msg := fmt.Sprintf("Create volume request for %s is already in progress", volName)
@@ -146,6 +288,7 @@ func (d *controllerService) CreateVolume(ctx context.Context, req *csi.CreateVol
 	}
 
 	var fs *cloud.FileSystem
+	var zone string
 	if existingFS != nil {
 		// Filesystem exists, skip creation
 		klog.V(2).InfoS("Found existing filesystem",
@@ -178,17 +321,34 @@ func (d *controllerService) CreateVolume(ctx context.Context, req *csi.CreateVol
 			}
 		}
 		fs = existingFS
+
+		if fs.SubnetId != "" {
+			subnet, err := d.describeSubnet(ctx, fs.SubnetId)
+			if err != nil {
+				return nil, err
+			}
+			zone = subnet.AvailabilityZone
+		}
 	} else {
 		// No existing filesystem, create new one
 
+		// the source of a clone is only needed until the clone exists, so retries succeed after it is deleted
+		if sourceVolume != nil {
+			if _, err := d.cloud.DescribeFileSystem(ctx, sourceVolume.GetVolumeId()); err != nil {
+				if errors.Is(err, cloud.ErrNotFound) {
+					return nil, status.Errorf(codes.NotFound, "Source volume %s not found", sourceVolume.GetVolumeId())
+				}
+				return nil, status.Errorf(codes.Internal, "Could not get source volume %s: %v", sourceVolume.GetVolumeId(), err)
+			}
+		}
+
 		// create a new volume with idempotency
 		// idempotency is handled by `CreateFileSystem`
 		volumeParams := req.GetParameters()
 		subnetId := volumeParams[volumeParamsSubnetId]
-		securityGroupIds := volumeParams[volumeParamsSecurityGroupIds]
 		fsOptions := &cloud.FileSystemOptions{
 			SubnetId:         subnetId,
-			SecurityGroupIds: strings.Split(securityGroupIds, ","),
+			SecurityGroupIds: splitList(volumeParams[volumeParamsSecurityGroupIds]),
 		}
 
 		if val, ok := volumeParams[volumeParamsAutoImportPolicy]; ok {
@@ -279,16 +439,33 @@ func (d *controllerService) CreateVolume(ctx context.Context, req *csi.CreateVol
 			fsOptions.MetadataIops = int32(n)
 		}
 
-		capRange := req.GetCapacityRange()
-		if capRange == nil {
-			fsOptions.CapacityGiB = cloud.DefaultVolumeSize
-		} else {
-			newSizeInt64 := util.RoundUpVolumeSize(capRange.GetRequiredBytes(), fsOptions.DeploymentType, fsOptions.StorageType, fsOptions.PerUnitStorageThroughput)
-			newSizeGiB, err := util.ConvertToInt32(newSizeInt64)
+		// Mutable parameters of a VolumeAttributesClass take precedence over the StorageClass parameters
+		if mutableParameters := req.GetMutableParameters(); len(mutableParameters) > 0 {
+			updateOptions, err := newFileSystemUpdateOptions(mutableParameters)
 			if err != nil {
-				return nil, status.Errorf(codes.OutOfRange, "Request storage capacity %d GiB is too large for integer type", newSizeInt64)
+				return nil, err
+			}
+			if updateOptions.PerUnitStorageThroughput != 0 {
+				fsOptions.PerUnitStorageThroughput = updateOptions.PerUnitStorageThroughput
+			}
+			if updateOptions.DataCompressionType != "" {
+				fsOptions.DataCompressionType = updateOptions.DataCompressionType
+			}
+			if updateOptions.MetadataConfigurationMode != "" {
+				fsOptions.MetadataConfigurationMode = updateOptions.MetadataConfigurationMode
+			}
+			if updateOptions.MetadataIops != 0 {
+				fsOptions.MetadataIops = updateOptions.MetadataIops
+			}
+			if updateOptions.AutomaticBackupRetentionDays != nil {
+				fsOptions.AutomaticBackupRetentionDays = *updateOptions.AutomaticBackupRetentionDays
+			}
+			if updateOptions.DailyAutomaticBackupStartTime != "" {
+				fsOptions.DailyAutomaticBackupStartTime = updateOptions.DailyAutomaticBackupStartTime
+			}
+			if updateOptions.WeeklyMaintenanceStartTime != "" {
+				fsOptions.WeeklyMaintenanceStartTime = updateOptions.WeeklyMaintenanceStartTime
 			}
-			fsOptions.CapacityGiB = newSizeGiB
 		}
 
 		var tagArray []string
@@ -308,11 +485,166 @@ func (d *controllerService) CreateVolume(ctx context.Context, req *csi.CreateVol
 		}
 		fsOptions.ExtraTags = tagArray
 
-		fs, err = d.cloud.CreateFileSystem(ctx, volName, fsOptions)
+		// deletionTags configure DeleteVolume, which only receives the volume ID
+		var deletionTags []string
+		if val, ok := volumeParams[volumeParamsExportOnDelete]; ok {
+			b, err := strconv.ParseBool(val)
+			if err != nil {
+				return nil, status.Error(codes.InvalidArgument, "exportOnDelete must be a bool")
+			}
+			if b {
+				if fsOptions.S3ImportPath == "" && len(associations) == 0 {
+					return nil, status.Errorf(codes.InvalidArgument, "Parameter %q requires %q or %q", volumeParamsExportOnDelete, volumeParamsS3ImportPath, volumeParamsDataRepositoryAssociations)
+				}
+				deletionTags = append(deletionTags, ExportOnDeleteTagKey+"=true")
+			}
+		}
+
+		finalBackup := false
+		if val, ok := volumeParams[volumeParamsFinalBackup]; ok {
+			b, err := strconv.ParseBool(val)
+			if err != nil {
+				return nil, status.Error(codes.InvalidArgument, "finalBackup must be a bool")
+			}
+			finalBackup = b
+		}
+		if val, ok := volumeParams[volumeParamsFinalBackupTags]; ok && len(val) > 0 {
+			if !finalBackup {
+				return nil, status.Errorf(codes.InvalidArgument, "Parameter %q requires %q", volumeParamsFinalBackupTags, volumeParamsFinalBackup)
+			}
+			finalBackupTags := strings.Split(val, ",")
+			if err := validateExtraTags(finalBackupTags); err != nil {
+				return nil, status.Error(codes.InvalidArgument, err.Error())
+			}
+			for _, tag := range finalBackupTags {
+				deletionTags = append(deletionTags, FinalBackupTagKeyPrefix+tag)
+			}
+		}
+		if finalBackup {
+			deletionTags = append(deletionTags, FinalBackupTagKey+"=true")
+		}
+
+		if val, ok := volumeParams[volumeParamsDeletionProtection]; ok {
+			b, err := strconv.ParseBool(val)
+			if err != nil {
+				return nil, status.Error(codes.InvalidArgument, "deletionProtection must be a bool")
+			}
+			if b {
+				deletionTags = append(deletionTags, DeletionProtectionKey+"=true")
+			}
+		}
+
+		if len(deletionTags) > 0 {
+			// copy the tags so that the markers are not added to the clone backup
+			fsOptions.ExtraTags = append(append([]string{}, tagArray...), deletionTags...)
+		}
+
+		var subnet *cloud.Subnet
+
+		if val, ok := volumeParams[volumeParamsSubnetSelector]; ok {
+			if subnetId != "" {
+				return nil, status.Errorf(codes.InvalidArgument, "Parameters %q and %q are mutually exclusive", volumeParamsSubnetId, volumeParamsSubnetSelector)
+			}
+			subnet, err = d.selectSubnet(ctx, val, req.GetAccessibilityRequirements())
+			if err != nil {
+				return nil, err
+			}
+			klog.V(4).InfoS("Selected subnet", "volumeName", volName, "subnetId", subnet.SubnetId, "zone", subnet.AvailabilityZone)
+			fsOptions.SubnetId = subnet.SubnetId
+			zone = subnet.AvailabilityZone
+		} else if subnetId != "" {
+			subnet, err = d.describeSubnet(ctx, subnetId)
+			if err != nil {
+				return nil, err
+			}
+			zone = subnet.AvailabilityZone
+			if !isZoneAccessible(req.GetAccessibilityRequirements(), zone) {
+				return nil, status.Errorf(codes.ResourceExhausted, "Subnet %s in Availability Zone %s does not satisfy the accessibility requirements", subnetId, zone)
+			}
+		}
+
+		if val, ok := volumeParams[volumeParamsSecurityGroupSelector]; ok {
+			if subnet == nil {
+				return nil, status.Errorf(codes.InvalidArgument, "Parameter %q requires %q or %q", volumeParamsSecurityGroupSelector, volumeParamsSubnetId, volumeParamsSubnetSelector)
+			}
+			groupIds, err := d.selectSecurityGroups(ctx, val, subnet.VpcId)
+			if err != nil {
+				return nil, err
+			}
+			fsOptions.SecurityGroupIds = append(fsOptions.SecurityGroupIds, groupIds...)
+		}
+
+		if d.driverOptions.createSecurityGroup {
+			if subnet == nil {
+				return nil, status.Errorf(codes.InvalidArgument, "Parameter %q or %q is required to create the cluster security group", volumeParamsSubnetId, volumeParamsSubnetSelector)
+			}
+			// Keep the security group from being garbage collected before the filesystem uses it
+			d.securityGroupLock.RLock()
+			defer d.securityGroupLock.RUnlock()
+			groupId, err := d.cloud.EnsureClusterSecurityGroup(ctx, d.driverOptions.clusterName, subnet.VpcId, d.driverOptions.nodeSecurityGroupIds)
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "Could not create the cluster security group: %v", err)
+			}
+			fsOptions.SecurityGroupIds = append(fsOptions.SecurityGroupIds, groupId)
+		}
+
+		if sourceVolume != nil {
+			// A clone is restored from an intermediate backup of the source filesystem
+			backup, err = d.createCloneBackup(ctx, volName, sourceVolume.GetVolumeId(), tagArray)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if backup != nil {
+			// A restored filesystem inherits the deployment and storage type of the backup
+			if fsOptions.DeploymentType != "" && fsOptions.DeploymentType != backup.DeploymentType {
+				return nil, status.Errorf(codes.InvalidArgument, "deploymentType %s does not match the deployment type %s of snapshot %s", fsOptions.DeploymentType, backup.DeploymentType, backup.BackupId)
+			}
+			if fsOptions.StorageType != "" && fsOptions.StorageType != backup.StorageType {
+				return nil, status.Errorf(codes.InvalidArgument, "storageType %s does not match the storage type %s of snapshot %s", fsOptions.StorageType, backup.StorageType, backup.BackupId)
+			}
+			fsOptions.DeploymentType = backup.DeploymentType
+			fsOptions.StorageType = backup.StorageType
+			if fsOptions.PerUnitStorageThroughput == 0 {
+				fsOptions.PerUnitStorageThroughput = backup.PerUnitStorageThroughput
+			}
+		}
+
+		// checked after the deployment type of a backup is applied, which restored filesystems and clones inherit
+		if finalBackup && !strings.HasPrefix(fsOptions.DeploymentType, "PERSISTENT") {
+			return nil, status.Errorf(codes.InvalidArgument, "Parameter %q requires a PERSISTENT %q", volumeParamsFinalBackup, volumeParamsDeploymentType)
+		}
+
+		capRange := req.GetCapacityRange()
+		if capRange == nil {
+			fsOptions.CapacityGiB = cloud.DefaultVolumeSize
+			if backup != nil {
+				fsOptions.CapacityGiB = backup.CapacityGiB
+			}
+		} else {
+			newSizeInt64 := util.RoundUpVolumeSize(capRange.GetRequiredBytes(), fsOptions.DeploymentType, fsOptions.StorageType, fsOptions.PerUnitStorageThroughput)
+			newSizeGiB, err := util.ConvertToInt32(newSizeInt64)
+			if err != nil {
+				return nil, status.Errorf(codes.OutOfRange, "Request storage capacity %d GiB is too large for integer type", newSizeInt64)
+			}
+			fsOptions.CapacityGiB = newSizeGiB
+		}
+
+		if backup != nil {
+			if fsOptions.CapacityGiB < backup.CapacityGiB {
+				return nil, status.Errorf(codes.OutOfRange, "Requested storage capacity %d GiB is less than the size %d GiB of snapshot %s", fsOptions.CapacityGiB, backup.CapacityGiB, backup.BackupId)
+			}
+			fs, err = d.cloud.CreateFileSystemFromBackup(ctx, volName, backup.BackupId, fsOptions)
+		} else {
+			fs, err = d.cloud.CreateFileSystem(ctx, volName, fsOptions)
+		}
 		if err != nil {
 			switch err {
 			case cloud.ErrFsExistsDiffSize:
 				return nil, status.Error(codes.AlreadyExists, err.Error())
+			case cloud.ErrNotFound:
+				return nil, status.Errorf(codes.NotFound, "Backup %s not found", backup.BackupId)
 			default:
 				return nil, status.Errorf(codes.Internal, "Could not create volume %q: %v", volName, err)
 			}
@@ -324,7 +656,288 @@ func (d *controllerService) CreateVolume(ctx context.Context, req *csi.CreateVol
 		return nil, status.Errorf(codes.Internal, "Filesystem is not ready: %v", err)
 	}
 
-	return newCreateVolumeResponse(fs), nil
+	if len(associations) > 0 {
+		if err := d.ensureDataRepositoryAssociations(ctx, fs.FileSystemId, associations); err != nil {
+			return nil, err
+		}
+	}
+
+	if sourceVolume != nil && deleteCloneBackup {
+		// The clone no longer depends on the intermediate backup once it is available. Retries of requests that failed
+		// after the clone was created do not know the backup, which is looked up by the name of the clone.
+		d.deleteCloneBackup(ctx, volName, sourceVolume.GetVolumeId(), backup)
+	}
+
+	resp := newCreateVolumeResponse(fs, req.GetVolumeContentSource(), zone)
+	setLustreTunables(resp.Volume, tunables)
+	return resp, nil
+}
+
+// ensureDataRepositoryAssociations creates the data repository associations of a filesystem that do not exist yet and
+// waits for all of them to become available. Associations are identified by their filesystem path, so retries of the
+// same request reuse the associations created by earlier attempts.
+func (d *controllerService) ensureDataRepositoryAssociations(ctx context.Context, fileSystemId string, associations []*cloud.DataRepositoryAssociationOptions) error {
+	existing, err := d.cloud.ListDataRepositoryAssociations(ctx, fileSystemId)
+	if err != nil {
+		return status.Errorf(codes.Internal, "Could not list data repository associations of filesystem %s: %v", fileSystemId, err)
+	}
+	byPath := make(map[string]*cloud.DataRepositoryAssociation, len(existing))
+	for _, association := range existing {
+		byPath[association.FileSystemPath] = association
+	}
+
+	for _, associationOptions := range associations {
+		association, ok := byPath[associationOptions.FileSystemPath]
+		if !ok {
+			association, err = d.cloud.CreateDataRepositoryAssociation(ctx, fileSystemId, associationOptions)
+			if err != nil {
+				return status.Errorf(codes.Internal, "Could not create data repository association for %s: %v", associationOptions.FileSystemPath, err)
+			}
+			klog.V(4).InfoS("Created data repository association", "fileSystemId", fileSystemId, "associationId", association.AssociationId, "fileSystemPath", associationOptions.FileSystemPath)
+		}
+		if err := d.cloud.WaitForDataRepositoryAssociationAvailable(ctx, association.AssociationId); err != nil {
+			return status.Errorf(codes.Internal, "Data repository association %s is not ready: %v", association.AssociationId, err)
+		}
+	}
+	return nil
+}
+
+// dataRepositoryAssociation is an element of the dataRepositoryAssociations parameter
+type dataRepositoryAssociation struct {
+	FileSystemPath              string   `json:"fileSystemPath"`
+	DataRepositoryPath          string   `json:"dataRepositoryPath"`
+	AutoImportPolicy            []string `json:"autoImportPolicy"`
+	AutoExportPolicy            []string `json:"autoExportPolicy"`
+	BatchImportMetaDataOnCreate bool     `json:"batchImportMetaDataOnCreate"`
+	ImportedFileChunkSize       int32    `json:"importedFileChunkSize"`
+}
+
+// parseDataRepositoryAssociations parses the JSON list of data repository associations of a storageclass.
+func parseDataRepositoryAssociations(val string) ([]*cloud.DataRepositoryAssociationOptions, error) {
+	var params []dataRepositoryAssociation
+	decoder := json.NewDecoder(strings.NewReader(val))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&params); err != nil {
+		return nil, fmt.Errorf("%s must be a JSON list of data repository associations: %v", volumeParamsDataRepositoryAssociations, err)
+	}
+
+	associations := make([]*cloud.DataRepositoryAssociationOptions, 0, len(params))
+	fileSystemPaths := make(map[string]bool, len(params))
+	for _, param := range params {
+		if !strings.HasPrefix(param.DataRepositoryPath, "s3://") {
+			return nil, fmt.Errorf("dataRepositoryPath %q must be an S3 path starting with s3://", param.DataRepositoryPath)
+		}
+		if !strings.HasPrefix(param.FileSystemPath, "/") {
+			return nil, fmt.Errorf("fileSystemPath %q must be an absolute path", param.FileSystemPath)
+		}
+		fileSystemPath := path.Clean(param.FileSystemPath)
+		if fileSystemPaths[fileSystemPath] {
+			return nil, fmt.Errorf("fileSystemPath %q is associated more than once", fileSystemPath)
+		}
+		fileSystemPaths[fileSystemPath] = true
+		for _, event := range append(param.AutoImportPolicy, param.AutoExportPolicy...) {
+			switch event {
+			case "NEW", "CHANGED", "DELETED":
+			default:
+				return nil, fmt.Errorf("invalid event %q, must be one of NEW, CHANGED or DELETED", event)
+			}
+		}
+		associations = append(associations, &cloud.DataRepositoryAssociationOptions{
+			FileSystemPath:              fileSystemPath,
+			DataRepositoryPath:          param.DataRepositoryPath,
+			AutoImportEvents:            param.AutoImportPolicy,
+			AutoExportEvents:            param.AutoExportPolicy,
+			BatchImportMetaDataOnCreate: param.BatchImportMetaDataOnCreate,
+			ImportedFileChunkSize:       param.ImportedFileChunkSize,
+		})
+	}
+	return associations, nil
+}
+
+// describeSubnet returns the subnet that a filesystem is created in.
+func (d *controllerService) describeSubnet(ctx context.Context, subnetId string) (*cloud.Subnet, error) {
+	subnet, err := d.cloud.DescribeSubnet(ctx, subnetId)
+	if err != nil {
+		if errors.Is(err, cloud.ErrNotFound) {
+			return nil, status.Errorf(codes.InvalidArgument, "Subnet %s not found", subnetId)
+		}
+		return nil, status.Errorf(codes.Internal, "Could not get subnet %s: %v", subnetId, err)
+	}
+	return subnet, nil
+}
+
+// selectSecurityGroups resolves a securityGroupSelector to the security groups of the VPC that carry its tags.
+func (d *controllerService) selectSecurityGroups(ctx context.Context, selector string, vpcId string) ([]string, error) {
+	tags, err := parseTagSelector(volumeParamsSecurityGroupSelector, selector)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	groupIds, err := d.cloud.FindSecurityGroupsByTags(ctx, vpcId, tags)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not find security groups matching %s %q: %v", volumeParamsSecurityGroupSelector, selector, err)
+	}
+	if len(groupIds) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "No security group in VPC %s matches %s %q", vpcId, volumeParamsSecurityGroupSelector, selector)
+	}
+	return groupIds, nil
+}
+
+// garbageCollectSecurityGroups deletes the cluster security groups that are no longer used by any filesystem. It is
+// skipped while this replica creates volumes with the cluster security group, the volumes that other replicas create
+// are protected by the last used tag of the security group.
+func (d *controllerService) garbageCollectSecurityGroups() {
+	if !d.securityGroupLock.TryLock() {
+		return
+	}
+	defer d.securityGroupLock.Unlock()
+
+	if err := d.cloud.DeleteUnusedClusterSecurityGroups(context.Background(), d.driverOptions.clusterName); err != nil {
+		klog.ErrorS(err, "Could not garbage collect security groups", "clusterName", d.driverOptions.clusterName)
+	}
+}
+
+// selectSubnet resolves a subnetSelector to the subnet that a filesystem is created in. Availability Zones are tried
+// in the order of the preferred topologies followed by the requisite topologies, and within a zone the subnet with the
+// most available IP addresses is chosen.
+func (d *controllerService) selectSubnet(ctx context.Context, selector string, requirements *csi.TopologyRequirement) (*cloud.Subnet, error) {
+	tags, err := parseTagSelector(volumeParamsSubnetSelector, selector)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	subnets, err := d.cloud.FindSubnetsByTags(ctx, tags)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not find subnets matching %s %q: %v", volumeParamsSubnetSelector, selector, err)
+	}
+	if len(subnets) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "No subnet matches %s %q", volumeParamsSubnetSelector, selector)
+	}
+
+	zones, anyZone := accessibleZones(requirements)
+	for _, zone := range zones {
+		if subnet := pickSubnet(subnets, zone); subnet != nil {
+			return subnet, nil
+		}
+	}
+	if anyZone {
+		return pickSubnet(subnets, ""), nil
+	}
+
+	return nil, status.Errorf(codes.ResourceExhausted, "No subnet matching %s %q in Availability Zones %v", volumeParamsSubnetSelector, selector, zones)
+}
+
+// parseTagSelector parses a comma separated list of key=value tags. A key without a value matches any value.
+func parseTagSelector(param string, selector string) (map[string]string, error) {
+	tags := make(map[string]string)
+	for _, term := range strings.Split(selector, ",") {
+		key, value, _ := strings.Cut(term, "=")
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, fmt.Errorf("invalid %s %q: tag keys must not be empty", param, selector)
+		}
+		if _, ok := tags[key]; ok {
+			return nil, fmt.Errorf("invalid %s %q: tag key %q is specified more than once", param, selector, key)
+		}
+		tags[key] = strings.TrimSpace(value)
+	}
+	return tags, nil
+}
+
+// accessibleZones returns the Availability Zones of the preferred and requisite topologies of a request, in the order
+// that they should be tried. anyZone is true if the requisite topologies do not restrict the zone.
+func accessibleZones(requirements *csi.TopologyRequirement) (zones []string, anyZone bool) {
+	anyZone = len(requirements.GetRequisite()) == 0
+	seen := make(map[string]bool)
+	for _, topology := range append(requirements.GetPreferred(), requirements.GetRequisite()...) {
+		zone, ok := topology.GetSegments()[TopologyKey]
+		if !ok {
+			anyZone = true
+			continue
+		}
+		if !seen[zone] {
+			seen[zone] = true
+			zones = append(zones, zone)
+		}
+	}
+	return zones, anyZone
+}
+
+// pickSubnet returns the subnet with the most available IP addresses in the given Availability Zone, or in any zone if
+// zone is empty. Ties are broken by subnet ID so that retries pick the same subnet.
+func pickSubnet(subnets []*cloud.Subnet, zone string) *cloud.Subnet {
+	var picked *cloud.Subnet
+	for _, subnet := range subnets {
+		if zone != "" && subnet.AvailabilityZone != zone {
+			continue
+		}
+		if picked == nil || subnet.AvailableIpAddressCount > picked.AvailableIpAddressCount ||
+			(subnet.AvailableIpAddressCount == picked.AvailableIpAddressCount && subnet.SubnetId < picked.SubnetId) {
+			picked = subnet
+		}
+	}
+	return picked
+}
+
+// isZoneAccessible reports whether a volume in the given Availability Zone satisfies the requisite topologies of a
+// request. Preferred topologies only influence where a volume is created, so they are not checked here.
+func isZoneAccessible(requirements *csi.TopologyRequirement, zone string) bool {
+	requisite := requirements.GetRequisite()
+	if len(requisite) == 0 {
+		return true
+	}
+	for _, topology := range requisite {
+		if requisiteZone, ok := topology.GetSegments()[TopologyKey]; !ok || requisiteZone == zone {
+			return true
+		}
+	}
+	return false
+}
+
+// createCloneBackup takes a backup of the source filesystem of a clone and waits for it to become available. The
+// backup is named after the volume, so retries of the same request reuse the backup.
+func (d *controllerService) createCloneBackup(ctx context.Context, volName string, sourceVolumeId string, extraTags []string) (*cloud.Backup, error) {
+	backupName := volName + cloneBackupNameSuffix
+	backup, err := d.cloud.CreateBackup(ctx, backupName, &cloud.BackupOptions{
+		FileSystemId:    sourceVolumeId,
+		ExtraTags:       extraTags,
+		CloneVolumeName: volName,
+	})
+	if err != nil {
+		switch err {
+		case cloud.ErrNotFound:
+			return nil, status.Errorf(codes.NotFound, "Source volume %s not found", sourceVolumeId)
+		case cloud.ErrBackupExistsDiffFs:
+			return nil, status.Error(codes.AlreadyExists, err.Error())
+		default:
+			return nil, status.Errorf(codes.Internal, "Could not create backup of source volume %s: %v", sourceVolumeId, err)
+		}
+	}
+
+	err = d.cloud.WaitForBackupAvailable(ctx, backup.BackupId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Backup of source volume %s is not ready: %v", sourceVolumeId, err)
+	}
+
+	return backup, nil
+}
+
+// deleteCloneBackup deletes the intermediate backup of the source filesystem of a clone if it still exists. The backup
+// is looked up if it is not known. Failures are logged only, the clone is usable without deleting the backup.
+func (d *controllerService) deleteCloneBackup(ctx context.Context, volName string, sourceVolumeId string, backup *cloud.Backup) {
+	if backup == nil {
+		var err error
+		backup, err = d.cloud.FindCloneBackup(ctx, sourceVolumeId, volName)
+		if err != nil {
+			if !errors.Is(err, cloud.ErrNotFound) {
+				klog.ErrorS(err, "CreateVolume: failed to find intermediate clone backup", "volumeName", volName)
+			}
+			return
+		}
+	}
+	if err := d.cloud.DeleteBackup(ctx, backup.BackupId); err != nil && !errors.Is(err, cloud.ErrNotFound) {
+		klog.ErrorS(err, "CreateVolume: failed to delete intermediate clone backup", "volumeName", volName, "backupId", backup.BackupId)
+	}
 }
 
 func (d *controllerService) DeleteVolume(ctx context.Context, req *csi.DeleteVolumeRequest) (*csi.DeleteVolumeResponse, error) {
@@ -341,16 +954,129 @@ func (d *controllerService) DeleteVolume(ctx context.Context, req *csi.DeleteVol
 	}
 	defer d.inFlight.Delete(volumeID)
 
-	if err := d.cloud.DeleteFileSystem(ctx, volumeID); err != nil {
+	if fileSystemId, subdirectory := parseVolumeId(volumeID); subdirectory != "" {
+		if err := d.deleteSubdirectoryVolume(ctx, fileSystemId, subdirectory); err != nil {
+			return nil, err
+		}
+		return &csi.DeleteVolumeResponse{}, nil
+	}
+
+	fs, err := d.cloud.DescribeFileSystem(ctx, volumeID)
+	if err != nil {
+		if errors.Is(err, cloud.ErrNotFound) {
+			klog.V(4).InfoS("DeleteVolume: volume not found, returning with success")
+			return &csi.DeleteVolumeResponse{}, nil
+		}
+		return nil, status.Errorf(codes.Internal, "Could not get volume ID %q: %v", volumeID, err)
+	}
+
+	protection, err := d.getDeletionProtection(ctx, fs)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not check deletion protection of volume ID %q: %v", volumeID, err)
+	}
+	if protection != "" {
+		d.recordPersistentVolumeEvent(ctx, fs.Tags[cloud.VolumeNameTagKey], corev1.EventTypeWarning, "DeletionProtected", "Filesystem %s is not deleted because of the %s", volumeID, protection)
+		return nil, status.Errorf(codes.FailedPrecondition, "Volume ID %q is protected from deletion by the %s", volumeID, protection)
+	}
+
+	if fs.Tags[ExportOnDeleteTagKey] == "true" {
+		if err := d.exportToRepository(ctx, volumeID); err != nil {
+			if errors.Is(err, cloud.ErrNotFound) {
+				klog.V(4).InfoS("DeleteVolume: volume not found, returning with success")
+				return &csi.DeleteVolumeResponse{}, nil
+			}
+			return nil, status.Errorf(codes.Unavailable, "Could not export volume ID %q to its data repository: %v", volumeID, err)
+		}
+	}
+
+	backupId, err := d.cloud.DeleteFileSystem(ctx, volumeID, newDeleteFileSystemOptions(fs))
+	if err != nil {
 		if err == cloud.ErrNotFound {
 			klog.V(4).InfoS("DeleteVolume: volume not found, returning with success")
 			return &csi.DeleteVolumeResponse{}, nil
 		}
 		return nil, status.Errorf(codes.Internal, "Could not delete volume ID %q: %v", volumeID, err)
 	}
+
+	if backupId != "" {
+		klog.InfoS("DeleteVolume: final backup created", "volumeID", volumeID, "backupID", backupId)
+		d.recordPersistentVolumeEvent(ctx, fs.Tags[cloud.VolumeNameTagKey], corev1.EventTypeNormal, "FinalBackupCreated", "Created final backup %s of filesystem %s", backupId, volumeID)
+	}
 	return &csi.DeleteVolumeResponse{}, nil
 }
 
+// newDeleteFileSystemOptions returns the options to delete the filesystem, which are stored in its tags at creation.
+func newDeleteFileSystemOptions(fs *cloud.FileSystem) *cloud.DeleteFileSystemOptions {
+	deleteOptions := &cloud.DeleteFileSystemOptions{
+		FinalBackup: fs.Tags[FinalBackupTagKey] == "true",
+	}
+	if !deleteOptions.FinalBackup {
+		return deleteOptions
+	}
+
+	for key, value := range fs.Tags {
+		if key == cloud.VolumeNameTagKey {
+			deleteOptions.FinalBackupTags = append(deleteOptions.FinalBackupTags, key+"="+value)
+		} else if strings.HasPrefix(key, FinalBackupTagKeyPrefix) {
+			deleteOptions.FinalBackupTags = append(deleteOptions.FinalBackupTags, strings.TrimPrefix(key, FinalBackupTagKeyPrefix)+"="+value)
+		}
+	}
+	sort.Strings(deleteOptions.FinalBackupTags)
+	return deleteOptions
+}
+
+// getDeletionProtection returns what protects the filesystem from deletion, either its tag or the annotation of its
+// PersistentVolume, or an empty string if the filesystem is not protected.
+func (d *controllerService) getDeletionProtection(ctx context.Context, fs *cloud.FileSystem) (string, error) {
+	if fs.Tags[DeletionProtectionKey] == "true" {
+		return fmt.Sprintf("tag %s=true", DeletionProtectionKey), nil
+	}
+
+	pvName := fs.Tags[cloud.VolumeNameTagKey]
+	if d.kubeClient == nil || pvName == "" {
+		return "", nil
+	}
+	pv, err := d.kubeClient.CoreV1().PersistentVolumes().Get(ctx, pvName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	if pv.Annotations[DeletionProtectionKey] == "true" {
+		return fmt.Sprintf("annotation %s=true of PersistentVolume %s", DeletionProtectionKey, pvName), nil
+	}
+	return "", nil
+}
+
+// exportToRepository exports the data of the filesystem to its data repository and waits for the export to finish.
+// An export task that is already running, e.g. started by a previous DeleteVolume call, is waited on instead of starting a new one.
+func (d *controllerService) exportToRepository(ctx context.Context, fileSystemId string) error {
+	tasks, err := d.cloud.ListDataRepositoryTasks(ctx, fileSystemId)
+	if err != nil {
+		return err
+	}
+
+	var taskId string
+	for _, task := range tasks {
+		if task.Type == dataRepositoryTaskTypeExport && (task.Lifecycle == dataRepositoryTaskLifecyclePending || task.Lifecycle == dataRepositoryTaskLifecycleExecuting) {
+			taskId = task.TaskId
+			break
+		}
+	}
+
+	if taskId == "" {
+		task, err := d.cloud.CreateDataRepositoryTask(ctx, fileSystemId, dataRepositoryTaskTypeExport)
+		if err != nil {
+			return err
+		}
+		taskId = task.TaskId
+	}
+
+	klog.V(4).InfoS("DeleteVolume: waiting for export to data repository", "filesystem", fileSystemId, "task", taskId)
+	return d.cloud.WaitForDataRepositoryTask(ctx, taskId)
+}
+
 func (d *controllerService) ControllerPublishVolume(ctx context.Context, req *csi.ControllerPublishVolumeRequest) (*csi.ControllerPublishVolumeResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "")
 }
@@ -360,7 +1086,129 @@ func (d *controllerService) ControllerUnpublishVolume(ctx context.Context, req *
 }
 
 func (d *controllerService) ControllerModifyVolume(ctx context.Context, req *csi.ControllerModifyVolumeRequest) (*csi.ControllerModifyVolumeResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "")
+	klog.V(4).InfoS("ControllerModifyVolume: called", "args", util.SanitizeRequest(req))
+	volumeID := req.GetVolumeId()
+	if len(volumeID) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Volume ID not provided")
+	}
+
+	if _, subdirectory := parseVolumeId(volumeID); subdirectory != "" {
+		return nil, status.Errorf(codes.InvalidArgument, "Volume %q is a subdirectory of a shared filesystem and cannot be modified", volumeID)
+	}
+
+	updateOptions, err := newFileSystemUpdateOptions(req.GetMutableParameters())
+	if err != nil {
+		return nil, err
+	}
+
+	fs, err := d.cloud.DescribeFileSystem(ctx, volumeID)
+	if err != nil {
+		if err == cloud.ErrNotFound {
+			return nil, status.Errorf(codes.NotFound, "Filesystem not found: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "DescribeFileSystem failed: %v", err)
+	}
+
+	updateOptions = removeUnchangedUpdateOptions(fs, updateOptions)
+	if updateOptions == nil {
+		klog.V(4).InfoS("ControllerModifyVolume: filesystem already matches requested parameters, returning with success", "volumeID", volumeID)
+		return &csi.ControllerModifyVolumeResponse{}, nil
+	}
+
+	err = d.cloud.UpdateFileSystem(ctx, volumeID, updateOptions)
+	if err != nil {
+		if err == cloud.ErrNotFound {
+			return nil, status.Errorf(codes.NotFound, "Filesystem not found: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "update failed: %v", err)
+	}
+
+	err = d.cloud.WaitForFileSystemUpdate(ctx, volumeID, updateOptions)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "filesystem is not updated: %v", err)
+	}
+
+	return &csi.ControllerModifyVolumeResponse{}, nil
+}
+
+// newFileSystemUpdateOptions parses the mutable parameters of a VolumeAttributesClass. Parameters that FSx cannot
+// change on a live filesystem are rejected.
+func newFileSystemUpdateOptions(mutableParameters map[string]string) (*cloud.FileSystemUpdateOptions, error) {
+	updateOptions := &cloud.FileSystemUpdateOptions{}
+	for key, val := range mutableParameters {
+		switch key {
+		case volumeParamsPerUnitStorageThroughput:
+			n, err := strconv.ParseInt(val, 10, 32)
+			if err != nil {
+				return nil, status.Error(codes.InvalidArgument, "perUnitStorageThroughput must be a number")
+			}
+			updateOptions.PerUnitStorageThroughput = int32(n)
+		case volumeParamsDataCompressionType:
+			updateOptions.DataCompressionType = val
+		case volumeParamsMetadataConfigurationMode:
+			updateOptions.MetadataConfigurationMode = val
+		case volumeParamsMetadataIops:
+			n, err := strconv.ParseInt(val, 10, 32)
+			if err != nil {
+				return nil, status.Error(codes.InvalidArgument, "metadataIops must be a number")
+			}
+			updateOptions.MetadataIops = int32(n)
+		case volumeParamsAutomaticBackupRetentionDays:
+			n, err := strconv.ParseInt(val, 10, 32)
+			if err != nil {
+				return nil, status.Error(codes.InvalidArgument, "automaticBackupRetentionDays must be a number")
+			}
+			retentionDays := int32(n)
+			updateOptions.AutomaticBackupRetentionDays = &retentionDays
+		case volumeParamsDailyAutomaticBackupStartTime:
+			updateOptions.DailyAutomaticBackupStartTime = val
+		case volumeParamsWeeklyMaintenanceStartTime:
+			updateOptions.WeeklyMaintenanceStartTime = val
+		case volumeParamsCopyTagsToBackups:
+			// UpdateFileSystemLustreConfiguration has no CopyTagsToBackups field
+			return nil, status.Errorf(codes.InvalidArgument, "Parameter %q can only be set in the StorageClass, FSx does not support changing it on an existing filesystem", key)
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "Parameter %q cannot be modified", key)
+		}
+	}
+	return updateOptions, nil
+}
+
+// removeUnchangedUpdateOptions clears the update options that the filesystem already has, and returns nil when there
+// is nothing left to update.
+func removeUnchangedUpdateOptions(fs *cloud.FileSystem, updateOptions *cloud.FileSystemUpdateOptions) *cloud.FileSystemUpdateOptions {
+	pending := *updateOptions
+	if pending.PerUnitStorageThroughput == fs.PerUnitStorageThroughput {
+		pending.PerUnitStorageThroughput = 0
+	}
+
+	if pending.DataCompressionType == fs.DataCompressionType {
+		pending.DataCompressionType = ""
+	}
+
+	// The metadata mode and IOPS are updated together, so only drop them when neither changes
+	if (pending.MetadataConfigurationMode == "" || pending.MetadataConfigurationMode == fs.MetadataConfigurationMode) &&
+		(pending.MetadataIops == 0 || pending.MetadataIops == fs.MetadataIops) {
+		pending.MetadataConfigurationMode = ""
+		pending.MetadataIops = 0
+	}
+
+	if pending.AutomaticBackupRetentionDays != nil && *pending.AutomaticBackupRetentionDays == fs.AutomaticBackupRetentionDays {
+		pending.AutomaticBackupRetentionDays = nil
+	}
+
+	if pending.DailyAutomaticBackupStartTime == fs.DailyAutomaticBackupStartTime {
+		pending.DailyAutomaticBackupStartTime = ""
+	}
+
+	if pending.WeeklyMaintenanceStartTime == fs.WeeklyMaintenanceStartTime {
+		pending.WeeklyMaintenanceStartTime = ""
+	}
+
+	if pending == (cloud.FileSystemUpdateOptions{}) {
+		return nil
+	}
+	return &pending
 }
 
 func (d *controllerService) ControllerGetCapabilities(ctx context.Context, req *csi.ControllerGetCapabilitiesRequest) (*csi.ControllerGetCapabilitiesResponse, error) {
@@ -386,7 +1234,53 @@ func (d *controllerService) GetCapacity(ctx context.Context, req *csi.GetCapacit
 
 func (d *controllerService) ListVolumes(ctx context.Context, req *csi.ListVolumesRequest) (*csi.ListVolumesResponse, error) {
 	klog.V(4).InfoS("ListVolumes: called", "args", util.SanitizeRequest(req))
-	return nil, status.Error(codes.Unimplemented, "")
+	if req.GetMaxEntries() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid max entries %d", req.GetMaxEntries())
+	}
+
+	fileSystems, err := d.cloud.ListFileSystems(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not list volumes: %v", err)
+	}
+
+	// The starting token is the ID of the last volume of the previous page. The volumes are ordered by ID, so volumes
+	// that are created or deleted between the requests do not shift the following pages.
+	start := 0
+	if token := req.GetStartingToken(); token != "" {
+		if !strings.HasPrefix(token, "fs-") {
+			return nil, status.Errorf(codes.Aborted, "Invalid starting token %q", token)
+		}
+		start = sort.Search(len(fileSystems), func(i int) bool {
+			return fileSystems[i].FileSystemId > token
+		})
+	}
+
+	end := len(fileSystems)
+	if maxEntries := int(req.GetMaxEntries()); maxEntries > 0 && start+maxEntries < end {
+		end = start + maxEntries
+	}
+
+	var nextToken string
+	if end < len(fileSystems) {
+		nextToken = fileSystems[end-1].FileSystemId
+	}
+
+	now := time.Now()
+	entries := make([]*csi.ListVolumesResponse_Entry, 0, end-start)
+	for _, fs := range fileSystems[start:end] {
+		entries = append(entries, &csi.ListVolumesResponse_Entry{
+			Volume: newCSIVolume(fs, nil),
+			// the status must be set when the VOLUME_CONDITION capability is advertised
+			Status: &csi.ListVolumesResponse_VolumeStatus{
+				VolumeCondition: newVolumeCondition(fs, now),
+			},
+		})
+	}
+
+	return &csi.ListVolumesResponse{
+		Entries:   entries,
+		NextToken: nextToken,
+	}, nil
 }
 
 func (d *controllerService) ValidateVolumeCapabilities(ctx context.Context, req *csi.ValidateVolumeCapabilitiesRequest) (*csi.ValidateVolumeCapabilitiesResponse, error) {
@@ -401,7 +1295,8 @@ func (d *controllerService) ValidateVolumeCapabilities(ctx context.Context, req
 		return nil, status.Error(codes.InvalidArgument, "Volume capabilities not provided")
 	}
 
-	if _, err := d.cloud.DescribeFileSystem(ctx, volumeID); err != nil {
+	fileSystemId, _ := parseVolumeId(volumeID)
+	if _, err := d.cloud.DescribeFileSystem(ctx, fileSystemId); err != nil {
 		if err == cloud.ErrNotFound {
 			return nil, status.Error(codes.NotFound, "Volume not found")
 		}
@@ -445,15 +1340,154 @@ func isValidVolumeCapabilities(volCaps []*csi.VolumeCapability) bool {
 }
 
 func (d *controllerService) CreateSnapshot(ctx context.Context, req *csi.CreateSnapshotRequest) (*csi.CreateSnapshotResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "")
+	klog.V(4).InfoS("CreateSnapshot: called", "args", util.SanitizeRequest(req))
+	snapshotName := req.GetName()
+	if len(snapshotName) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Snapshot name not provided")
+	}
+
+	sourceVolumeId := req.GetSourceVolumeId()
+	if len(sourceVolumeId) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Snapshot source volume ID not provided")
+	}
+
+	if _, subdirectory := parseVolumeId(sourceVolumeId); subdirectory != "" {
+		return nil, status.Errorf(codes.InvalidArgument, "Volume %q is a subdirectory of a shared filesystem and cannot be snapshotted", sourceVolumeId)
+	}
+
+	// check if a request is already in-flight
+	if ok := d.inFlight.Insert(snapshotName); !ok {
+		msg := fmt.Sprintf("Create snapshot request for %s is already in progress", snapshotName)
+		return nil, status.Error(codes.Aborted, msg)
+	}
+	defer d.inFlight.Delete(snapshotName)
+
+	backupOptions := &cloud.BackupOptions{
+		FileSystemId: sourceVolumeId,
+	}
+
+	var tagArray []string
+	optionsTags := d.driverOptions.extraTags
+
+	if optionsTags != "" {
+		tagArray = strings.Split(optionsTags, ",")
+	}
+
+	if val, ok := req.GetParameters()[volumeParamsExtraTags]; ok && len(val) > 0 {
+		extraTags := strings.Split(val, ",")
+		err := validateExtraTags(extraTags)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		tagArray = append(tagArray, extraTags...)
+	}
+	backupOptions.ExtraTags = tagArray
+
+	// create a new backup with idempotency
+	// idempotency is handled by `CreateBackup`
+	backup, err := d.cloud.CreateBackup(ctx, snapshotName, backupOptions)
+	if err != nil {
+		switch err {
+		case cloud.ErrNotFound:
+			return nil, status.Errorf(codes.NotFound, "Source volume %q not found", sourceVolumeId)
+		case cloud.ErrBackupExistsDiffFs:
+			return nil, status.Error(codes.AlreadyExists, err.Error())
+		default:
+			return nil, status.Errorf(codes.Internal, "Could not create snapshot %q: %v", snapshotName, err)
+		}
+	}
+
+	if backup.Lifecycle == backupLifecycleFailed {
+		return nil, status.Errorf(codes.Internal, "Snapshot %q failed: %s", snapshotName, backup.FailureMessage)
+	}
+
+	return &csi.CreateSnapshotResponse{
+		Snapshot: newCSISnapshot(backup),
+	}, nil
 }
 
 func (d *controllerService) DeleteSnapshot(ctx context.Context, req *csi.DeleteSnapshotRequest) (*csi.DeleteSnapshotResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "")
+	klog.V(4).InfoS("DeleteSnapshot: called", "args", util.SanitizeRequest(req))
+	snapshotId := req.GetSnapshotId()
+	if len(snapshotId) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Snapshot ID not provided")
+	}
+
+	// check if a request is already in-flight
+	if ok := d.inFlight.Insert(snapshotId); !ok {
+		msg := fmt.Sprintf("Delete snapshot request for %s is already in progress", snapshotId)
+		return nil, status.Error(codes.Aborted, msg)
+	}
+	defer d.inFlight.Delete(snapshotId)
+
+	if err := d.cloud.DeleteBackup(ctx, snapshotId); err != nil {
+		if err == cloud.ErrNotFound {
+			klog.V(4).InfoS("DeleteSnapshot: snapshot not found, returning with success")
+			return &csi.DeleteSnapshotResponse{}, nil
+		}
+		return nil, status.Errorf(codes.Internal, "Could not delete snapshot ID %q: %v", snapshotId, err)
+	}
+	return &csi.DeleteSnapshotResponse{}, nil
 }
 
 func (d *controllerService) ListSnapshots(ctx context.Context, req *csi.ListSnapshotsRequest) (*csi.ListSnapshotsResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "")
+	klog.V(4).InfoS("ListSnapshots: called", "args", util.SanitizeRequest(req))
+	snapshotId := req.GetSnapshotId()
+	sourceVolumeId := req.GetSourceVolumeId()
+
+	if len(snapshotId) != 0 {
+		backup, err := d.cloud.DescribeBackup(ctx, snapshotId)
+		if err != nil {
+			if err == cloud.ErrNotFound {
+				klog.V(4).InfoS("ListSnapshots: snapshot not found, returning with success")
+				return &csi.ListSnapshotsResponse{}, nil
+			}
+			return nil, status.Errorf(codes.Internal, "Could not get snapshot ID %q: %v", snapshotId, err)
+		}
+		if len(sourceVolumeId) != 0 && backup.FileSystemId != sourceVolumeId {
+			return &csi.ListSnapshotsResponse{}, nil
+		}
+		if isCloneBackup(backup) {
+			return &csi.ListSnapshotsResponse{}, nil
+		}
+		return &csi.ListSnapshotsResponse{
+			Entries: []*csi.ListSnapshotsResponse_Entry{
+				{
+					Snapshot: newCSISnapshot(backup),
+				},
+			},
+		}, nil
+	}
+
+	if req.GetMaxEntries() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "Invalid max entries %d", req.GetMaxEntries())
+	}
+
+	backups, nextToken, err := d.cloud.ListBackups(ctx, sourceVolumeId, req.GetMaxEntries(), req.GetStartingToken())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not list snapshots: %v", err)
+	}
+
+	var entries []*csi.ListSnapshotsResponse_Entry
+	for _, backup := range backups {
+		if isCloneBackup(backup) {
+			continue
+		}
+		entries = append(entries, &csi.ListSnapshotsResponse_Entry{
+			Snapshot: newCSISnapshot(backup),
+		})
+	}
+
+	return &csi.ListSnapshotsResponse{
+		Entries:   entries,
+		NextToken: nextToken,
+	}, nil
+}
+
+// isCloneBackup checks if the backup is the intermediate backup of a clone, which is not a snapshot
+func isCloneBackup(backup *cloud.Backup) bool {
+	_, ok := backup.Tags[cloud.CloneVolumeNameTagKey]
+	return ok
 }
 
 func (d *controllerService) ControllerExpandVolume(ctx context.Context, req *csi.ControllerExpandVolumeRequest) (*csi.ControllerExpandVolumeResponse, error) {
@@ -468,6 +1502,23 @@ func (d *controllerService) ControllerExpandVolume(ctx context.Context, req *csi
 		return nil, status.Error(codes.InvalidArgument, "Capacity range not provided")
 	}
 
+	if fileSystemId, subdirectory := parseVolumeId(volumeID); subdirectory != "" {
+		// the subdirectory must not be deleted while its quota is changed
+		if ok := d.inFlight.Insert(volumeID); !ok {
+			msg := fmt.Sprintf(internal.VolumeOperationAlreadyExistsErrorMsg, volumeID)
+			return nil, status.Error(codes.Aborted, msg)
+		}
+		defer d.inFlight.Delete(volumeID)
+
+		if err := d.expandSubdirectoryVolume(ctx, fileSystemId, subdirectory, capRange.GetRequiredBytes()); err != nil {
+			return nil, err
+		}
+		return &csi.ControllerExpandVolumeResponse{
+			CapacityBytes:         capRange.GetRequiredBytes(),
+			NodeExpansionRequired: false,
+		}, nil
+	}
+
 	fs, err := d.cloud.DescribeFileSystem(ctx, volumeID)
 	if err != nil {
 		if err == cloud.ErrNotFound {
@@ -513,20 +1564,138 @@ func (d *controllerService) ControllerExpandVolume(ctx context.Context, req *csi
 }
 
 func (d *controllerService) ControllerGetVolume(ctx context.Context, req *csi.ControllerGetVolumeRequest) (*csi.ControllerGetVolumeResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "")
+	klog.V(4).InfoS("ControllerGetVolume: called", "args", util.SanitizeRequest(req))
+	volumeID := req.GetVolumeId()
+	if len(volumeID) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Volume ID not provided")
+	}
+
+	fileSystemId, subdirectory := parseVolumeId(volumeID)
+	fs, err := d.cloud.DescribeFileSystem(ctx, fileSystemId)
+	if err != nil {
+		if err == cloud.ErrNotFound {
+			return nil, status.Errorf(codes.NotFound, "Volume %q not found", volumeID)
+		}
+		return nil, status.Errorf(codes.Internal, "Could not get volume with ID %q: %v", volumeID, err)
+	}
+
+	volume := newCSIVolume(fs, nil)
+	if subdirectory != "" {
+		volume.VolumeId = volumeID
+		volume.CapacityBytes = 0
+	}
+
+	return &csi.ControllerGetVolumeResponse{
+		Volume: volume,
+		Status: &csi.ControllerGetVolumeResponse_VolumeStatus{
+			VolumeCondition: newVolumeCondition(fs, time.Now()),
+		},
+	}, nil
 }
 
-func newCreateVolumeResponse(fs *cloud.FileSystem) *csi.CreateVolumeResponse {
-	return &csi.CreateVolumeResponse{
-		Volume: &csi.Volume{
-			VolumeId:      fs.FileSystemId,
-			CapacityBytes: util.GiBToBytes(fs.CapacityGiB),
-			VolumeContext: map[string]string{
-				volumeContextDnsName:   fs.DnsName,
-				volumeContextMountName: fs.MountName,
+// newVolumeCondition reports a filesystem as abnormal when FSx cannot serve it, or when an update of the filesystem
+// has failed or has not progressed for longer than fileSystemUpdateStuckTimeout.
+func newVolumeCondition(fs *cloud.FileSystem, now time.Time) *csi.VolumeCondition {
+	switch fs.Lifecycle {
+	case fileSystemLifecycleMisconfigured, fileSystemLifecycleMisconfiguredUnavailable, fileSystemLifecycleFailed:
+		return &csi.VolumeCondition{
+			Abnormal: true,
+			Message:  fmt.Sprintf("Filesystem %s is %s: %s", fs.FileSystemId, fs.Lifecycle, fs.FailureMessage),
+		}
+	}
+
+	// a failed update usually returns the filesystem to AVAILABLE, so the latest update is checked in every lifecycle
+	if update := latestFileSystemUpdate(fs.AdministrativeActions); update != nil {
+		switch update.Status {
+		case administrativeActionStatusFailed:
+			return &csi.VolumeCondition{
+				Abnormal: true,
+				Message:  fmt.Sprintf("Update of filesystem %s failed: %s", fs.FileSystemId, update.FailureMessage),
+			}
+		case administrativeActionStatusPending, administrativeActionStatusInProgress:
+			if now.Sub(update.RequestTime) > fileSystemUpdateStuckTimeout {
+				return &csi.VolumeCondition{
+					Abnormal: true,
+					Message:  fmt.Sprintf("Update of filesystem %s has been %s since %s", fs.FileSystemId, update.Status, update.RequestTime.Format(time.RFC3339)),
+				}
+			}
+		}
+	}
+
+	return &csi.VolumeCondition{
+		Abnormal: false,
+		Message:  fmt.Sprintf("Filesystem %s is %s", fs.FileSystemId, fs.Lifecycle),
+	}
+}
+
+// latestFileSystemUpdate returns the FILE_SYSTEM_UPDATE administrative action with the latest request time, or nil if
+// there is none
+func latestFileSystemUpdate(actions []cloud.AdministrativeAction) *cloud.AdministrativeAction {
+	var latest *cloud.AdministrativeAction
+	for i := range actions {
+		if actions[i].ActionType != administrativeActionTypeFileSystemUpdate {
+			continue
+		}
+		if latest == nil || actions[i].RequestTime.After(latest.RequestTime) {
+			latest = &actions[i]
+		}
+	}
+	return latest
+}
+
+func newCreateVolumeResponse(fs *cloud.FileSystem, contentSource *csi.VolumeContentSource, zone string) *csi.CreateVolumeResponse {
+	volume := newCSIVolume(fs, contentSource)
+	if zone != "" {
+		volume.AccessibleTopology = []*csi.Topology{
+			{
+				Segments: map[string]string{TopologyKey: zone},
 			},
+		}
+	}
+	return &csi.CreateVolumeResponse{
+		Volume: volume,
+	}
+}
+
+func newCSIVolume(fs *cloud.FileSystem, contentSource *csi.VolumeContentSource) *csi.Volume {
+	return &csi.Volume{
+		VolumeId:      fs.FileSystemId,
+		CapacityBytes: util.GiBToBytes(fs.CapacityGiB),
+		VolumeContext: map[string]string{
+			volumeContextDnsName:   fs.DnsName,
+			volumeContextMountName: fs.MountName,
 		},
+		ContentSource: contentSource,
+	}
+}
+
+// setLustreTunables adds the Lustre client tunables of a volume to its context, so that nodes apply them when they
+// mount the volume.
+func setLustreTunables(volume *csi.Volume, tunables map[string]string) {
+	for tunable, value := range tunables {
+		volume.VolumeContext[lustreTunablePrefix+tunable] = value
+	}
+}
+
+func newCSISnapshot(backup *cloud.Backup) *csi.Snapshot {
+	return &csi.Snapshot{
+		SnapshotId:     backup.BackupId,
+		SourceVolumeId: backup.FileSystemId,
+		SizeBytes:      util.GiBToBytes(backup.CapacityGiB),
+		CreationTime:   timestamppb.New(backup.CreationTime),
+		ReadyToUse:     backup.Lifecycle == backupLifecycleAvailable,
+	}
+}
+
+// splitList splits a comma separated list, dropping empty items
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
 }
 
 func validateExtraTags(tags []string) error {
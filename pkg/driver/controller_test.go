@@ -20,7 +20,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"sigs.k8s.io/aws-fsx-csi-driver/pkg/driver/internal"
 
@@ -32,6 +38,10 @@ import (
 	"go.uber.org/mock/gomock"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/aws-fsx-csi-driver/pkg/cloud"
 	"sigs.k8s.io/aws-fsx-csi-driver/pkg/driver/mocks"
 	"sigs.k8s.io/aws-fsx-csi-driver/pkg/util"
@@ -40,15 +50,22 @@ import (
 func TestCreateVolume(t *testing.T) {
 
 	var (
-		volumeName             = "volumeName"
-		fileSystemId           = "fs-1234"
-		volumeSizeGiB    int32 = 1200
-		p2VolumeSizeGiB  int32 = 4800
-		subnetId               = "subnet-056da83524edbe641"
-		securityGroupIds       = "sg-086f61ea73388fb6b,sg-0145e55e976000c9e"
-		dnsName                = "test.fsx.us-west-2.amazoawd.com"
-		mountName              = "random"
-		stdVolCap              = &csi.VolumeCapability{
+		volumeName                       = "volumeName"
+		fileSystemId                     = "fs-1234"
+		volumeSizeGiB              int32 = 1200
+		p2VolumeSizeGiB            int32 = 4800
+		subnetId                         = "subnet-056da83524edbe641"
+		availabilityZone                 = "us-west-2a"
+		vpcId                            = "vpc-0a1b2c3d4e5f67890"
+		clusterName                      = "test-cluster"
+		dataRepositoryAssociations       = `[
+			{"fileSystemPath": "/training", "dataRepositoryPath": "s3://bucket/training", "autoImportPolicy": ["NEW", "CHANGED", "DELETED"], "batchImportMetaDataOnCreate": true},
+			{"fileSystemPath": "/results/", "dataRepositoryPath": "s3://bucket/results", "autoExportPolicy": ["NEW", "CHANGED"]}
+		]`
+		securityGroupIds = "sg-086f61ea73388fb6b,sg-0145e55e976000c9e"
+		dnsName          = "test.fsx.us-west-2.amazoawd.com"
+		mountName        = "random"
+		stdVolCap        = &csi.VolumeCapability{
 			AccessType: &csi.VolumeCapability_Mount{
 				Mount: &csi.VolumeCapability_MountVolume{},
 			},
@@ -56,9 +73,25 @@ func TestCreateVolume(t *testing.T) {
 				Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
 			},
 		}
-		extraTags        = "key1=value1,key2=value2"
-		emptyExtraTags   = ""
-		invalidExtraTags = "key1=value1,value2"
+		extraTags          = "key1=value1,key2=value2"
+		emptyExtraTags     = ""
+		invalidExtraTags   = "key1=value1,value2"
+		backupId           = "backup-1234"
+		sourceFileSystemId = "fs-5678"
+		volumeSource       = &csi.VolumeContentSource{
+			Type: &csi.VolumeContentSource_Volume{
+				Volume: &csi.VolumeContentSource_VolumeSource{
+					VolumeId: sourceFileSystemId,
+				},
+			},
+		}
+		snapshotSource = &csi.VolumeContentSource{
+			Type: &csi.VolumeContentSource_Snapshot{
+				Snapshot: &csi.VolumeContentSource_SnapshotSource{
+					SnapshotId: backupId,
+				},
+			},
+		}
 	)
 	testCases := []struct {
 		name     string
@@ -95,6 +128,7 @@ func TestCreateVolume(t *testing.T) {
 					MountName:    mountName,
 				}
 				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().DescribeSubnet(gomock.Eq(ctx), gomock.Eq(subnetId)).Return(&cloud.Subnet{SubnetId: subnetId, AvailabilityZone: availabilityZone}, nil)
 				mockCloud.EXPECT().CreateFileSystem(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Any()).Return(fs, nil)
 				mockCloud.EXPECT().WaitForFileSystemAvailable(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil)
 
@@ -136,6 +170,102 @@ func TestCreateVolume(t *testing.T) {
 				mockCtl.Finish()
 			},
 		},
+		{
+			name: "success: mutable parameters override storageclass parameters",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:                 subnetId,
+						volumeParamsSecurityGroupIds:         securityGroupIds,
+						volumeParamsDeploymentType:           "PERSISTENT_2",
+						volumeParamsPerUnitStorageThroughput: "125",
+						volumeParamsDataCompressionType:      "NONE",
+					},
+					MutableParameters: map[string]string{
+						volumeParamsPerUnitStorageThroughput: "250",
+						volumeParamsDataCompressionType:      "LZ4",
+					},
+				}
+
+				ctx := context.Background()
+				fs := &cloud.FileSystem{
+					FileSystemId: fileSystemId,
+					CapacityGiB:  volumeSizeGiB,
+					DnsName:      dnsName,
+					MountName:    mountName,
+				}
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().DescribeSubnet(gomock.Eq(ctx), gomock.Eq(subnetId)).Return(&cloud.Subnet{SubnetId: subnetId, AvailabilityZone: availabilityZone}, nil)
+				mockCloud.EXPECT().CreateFileSystem(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, volumeName string, fileSystemOptions *cloud.FileSystemOptions) (*cloud.FileSystem, error) {
+						if fileSystemOptions.PerUnitStorageThroughput != 250 {
+							t.Fatalf("PerUnitStorageThroughput mismatches. actual: %v expected: %v", fileSystemOptions.PerUnitStorageThroughput, 250)
+						}
+						if fileSystemOptions.DataCompressionType != "LZ4" {
+							t.Fatalf("DataCompressionType mismatches. actual: %v expected: %v", fileSystemOptions.DataCompressionType, "LZ4")
+						}
+						return fs, nil
+					})
+				mockCloud.EXPECT().WaitForFileSystemAvailable(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: immutable mutable parameter",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:         subnetId,
+						volumeParamsSecurityGroupIds: securityGroupIds,
+					},
+					MutableParameters: map[string]string{
+						volumeParamsStorageType: "HDD",
+					},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
 		{
 			name: "success: normal with deploymentType SCRATCH_2",
 			testFunc: func(t *testing.T) {
@@ -172,6 +302,7 @@ func TestCreateVolume(t *testing.T) {
 					MountName:    mountName,
 				}
 				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().DescribeSubnet(gomock.Eq(ctx), gomock.Eq(subnetId)).Return(&cloud.Subnet{SubnetId: subnetId, AvailabilityZone: availabilityZone}, nil)
 				mockCloud.EXPECT().CreateFileSystem(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Any()).Return(fs, nil)
 				mockCloud.EXPECT().WaitForFileSystemAvailable(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil)
 
@@ -254,6 +385,7 @@ func TestCreateVolume(t *testing.T) {
 					MountName:    mountName,
 				}
 				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().DescribeSubnet(gomock.Eq(ctx), gomock.Eq(subnetId)).Return(&cloud.Subnet{SubnetId: subnetId, AvailabilityZone: availabilityZone}, nil)
 				mockCloud.EXPECT().CreateFileSystem(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Any()).Return(fs, nil)
 				mockCloud.EXPECT().WaitForFileSystemAvailable(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil)
 
@@ -339,6 +471,7 @@ func TestCreateVolume(t *testing.T) {
 					MountName:    mountName,
 				}
 				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().DescribeSubnet(gomock.Eq(ctx), gomock.Eq(subnetId)).Return(&cloud.Subnet{SubnetId: subnetId, AvailabilityZone: availabilityZone}, nil)
 				mockCloud.EXPECT().CreateFileSystem(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Any()).Return(fs, nil)
 				mockCloud.EXPECT().WaitForFileSystemAvailable(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil)
 
@@ -412,6 +545,7 @@ func TestCreateVolume(t *testing.T) {
 					MountName:    mountName,
 				}
 				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().DescribeSubnet(gomock.Eq(ctx), gomock.Eq(subnetId)).Return(&cloud.Subnet{SubnetId: subnetId, AvailabilityZone: availabilityZone}, nil)
 				mockCloud.EXPECT().CreateFileSystem(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Any()).Return(fs, nil)
 				mockCloud.EXPECT().WaitForFileSystemAvailable(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil)
 
@@ -487,6 +621,39 @@ func TestCreateVolume(t *testing.T) {
 				mockCtl.Finish()
 			},
 		},
+		{
+			name: "fail: unsupported Lustre tunable",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:                         subnetId,
+						volumeParamsSecurityGroupIds:                 securityGroupIds,
+						lustreTunablePrefix + "llite.checksum_pages": "0",
+					},
+				}
+
+				ctx := context.Background()
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
 		{
 			name: "fail: volume name missing",
 			testFunc: func(t *testing.T) {
@@ -604,6 +771,7 @@ func TestCreateVolume(t *testing.T) {
 
 				ctx := context.Background()
 				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().DescribeSubnet(gomock.Eq(ctx), gomock.Eq(subnetId)).Return(&cloud.Subnet{SubnetId: subnetId, AvailabilityZone: availabilityZone}, nil)
 				mockCloud.EXPECT().CreateFileSystem(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Any()).Return(nil, cloud.ErrFsExistsDiffSize)
 
 				_, err := driver.CreateVolume(ctx, req)
@@ -614,23 +782,8 @@ func TestCreateVolume(t *testing.T) {
 				mockCtl.Finish()
 			},
 		},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.name, tc.testFunc)
-	}
-}
-
-func TestDeleteVolume(t *testing.T) {
-	var (
-		fileSystemId = "fs-1234"
-	)
-	testCases := []struct {
-		name     string
-		testFunc func(t *testing.T)
-	}{
 		{
-			name: "success: normal",
+			name: "success: restore from snapshot",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -641,46 +794,83 @@ func TestDeleteVolume(t *testing.T) {
 					driverOptions: &DriverOptions{},
 				}
 
-				req := &csi.DeleteVolumeRequest{
-					VolumeId: fileSystemId,
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: util.GiBToBytes(2 * volumeSizeGiB),
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:                 subnetId,
+						volumeParamsSecurityGroupIds:         securityGroupIds,
+						volumeParamsPerUnitStorageThroughput: "500",
+						volumeParamsKmsKeyId:                 "kmsKeyId",
+					},
+					VolumeContentSource: snapshotSource,
 				}
 
 				ctx := context.Background()
+				backup := &cloud.Backup{
+					BackupId:                 backupId,
+					FileSystemId:             "fs-5678",
+					CapacityGiB:              volumeSizeGiB,
+					StorageType:              "SSD",
+					DeploymentType:           "PERSISTENT_1",
+					PerUnitStorageThroughput: 200,
+					Lifecycle:                backupLifecycleAvailable,
+				}
+				mockCloud.EXPECT().DescribeBackup(gomock.Eq(ctx), gomock.Eq(backupId)).Return(backup, nil)
+				fs := &cloud.FileSystem{
+					FileSystemId:   fileSystemId,
+					CapacityGiB:    2 * volumeSizeGiB,
+					DnsName:        dnsName,
+					MountName:      mountName,
+					StorageType:    "SSD",
+					DeploymentType: "PERSISTENT_1",
+				}
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().DescribeSubnet(gomock.Eq(ctx), gomock.Eq(subnetId)).Return(&cloud.Subnet{SubnetId: subnetId, AvailabilityZone: availabilityZone}, nil)
+				mockCloud.EXPECT().CreateFileSystemFromBackup(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Eq(backupId), gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ string, _ string, fsOptions *cloud.FileSystemOptions) (*cloud.FileSystem, error) {
+						if fsOptions.DeploymentType != backup.DeploymentType {
+							t.Fatalf("DeploymentType mismatches. actual: %v expected: %v", fsOptions.DeploymentType, backup.DeploymentType)
+						}
+						if fsOptions.StorageType != backup.StorageType {
+							t.Fatalf("StorageType mismatches. actual: %v expected: %v", fsOptions.StorageType, backup.StorageType)
+						}
+						if fsOptions.PerUnitStorageThroughput != 500 {
+							t.Fatalf("PerUnitStorageThroughput mismatches. actual: %v expected: %v", fsOptions.PerUnitStorageThroughput, 500)
+						}
+						if fsOptions.KmsKeyId != "kmsKeyId" {
+							t.Fatalf("KmsKeyId mismatches. actual: %v expected: %v", fsOptions.KmsKeyId, "kmsKeyId")
+						}
+						if fsOptions.CapacityGiB != 2*volumeSizeGiB {
+							t.Fatalf("CapacityGiB mismatches. actual: %v expected: %v", fsOptions.CapacityGiB, 2*volumeSizeGiB)
+						}
+						return fs, nil
+					})
+				mockCloud.EXPECT().WaitForFileSystemAvailable(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil)
 
-				mockCloud.EXPECT().DeleteFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil)
-				_, err := driver.DeleteVolume(ctx, req)
+				resp, err := driver.CreateVolume(ctx, req)
 				if err != nil {
-					t.Fatalf("DeleteVolume is failed: %v", err)
+					t.Fatalf("CreateVolume is failed: %v", err)
 				}
 
-				mockCtl.Finish()
-			},
-		},
-		{
-			name: "fail: volume ID is missing",
-			testFunc: func(t *testing.T) {
-				mockCtl := gomock.NewController(t)
-				mockCloud := mocks.NewMockCloud(mockCtl)
-
-				driver := controllerService{
-					cloud:         mockCloud,
-					inFlight:      internal.NewInFlight(),
-					driverOptions: &DriverOptions{},
+				if resp.Volume.VolumeId != fileSystemId {
+					t.Fatalf("VolumeId mismatches. actual: %v expected: %v", resp.Volume.VolumeId, fileSystemId)
 				}
 
-				req := &csi.DeleteVolumeRequest{}
-
-				ctx := context.Background()
-				_, err := driver.DeleteVolume(ctx, req)
-				if err == nil {
-					t.Fatal("DeleteVolume is not failed")
+				if resp.Volume.ContentSource.GetSnapshot().GetSnapshotId() != backupId {
+					t.Fatalf("ContentSource mismatches. actual: %v expected: %v", resp.Volume.ContentSource, snapshotSource)
 				}
 
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "success: DeleteFileSystem returns ErrNotFound",
+			name: "fail: requested capacity less than snapshot size",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -691,22 +881,45 @@ func TestDeleteVolume(t *testing.T) {
 					driverOptions: &DriverOptions{},
 				}
 
-				req := &csi.DeleteVolumeRequest{
-					VolumeId: fileSystemId,
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: util.GiBToBytes(volumeSizeGiB),
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:         subnetId,
+						volumeParamsSecurityGroupIds: securityGroupIds,
+					},
+					VolumeContentSource: snapshotSource,
 				}
 
 				ctx := context.Background()
-				mockCloud.EXPECT().DeleteFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(cloud.ErrNotFound)
-				_, err := driver.DeleteVolume(ctx, req)
-				if err != nil {
-					t.Fatalf("DeleteVolume is failed: %v", err)
+				backup := &cloud.Backup{
+					BackupId:                 backupId,
+					FileSystemId:             "fs-5678",
+					CapacityGiB:              2 * volumeSizeGiB,
+					StorageType:              "SSD",
+					DeploymentType:           "PERSISTENT_1",
+					PerUnitStorageThroughput: 200,
+					Lifecycle:                backupLifecycleAvailable,
+				}
+				mockCloud.EXPECT().DescribeBackup(gomock.Eq(ctx), gomock.Eq(backupId)).Return(backup, nil)
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().DescribeSubnet(gomock.Eq(ctx), gomock.Eq(subnetId)).Return(&cloud.Subnet{SubnetId: subnetId, AvailabilityZone: availabilityZone}, nil)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.OutOfRange {
+					t.Fatalf("Unexpected error: %v", err)
 				}
 
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "fail: DeleteFileSystem returns other error",
+			name: "fail: deploymentType does not match snapshot",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -717,39 +930,46 @@ func TestDeleteVolume(t *testing.T) {
 					driverOptions: &DriverOptions{},
 				}
 
-				req := &csi.DeleteVolumeRequest{
-					VolumeId: fileSystemId,
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: util.GiBToBytes(volumeSizeGiB),
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:         subnetId,
+						volumeParamsSecurityGroupIds: securityGroupIds,
+						volumeParamsDeploymentType:   "SCRATCH_2",
+					},
+					VolumeContentSource: snapshotSource,
 				}
 
 				ctx := context.Background()
-				mockCloud.EXPECT().DeleteFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(errors.New("DeleteFileSystem failed"))
-				_, err := driver.DeleteVolume(ctx, req)
-				if err == nil {
-					t.Fatal("DeleteVolume is not failed")
+				backup := &cloud.Backup{
+					BackupId:                 backupId,
+					FileSystemId:             "fs-5678",
+					CapacityGiB:              volumeSizeGiB,
+					StorageType:              "SSD",
+					DeploymentType:           "PERSISTENT_1",
+					PerUnitStorageThroughput: 200,
+					Lifecycle:                backupLifecycleAvailable,
+				}
+				mockCloud.EXPECT().DescribeBackup(gomock.Eq(ctx), gomock.Eq(backupId)).Return(backup, nil)
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().DescribeSubnet(gomock.Eq(ctx), gomock.Eq(subnetId)).Return(&cloud.Subnet{SubnetId: subnetId, AvailabilityZone: availabilityZone}, nil)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
 				}
 
 				mockCtl.Finish()
 			},
 		},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.name, tc.testFunc)
-	}
-}
-
-func TestExpandVolume(t *testing.T) {
-	var (
-		fileSystemId         = "fs-1234"
-		initialSizeGiB int32 = 1200
-		finalSizeGiB   int32 = 2400
-	)
-	testCases := []struct {
-		name     string
-		testFunc func(t *testing.T)
-	}{
 		{
-			name: "success: normal",
+			name: "fail: source snapshot not ready",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -760,38 +980,43 @@ func TestExpandVolume(t *testing.T) {
 					driverOptions: &DriverOptions{},
 				}
 
-				ctx := context.Background()
-				expandRequest := &csi.ControllerExpandVolumeRequest{
-					VolumeId: fileSystemId,
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
 					CapacityRange: &csi.CapacityRange{
-						RequiredBytes: util.GiBToBytes(1440),
-						LimitBytes:    util.GiBToBytes(3600),
+						RequiredBytes: util.GiBToBytes(volumeSizeGiB),
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:         subnetId,
+						volumeParamsSecurityGroupIds: securityGroupIds,
 					},
+					VolumeContentSource: snapshotSource,
 				}
 
-				initialFs := &cloud.FileSystem{
-					FileSystemId:             fileSystemId,
-					CapacityGiB:              initialSizeGiB,
-					DnsName:                  "test.us-east-1.fsx.amazonaws.com",
-					MountName:                "random",
+				ctx := context.Background()
+				backup := &cloud.Backup{
+					BackupId:                 backupId,
+					FileSystemId:             "fs-5678",
+					CapacityGiB:              volumeSizeGiB,
 					StorageType:              "SSD",
-					DeploymentType:           "SCRATCH_2",
-					PerUnitStorageThroughput: 0,
+					DeploymentType:           "PERSISTENT_1",
+					PerUnitStorageThroughput: 200,
+					Lifecycle:                "CREATING",
 				}
+				mockCloud.EXPECT().DescribeBackup(gomock.Eq(ctx), gomock.Eq(backupId)).Return(backup, nil)
 
-				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(initialFs, nil)
-				mockCloud.EXPECT().ResizeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Eq(finalSizeGiB)).Return(finalSizeGiB, nil)
-				mockCloud.EXPECT().WaitForFileSystemResize(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Eq(finalSizeGiB)).Return(nil)
-				_, err := driver.ControllerExpandVolume(ctx, expandRequest)
-				if err != nil {
-					t.Fatalf("ControllerExpandVolume failed: %v", err)
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.Unavailable {
+					t.Fatalf("Unexpected error: %v", err)
 				}
 
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "success: requested capacity does not exceed current capacity",
+			name: "success: clone from volume",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -802,36 +1027,3797 @@ func TestExpandVolume(t *testing.T) {
 					driverOptions: &DriverOptions{},
 				}
 
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: util.GiBToBytes(volumeSizeGiB),
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:         subnetId,
+						volumeParamsSecurityGroupIds: securityGroupIds,
+					},
+					VolumeContentSource: volumeSource,
+				}
+
 				ctx := context.Background()
-				expandRequest := &csi.ControllerExpandVolumeRequest{
-					VolumeId: fileSystemId,
+				sourceFs := &cloud.FileSystem{
+					FileSystemId:   sourceFileSystemId,
+					CapacityGiB:    volumeSizeGiB,
+					DnsName:        dnsName,
+					MountName:      mountName,
+					StorageType:    "SSD",
+					DeploymentType: "PERSISTENT_1",
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(sourceFileSystemId)).Return(sourceFs, nil)
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().DescribeSubnet(gomock.Eq(ctx), gomock.Eq(subnetId)).Return(&cloud.Subnet{SubnetId: subnetId, AvailabilityZone: availabilityZone}, nil)
+				backup := &cloud.Backup{
+					BackupId:                 backupId,
+					FileSystemId:             sourceFileSystemId,
+					CapacityGiB:              volumeSizeGiB,
+					StorageType:              "SSD",
+					DeploymentType:           "PERSISTENT_1",
+					PerUnitStorageThroughput: 200,
+					Lifecycle:                "CREATING",
+				}
+				backupOptions := &cloud.BackupOptions{
+					FileSystemId:    sourceFileSystemId,
+					CloneVolumeName: volumeName,
+				}
+				mockCloud.EXPECT().CreateBackup(gomock.Eq(ctx), gomock.Eq(volumeName+cloneBackupNameSuffix), gomock.Eq(backupOptions)).Return(backup, nil)
+				fs := &cloud.FileSystem{
+					FileSystemId:   fileSystemId,
+					CapacityGiB:    volumeSizeGiB,
+					DnsName:        dnsName,
+					MountName:      mountName,
+					StorageType:    "SSD",
+					DeploymentType: "PERSISTENT_1",
+				}
+				mockCloud.EXPECT().WaitForBackupAvailable(gomock.Eq(ctx), gomock.Eq(backupId)).Return(nil)
+				mockCloud.EXPECT().CreateFileSystemFromBackup(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Eq(backupId), gomock.Any()).Return(fs, nil)
+				mockCloud.EXPECT().WaitForFileSystemAvailable(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil)
+				mockCloud.EXPECT().DeleteBackup(gomock.Eq(ctx), gomock.Eq(backupId)).Return(nil)
+
+				resp, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume is failed: %v", err)
+				}
+
+				if resp.Volume.VolumeId != fileSystemId {
+					t.Fatalf("VolumeId mismatches. actual: %v expected: %v", resp.Volume.VolumeId, fileSystemId)
+				}
+
+				if resp.Volume.ContentSource.GetVolume().GetVolumeId() != sourceFileSystemId {
+					t.Fatalf("ContentSource mismatches. actual: %v expected: %v", resp.Volume.ContentSource, volumeSource)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: retry of timed out clone deletes backup",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
 					CapacityRange: &csi.CapacityRange{
-						RequiredBytes: util.GiBToBytes(initialSizeGiB),
-						LimitBytes:    util.GiBToBytes(3600),
+						RequiredBytes: util.GiBToBytes(volumeSizeGiB),
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:         subnetId,
+						volumeParamsSecurityGroupIds: securityGroupIds,
+					},
+					VolumeContentSource: volumeSource,
+				}
+
+				ctx := context.Background()
+				sourceFs := &cloud.FileSystem{
+					FileSystemId:   sourceFileSystemId,
+					CapacityGiB:    volumeSizeGiB,
+					DnsName:        dnsName,
+					MountName:      mountName,
+					StorageType:    "SSD",
+					DeploymentType: "PERSISTENT_1",
+				}
+				backup := &cloud.Backup{
+					BackupId:       backupId,
+					FileSystemId:   sourceFileSystemId,
+					CapacityGiB:    volumeSizeGiB,
+					StorageType:    "SSD",
+					DeploymentType: "PERSISTENT_1",
+					Lifecycle:      "AVAILABLE",
+				}
+				fs := &cloud.FileSystem{
+					FileSystemId:   fileSystemId,
+					CapacityGiB:    volumeSizeGiB,
+					DnsName:        dnsName,
+					MountName:      mountName,
+					StorageType:    "SSD",
+					DeploymentType: "PERSISTENT_1",
+				}
+
+				// the first attempt times out waiting for the clone, which keeps the backup
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(sourceFileSystemId)).Return(sourceFs, nil)
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().DescribeSubnet(gomock.Eq(ctx), gomock.Eq(subnetId)).Return(&cloud.Subnet{SubnetId: subnetId, AvailabilityZone: availabilityZone}, nil)
+				mockCloud.EXPECT().CreateBackup(gomock.Eq(ctx), gomock.Eq(volumeName+cloneBackupNameSuffix), gomock.Any()).Return(backup, nil)
+				mockCloud.EXPECT().WaitForBackupAvailable(gomock.Eq(ctx), gomock.Eq(backupId)).Return(nil)
+				mockCloud.EXPECT().CreateFileSystemFromBackup(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Eq(backupId), gomock.Any()).Return(fs, nil)
+				mockCloud.EXPECT().WaitForFileSystemAvailable(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(errors.New("timed out waiting for the condition"))
+
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.Internal {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				// the retry finds the existing clone without the source, which may be deleted in the meantime, and looks up
+				// the backup to delete it once the clone is available
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(fs, nil)
+				mockCloud.EXPECT().WaitForFileSystemAvailable(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil)
+				mockCloud.EXPECT().FindCloneBackup(gomock.Eq(ctx), gomock.Eq(sourceFileSystemId), gomock.Eq(volumeName)).Return(backup, nil)
+				mockCloud.EXPECT().DeleteBackup(gomock.Eq(ctx), gomock.Eq(backupId)).Return(nil)
+
+				resp, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume is failed: %v", err)
+				}
+
+				if resp.Volume.VolumeId != fileSystemId {
+					t.Fatalf("VolumeId mismatches. actual: %v expected: %v", resp.Volume.VolumeId, fileSystemId)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: clone from volume and retain backup",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: util.GiBToBytes(volumeSizeGiB),
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:          subnetId,
+						volumeParamsSecurityGroupIds:  securityGroupIds,
+						volumeParamsDeleteCloneBackup: "false",
+					},
+					VolumeContentSource: volumeSource,
+				}
+
+				ctx := context.Background()
+				sourceFs := &cloud.FileSystem{
+					FileSystemId:   sourceFileSystemId,
+					CapacityGiB:    volumeSizeGiB,
+					DnsName:        dnsName,
+					MountName:      mountName,
+					StorageType:    "SSD",
+					DeploymentType: "PERSISTENT_1",
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(sourceFileSystemId)).Return(sourceFs, nil)
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().DescribeSubnet(gomock.Eq(ctx), gomock.Eq(subnetId)).Return(&cloud.Subnet{SubnetId: subnetId, AvailabilityZone: availabilityZone}, nil)
+				backup := &cloud.Backup{
+					BackupId:                 backupId,
+					FileSystemId:             sourceFileSystemId,
+					CapacityGiB:              volumeSizeGiB,
+					StorageType:              "SSD",
+					DeploymentType:           "PERSISTENT_1",
+					PerUnitStorageThroughput: 200,
+					Lifecycle:                "CREATING",
+				}
+				backupOptions := &cloud.BackupOptions{
+					FileSystemId:    sourceFileSystemId,
+					CloneVolumeName: volumeName,
+				}
+				mockCloud.EXPECT().CreateBackup(gomock.Eq(ctx), gomock.Eq(volumeName+cloneBackupNameSuffix), gomock.Eq(backupOptions)).Return(backup, nil)
+				fs := &cloud.FileSystem{
+					FileSystemId:   fileSystemId,
+					CapacityGiB:    volumeSizeGiB,
+					DnsName:        dnsName,
+					MountName:      mountName,
+					StorageType:    "SSD",
+					DeploymentType: "PERSISTENT_1",
+				}
+				mockCloud.EXPECT().WaitForBackupAvailable(gomock.Eq(ctx), gomock.Eq(backupId)).Return(nil)
+				mockCloud.EXPECT().CreateFileSystemFromBackup(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Eq(backupId), gomock.Any()).Return(fs, nil)
+				mockCloud.EXPECT().WaitForFileSystemAvailable(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: clone backup failed",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: util.GiBToBytes(volumeSizeGiB),
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:         subnetId,
+						volumeParamsSecurityGroupIds: securityGroupIds,
+					},
+					VolumeContentSource: volumeSource,
+				}
+
+				ctx := context.Background()
+				sourceFs := &cloud.FileSystem{
+					FileSystemId:   sourceFileSystemId,
+					CapacityGiB:    volumeSizeGiB,
+					DnsName:        dnsName,
+					MountName:      mountName,
+					StorageType:    "SSD",
+					DeploymentType: "PERSISTENT_1",
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(sourceFileSystemId)).Return(sourceFs, nil)
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().DescribeSubnet(gomock.Eq(ctx), gomock.Eq(subnetId)).Return(&cloud.Subnet{SubnetId: subnetId, AvailabilityZone: availabilityZone}, nil)
+				backup := &cloud.Backup{
+					BackupId:                 backupId,
+					FileSystemId:             sourceFileSystemId,
+					CapacityGiB:              volumeSizeGiB,
+					StorageType:              "SSD",
+					DeploymentType:           "PERSISTENT_1",
+					PerUnitStorageThroughput: 200,
+					Lifecycle:                "CREATING",
+				}
+				backupOptions := &cloud.BackupOptions{
+					FileSystemId:    sourceFileSystemId,
+					CloneVolumeName: volumeName,
+				}
+				mockCloud.EXPECT().CreateBackup(gomock.Eq(ctx), gomock.Eq(volumeName+cloneBackupNameSuffix), gomock.Eq(backupOptions)).Return(backup, nil)
+				mockCloud.EXPECT().WaitForBackupAvailable(gomock.Eq(ctx), gomock.Eq(backupId)).Return(errors.New("backup failed"))
+
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.Internal {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: source volume not found",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:         subnetId,
+						volumeParamsSecurityGroupIds: securityGroupIds,
+					},
+					VolumeContentSource: volumeSource,
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(sourceFileSystemId)).Return(nil, cloud.ErrNotFound)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.NotFound {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: source snapshot not found",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:         subnetId,
+						volumeParamsSecurityGroupIds: securityGroupIds,
+					},
+					VolumeContentSource: &csi.VolumeContentSource{
+						Type: &csi.VolumeContentSource_Snapshot{
+							Snapshot: &csi.VolumeContentSource_SnapshotSource{
+								SnapshotId: "backup-1234",
+							},
+						},
+					},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeBackup(gomock.Eq(ctx), gomock.Eq("backup-1234")).Return(nil, cloud.ErrNotFound)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.NotFound {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: accessible topology of the subnet",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:         subnetId,
+						volumeParamsSecurityGroupIds: securityGroupIds,
+					},
+					AccessibilityRequirements: &csi.TopologyRequirement{
+						Requisite: []*csi.Topology{
+							{Segments: map[string]string{TopologyKey: "us-west-2b"}},
+							{Segments: map[string]string{TopologyKey: availabilityZone}},
+						},
+					},
+				}
+
+				ctx := context.Background()
+				fs := &cloud.FileSystem{
+					FileSystemId: fileSystemId,
+					CapacityGiB:  volumeSizeGiB,
+					DnsName:      dnsName,
+					MountName:    mountName,
+					SubnetId:     subnetId,
+				}
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().DescribeSubnet(gomock.Eq(ctx), gomock.Eq(subnetId)).Return(&cloud.Subnet{SubnetId: subnetId, AvailabilityZone: availabilityZone}, nil)
+				mockCloud.EXPECT().CreateFileSystem(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Any()).Return(fs, nil)
+				mockCloud.EXPECT().WaitForFileSystemAvailable(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil)
+
+				resp, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume is failed: %v", err)
+				}
+
+				if len(resp.Volume.AccessibleTopology) != 1 {
+					t.Fatalf("AccessibleTopology length mismatches. actual: %v expected: %v", len(resp.Volume.AccessibleTopology), 1)
+				}
+
+				if zone := resp.Volume.AccessibleTopology[0].Segments[TopologyKey]; zone != availabilityZone {
+					t.Fatalf("zone mismatches. actual: %v expected: %v", zone, availabilityZone)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: accessible topology of an existing filesystem",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:         subnetId,
+						volumeParamsSecurityGroupIds: securityGroupIds,
+					},
+				}
+
+				ctx := context.Background()
+				fs := &cloud.FileSystem{
+					FileSystemId: fileSystemId,
+					CapacityGiB:  volumeSizeGiB,
+					DnsName:      dnsName,
+					MountName:    mountName,
+					SubnetId:     subnetId,
+				}
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(fs, nil)
+				mockCloud.EXPECT().DescribeSubnet(gomock.Eq(ctx), gomock.Eq(subnetId)).Return(&cloud.Subnet{SubnetId: subnetId, AvailabilityZone: availabilityZone}, nil)
+				mockCloud.EXPECT().WaitForFileSystemAvailable(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil)
+
+				resp, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume is failed: %v", err)
+				}
+
+				if len(resp.Volume.AccessibleTopology) != 1 {
+					t.Fatalf("AccessibleTopology length mismatches. actual: %v expected: %v", len(resp.Volume.AccessibleTopology), 1)
+				}
+
+				if zone := resp.Volume.AccessibleTopology[0].Segments[TopologyKey]; zone != availabilityZone {
+					t.Fatalf("zone mismatches. actual: %v expected: %v", zone, availabilityZone)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: subnet does not satisfy accessibility requirements",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:         subnetId,
+						volumeParamsSecurityGroupIds: securityGroupIds,
+					},
+					AccessibilityRequirements: &csi.TopologyRequirement{
+						Requisite: []*csi.Topology{
+							{Segments: map[string]string{TopologyKey: "us-west-2b"}},
+						},
+					},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().DescribeSubnet(gomock.Eq(ctx), gomock.Eq(subnetId)).Return(&cloud.Subnet{SubnetId: subnetId, AvailabilityZone: availabilityZone}, nil)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.ResourceExhausted {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: subnet not found",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:         subnetId,
+						volumeParamsSecurityGroupIds: securityGroupIds,
+					},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().DescribeSubnet(gomock.Eq(ctx), gomock.Eq(subnetId)).Return(nil, cloud.ErrNotFound)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: subnetSelector picks a subnet in the preferred zone",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetSelector:   "Tier=private, kubernetes.io/cluster/test",
+						volumeParamsSecurityGroupIds: securityGroupIds,
+					},
+					AccessibilityRequirements: &csi.TopologyRequirement{
+						Requisite: []*csi.Topology{
+							{Segments: map[string]string{TopologyKey: "us-west-2b"}},
+							{Segments: map[string]string{TopologyKey: availabilityZone}},
+						},
+						Preferred: []*csi.Topology{
+							{Segments: map[string]string{TopologyKey: availabilityZone}},
+						},
+					},
+				}
+
+				ctx := context.Background()
+				subnets := []*cloud.Subnet{
+					{SubnetId: "subnet-b", AvailabilityZone: "us-west-2b", AvailableIpAddressCount: 4000},
+					{SubnetId: "subnet-a1", AvailabilityZone: availabilityZone, AvailableIpAddressCount: 100},
+					{SubnetId: "subnet-a2", AvailabilityZone: availabilityZone, AvailableIpAddressCount: 200},
+				}
+				fs := &cloud.FileSystem{
+					FileSystemId: fileSystemId,
+					CapacityGiB:  volumeSizeGiB,
+					DnsName:      dnsName,
+					MountName:    mountName,
+					SubnetId:     "subnet-a2",
+				}
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().FindSubnetsByTags(gomock.Eq(ctx), gomock.Eq(map[string]string{"Tier": "private", "kubernetes.io/cluster/test": ""})).Return(subnets, nil)
+				mockCloud.EXPECT().CreateFileSystem(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ string, fsOptions *cloud.FileSystemOptions) (*cloud.FileSystem, error) {
+						if fsOptions.SubnetId != "subnet-a2" {
+							t.Fatalf("SubnetId mismatches. actual: %v expected: %v", fsOptions.SubnetId, "subnet-a2")
+						}
+						return fs, nil
+					})
+				mockCloud.EXPECT().WaitForFileSystemAvailable(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil)
+
+				resp, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume is failed: %v", err)
+				}
+
+				if zone := resp.Volume.AccessibleTopology[0].Segments[TopologyKey]; zone != availabilityZone {
+					t.Fatalf("zone mismatches. actual: %v expected: %v", zone, availabilityZone)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: subnetId and subnetSelector are both set",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:         subnetId,
+						volumeParamsSubnetSelector:   "Tier=private",
+						volumeParamsSecurityGroupIds: securityGroupIds,
+					},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: no subnet matches subnetSelector",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetSelector:   "Tier=private",
+						volumeParamsSecurityGroupIds: securityGroupIds,
+					},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().FindSubnetsByTags(gomock.Eq(ctx), gomock.Any()).Return(nil, nil)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: no subnet matching subnetSelector in requisite zones",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetSelector:   "Tier=private",
+						volumeParamsSecurityGroupIds: securityGroupIds,
+					},
+					AccessibilityRequirements: &csi.TopologyRequirement{
+						Requisite: []*csi.Topology{
+							{Segments: map[string]string{TopologyKey: "us-west-2c"}},
+						},
+					},
+				}
+
+				ctx := context.Background()
+				subnets := []*cloud.Subnet{
+					{SubnetId: subnetId, AvailabilityZone: availabilityZone, AvailableIpAddressCount: 100},
+				}
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().FindSubnetsByTags(gomock.Eq(ctx), gomock.Any()).Return(subnets, nil)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.ResourceExhausted {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: empty securityGroupIds",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:         subnetId,
+						volumeParamsSecurityGroupIds: "",
+					},
+				}
+
+				ctx := context.Background()
+				fs := &cloud.FileSystem{
+					FileSystemId: fileSystemId,
+					CapacityGiB:  volumeSizeGiB,
+					DnsName:      dnsName,
+					MountName:    mountName,
+				}
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().DescribeSubnet(gomock.Eq(ctx), gomock.Eq(subnetId)).Return(&cloud.Subnet{SubnetId: subnetId, AvailabilityZone: availabilityZone}, nil)
+				mockCloud.EXPECT().CreateFileSystem(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ string, fsOptions *cloud.FileSystemOptions) (*cloud.FileSystem, error) {
+						if len(fsOptions.SecurityGroupIds) != 0 {
+							t.Fatalf("SecurityGroupIds mismatches. actual: %q expected: none", fsOptions.SecurityGroupIds)
+						}
+						return fs, nil
+					})
+				mockCloud.EXPECT().WaitForFileSystemAvailable(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: securityGroupSelector",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:              subnetId,
+						volumeParamsSecurityGroupIds:      "sg-1, sg-2",
+						volumeParamsSecurityGroupSelector: "fsx-lustre=true",
+					},
+				}
+
+				ctx := context.Background()
+				fs := &cloud.FileSystem{
+					FileSystemId: fileSystemId,
+					CapacityGiB:  volumeSizeGiB,
+					DnsName:      dnsName,
+					MountName:    mountName,
+				}
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().DescribeSubnet(gomock.Eq(ctx), gomock.Eq(subnetId)).Return(&cloud.Subnet{SubnetId: subnetId, VpcId: vpcId, AvailabilityZone: availabilityZone}, nil)
+				mockCloud.EXPECT().FindSecurityGroupsByTags(gomock.Eq(ctx), gomock.Eq(vpcId), gomock.Eq(map[string]string{"fsx-lustre": "true"})).Return([]string{"sg-3"}, nil)
+				mockCloud.EXPECT().CreateFileSystem(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ string, fsOptions *cloud.FileSystemOptions) (*cloud.FileSystem, error) {
+						if strings.Join(fsOptions.SecurityGroupIds, ",") != "sg-1,sg-2,sg-3" {
+							t.Fatalf("SecurityGroupIds mismatches. actual: %q expected: %q", fsOptions.SecurityGroupIds, []string{"sg-1", "sg-2", "sg-3"})
+						}
+						return fs, nil
+					})
+				mockCloud.EXPECT().WaitForFileSystemAvailable(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: no security group matches securityGroupSelector",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:              subnetId,
+						volumeParamsSecurityGroupSelector: "fsx-lustre=true",
+					},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().DescribeSubnet(gomock.Eq(ctx), gomock.Eq(subnetId)).Return(&cloud.Subnet{SubnetId: subnetId, VpcId: vpcId, AvailabilityZone: availabilityZone}, nil)
+				mockCloud.EXPECT().FindSecurityGroupsByTags(gomock.Eq(ctx), gomock.Eq(vpcId), gomock.Any()).Return(nil, nil)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: securityGroupSelector without subnet",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsSecurityGroupSelector: "fsx-lustre=true",
+					},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: cluster security group",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:    mockCloud,
+					inFlight: internal.NewInFlight(),
+					driverOptions: &DriverOptions{
+						clusterName:          clusterName,
+						nodeSecurityGroupIds: []string{"sg-node"},
+						createSecurityGroup:  true,
+					},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId: subnetId,
+					},
+				}
+
+				ctx := context.Background()
+				fs := &cloud.FileSystem{
+					FileSystemId: fileSystemId,
+					CapacityGiB:  volumeSizeGiB,
+					DnsName:      dnsName,
+					MountName:    mountName,
+				}
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().DescribeSubnet(gomock.Eq(ctx), gomock.Eq(subnetId)).Return(&cloud.Subnet{SubnetId: subnetId, VpcId: vpcId, AvailabilityZone: availabilityZone}, nil)
+				mockCloud.EXPECT().EnsureClusterSecurityGroup(gomock.Eq(ctx), gomock.Eq(clusterName), gomock.Eq(vpcId), gomock.Eq([]string{"sg-node"})).Return("sg-cluster", nil)
+				mockCloud.EXPECT().CreateFileSystem(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ string, fsOptions *cloud.FileSystemOptions) (*cloud.FileSystem, error) {
+						if strings.Join(fsOptions.SecurityGroupIds, ",") != "sg-cluster" {
+							t.Fatalf("SecurityGroupIds mismatches. actual: %q expected: %q", fsOptions.SecurityGroupIds, []string{"sg-cluster"})
+						}
+						return fs, nil
+					})
+				mockCloud.EXPECT().WaitForFileSystemAvailable(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: cluster security group could not be created",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:    mockCloud,
+					inFlight: internal.NewInFlight(),
+					driverOptions: &DriverOptions{
+						clusterName:          clusterName,
+						nodeSecurityGroupIds: []string{"sg-node"},
+						createSecurityGroup:  true,
+					},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId: subnetId,
+					},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().DescribeSubnet(gomock.Eq(ctx), gomock.Eq(subnetId)).Return(&cloud.Subnet{SubnetId: subnetId, VpcId: vpcId, AvailabilityZone: availabilityZone}, nil)
+				mockCloud.EXPECT().EnsureClusterSecurityGroup(gomock.Eq(ctx), gomock.Eq(clusterName), gomock.Eq(vpcId), gomock.Any()).Return("", errors.New("CreateSecurityGroup failed"))
+
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.Internal {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: data repository associations",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:                   subnetId,
+						volumeParamsSecurityGroupIds:           securityGroupIds,
+						volumeParamsDeploymentType:             "PERSISTENT_2",
+						volumeParamsPerUnitStorageThroughput:   "125",
+						volumeParamsDataRepositoryAssociations: dataRepositoryAssociations,
+					},
+				}
+
+				ctx := context.Background()
+				fs := &cloud.FileSystem{
+					FileSystemId: fileSystemId,
+					CapacityGiB:  volumeSizeGiB,
+					DnsName:      dnsName,
+					MountName:    mountName,
+				}
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().DescribeSubnet(gomock.Eq(ctx), gomock.Eq(subnetId)).Return(&cloud.Subnet{SubnetId: subnetId, AvailabilityZone: availabilityZone}, nil)
+				mockCloud.EXPECT().CreateFileSystem(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Any()).Return(fs, nil)
+				mockCloud.EXPECT().WaitForFileSystemAvailable(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil)
+				mockCloud.EXPECT().ListDataRepositoryAssociations(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return([]*cloud.DataRepositoryAssociation{
+					{AssociationId: "dra-1", FileSystemId: fileSystemId, FileSystemPath: "/training"},
+				}, nil)
+				mockCloud.EXPECT().CreateDataRepositoryAssociation(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ string, associationOptions *cloud.DataRepositoryAssociationOptions) (*cloud.DataRepositoryAssociation, error) {
+						if associationOptions.FileSystemPath != "/results" {
+							t.Fatalf("FileSystemPath mismatches. actual: %v expected: %v", associationOptions.FileSystemPath, "/results")
+						}
+						if len(associationOptions.AutoExportEvents) != 2 {
+							t.Fatalf("AutoExportEvents length mismatches. actual: %v expected: %v", len(associationOptions.AutoExportEvents), 2)
+						}
+						return &cloud.DataRepositoryAssociation{AssociationId: "dra-2", FileSystemId: fileSystemId, FileSystemPath: "/results"}, nil
+					})
+				mockCloud.EXPECT().WaitForDataRepositoryAssociationAvailable(gomock.Eq(ctx), gomock.Eq("dra-1")).Return(nil)
+				mockCloud.EXPECT().WaitForDataRepositoryAssociationAvailable(gomock.Eq(ctx), gomock.Eq("dra-2")).Return(nil)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: data repository association not ready",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:                   subnetId,
+						volumeParamsSecurityGroupIds:           securityGroupIds,
+						volumeParamsDataRepositoryAssociations: `[{"fileSystemPath": "/training", "dataRepositoryPath": "s3://bucket/training"}]`,
+					},
+				}
+
+				ctx := context.Background()
+				fs := &cloud.FileSystem{
+					FileSystemId: fileSystemId,
+					CapacityGiB:  volumeSizeGiB,
+					DnsName:      dnsName,
+					MountName:    mountName,
+				}
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(fs, nil)
+				mockCloud.EXPECT().WaitForFileSystemAvailable(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil)
+				mockCloud.EXPECT().ListDataRepositoryAssociations(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil, nil)
+				mockCloud.EXPECT().CreateDataRepositoryAssociation(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Any()).Return(&cloud.DataRepositoryAssociation{AssociationId: "dra-1"}, nil)
+				mockCloud.EXPECT().WaitForDataRepositoryAssociationAvailable(gomock.Eq(ctx), gomock.Eq("dra-1")).Return(errors.New("data repository association dra-1 is MISCONFIGURED"))
+
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.Internal {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: invalid data repository associations",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				for _, val := range []string{
+					`{"fileSystemPath": "/training"}`,
+					`[{"fileSystemPath": "/training", "dataRepositoryPath": "bucket/training"}]`,
+					`[{"fileSystemPath": "training", "dataRepositoryPath": "s3://bucket/training"}]`,
+					`[{"fileSystemPath": "/training", "dataRepositoryPath": "s3://bucket/training", "autoImportPolicy": ["MODIFIED"]}]`,
+					`[{"fileSystemPath": "/training", "dataRepositoryPath": "s3://bucket/training", "importPath": "s3://bucket"}]`,
+					`[{"fileSystemPath": "/training", "dataRepositoryPath": "s3://bucket/a"}, {"fileSystemPath": "/training/", "dataRepositoryPath": "s3://bucket/b"}]`,
+				} {
+					req := &csi.CreateVolumeRequest{
+						Name: volumeName,
+						VolumeCapabilities: []*csi.VolumeCapability{
+							stdVolCap,
+						},
+						Parameters: map[string]string{
+							volumeParamsSubnetId:                   subnetId,
+							volumeParamsSecurityGroupIds:           securityGroupIds,
+							volumeParamsDataRepositoryAssociations: val,
+						},
+					}
+
+					_, err := driver.CreateVolume(context.Background(), req)
+					if status.Code(err) != codes.InvalidArgument {
+						t.Fatalf("Unexpected error for %s: %v", val, err)
+					}
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: data repository associations with s3ImportPath",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:                   subnetId,
+						volumeParamsSecurityGroupIds:           securityGroupIds,
+						volumeParamsS3ImportPath:               "s3://bucket",
+						volumeParamsDataRepositoryAssociations: dataRepositoryAssociations,
+					},
+				}
+
+				_, err := driver.CreateVolume(context.Background(), req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: exportOnDelete and deletionProtection tag the filesystem",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:           subnetId,
+						volumeParamsSecurityGroupIds:   securityGroupIds,
+						volumeParamsS3ImportPath:       "s3://bucket/prefix",
+						volumeParamsExtraTags:          "key1=value1",
+						volumeParamsExportOnDelete:     "true",
+						volumeParamsDeletionProtection: "true",
+					},
+				}
+
+				ctx := context.Background()
+				fs := &cloud.FileSystem{
+					FileSystemId: fileSystemId,
+					CapacityGiB:  volumeSizeGiB,
+					DnsName:      dnsName,
+					MountName:    mountName,
+				}
+				expectedTags := []string{"key1=value1", ExportOnDeleteTagKey + "=true", DeletionProtectionKey + "=true"}
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().DescribeSubnet(gomock.Eq(ctx), gomock.Eq(subnetId)).Return(&cloud.Subnet{SubnetId: subnetId, AvailabilityZone: availabilityZone}, nil)
+				mockCloud.EXPECT().CreateFileSystem(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ string, options *cloud.FileSystemOptions) (*cloud.FileSystem, error) {
+						if !reflect.DeepEqual(options.ExtraTags, expectedTags) {
+							t.Fatalf("ExtraTags mismatches. actual: %v expected: %v", options.ExtraTags, expectedTags)
+						}
+						return fs, nil
+					})
+				mockCloud.EXPECT().WaitForFileSystemAvailable(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: exportOnDelete without data repository",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:         subnetId,
+						volumeParamsSecurityGroupIds: securityGroupIds,
+						volumeParamsExportOnDelete:   "true",
+					},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: finalBackup tags the filesystem",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:                 subnetId,
+						volumeParamsSecurityGroupIds:         securityGroupIds,
+						volumeParamsDeploymentType:           string(types.LustreDeploymentTypePersistent2),
+						volumeParamsPerUnitStorageThroughput: "125",
+						volumeParamsFinalBackup:              "true",
+						volumeParamsFinalBackupTags:          "retention=30d,team=ml",
+					},
+				}
+
+				ctx := context.Background()
+				fs := &cloud.FileSystem{
+					FileSystemId: fileSystemId,
+					CapacityGiB:  volumeSizeGiB,
+					DnsName:      dnsName,
+					MountName:    mountName,
+				}
+				expectedTags := []string{FinalBackupTagKeyPrefix + "retention=30d", FinalBackupTagKeyPrefix + "team=ml", FinalBackupTagKey + "=true"}
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().DescribeSubnet(gomock.Eq(ctx), gomock.Eq(subnetId)).Return(&cloud.Subnet{SubnetId: subnetId, AvailabilityZone: availabilityZone}, nil)
+				mockCloud.EXPECT().CreateFileSystem(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ string, options *cloud.FileSystemOptions) (*cloud.FileSystem, error) {
+						if !reflect.DeepEqual(options.ExtraTags, expectedTags) {
+							t.Fatalf("ExtraTags mismatches. actual: %v expected: %v", options.ExtraTags, expectedTags)
+						}
+						return fs, nil
+					})
+				mockCloud.EXPECT().WaitForFileSystemAvailable(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: finalBackup with SCRATCH deployment type",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:         subnetId,
+						volumeParamsSecurityGroupIds: securityGroupIds,
+						volumeParamsDeploymentType:   string(types.LustreDeploymentTypeScratch2),
+						volumeParamsFinalBackup:      "true",
+					},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().DescribeSubnet(gomock.Eq(ctx), gomock.Eq(subnetId)).Return(&cloud.Subnet{SubnetId: subnetId, AvailabilityZone: availabilityZone}, nil)
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: finalBackup of a filesystem restored from a PERSISTENT snapshot",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:         subnetId,
+						volumeParamsSecurityGroupIds: securityGroupIds,
+						volumeParamsFinalBackup:      "true",
+					},
+					VolumeContentSource: snapshotSource,
+				}
+
+				ctx := context.Background()
+				backup := &cloud.Backup{
+					BackupId:                 backupId,
+					FileSystemId:             "fs-5678",
+					CapacityGiB:              volumeSizeGiB,
+					StorageType:              "SSD",
+					DeploymentType:           "PERSISTENT_2",
+					PerUnitStorageThroughput: 125,
+					Lifecycle:                backupLifecycleAvailable,
+				}
+				fs := &cloud.FileSystem{
+					FileSystemId:   fileSystemId,
+					CapacityGiB:    volumeSizeGiB,
+					DnsName:        dnsName,
+					MountName:      mountName,
+					StorageType:    "SSD",
+					DeploymentType: "PERSISTENT_2",
+				}
+				mockCloud.EXPECT().DescribeBackup(gomock.Eq(ctx), gomock.Eq(backupId)).Return(backup, nil)
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().DescribeSubnet(gomock.Eq(ctx), gomock.Eq(subnetId)).Return(&cloud.Subnet{SubnetId: subnetId, AvailabilityZone: availabilityZone}, nil)
+				mockCloud.EXPECT().CreateFileSystemFromBackup(gomock.Eq(ctx), gomock.Eq(volumeName), gomock.Eq(backupId), gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ string, _ string, fsOptions *cloud.FileSystemOptions) (*cloud.FileSystem, error) {
+						if !slices.Contains(fsOptions.ExtraTags, FinalBackupTagKey+"=true") {
+							t.Fatalf("ExtraTags %v do not contain %v", fsOptions.ExtraTags, FinalBackupTagKey+"=true")
+						}
+						return fs, nil
+					})
+				mockCloud.EXPECT().WaitForFileSystemAvailable(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil)
+
+				_, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: finalBackup of a filesystem restored from a SCRATCH snapshot",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:         subnetId,
+						volumeParamsSecurityGroupIds: securityGroupIds,
+						volumeParamsFinalBackup:      "true",
+					},
+					VolumeContentSource: snapshotSource,
+				}
+
+				ctx := context.Background()
+				backup := &cloud.Backup{
+					BackupId:       backupId,
+					FileSystemId:   "fs-5678",
+					CapacityGiB:    volumeSizeGiB,
+					StorageType:    "SSD",
+					DeploymentType: "SCRATCH_2",
+					Lifecycle:      backupLifecycleAvailable,
+				}
+				mockCloud.EXPECT().DescribeBackup(gomock.Eq(ctx), gomock.Eq(backupId)).Return(backup, nil)
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				mockCloud.EXPECT().DescribeSubnet(gomock.Eq(ctx), gomock.Eq(subnetId)).Return(&cloud.Subnet{SubnetId: subnetId, AvailabilityZone: availabilityZone}, nil)
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: finalBackupTags without finalBackup",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsSubnetId:         subnetId,
+						volumeParamsSecurityGroupIds: securityGroupIds,
+						volumeParamsDeploymentType:   string(types.LustreDeploymentTypePersistent2),
+						volumeParamsFinalBackupTags:  "retention=30d",
+					},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().FindFileSystemByVolumeName(gomock.Eq(ctx), gomock.Eq(volumeName)).Return(nil, cloud.ErrNotFound)
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestDeleteVolume(t *testing.T) {
+	var (
+		fileSystemId = "fs-1234"
+		taskId       = "task-1234"
+		backupId     = "backup-1234"
+		volumeName   = "pvc-1234"
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: normal",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: fileSystemId,
+				}
+
+				ctx := context.Background()
+
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(&cloud.FileSystem{FileSystemId: fileSystemId}, nil)
+				mockCloud.EXPECT().DeleteFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Eq(&cloud.DeleteFileSystemOptions{})).Return("", nil)
+				_, err := driver.DeleteVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("DeleteVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: volume ID is missing",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.DeleteVolumeRequest{}
+
+				ctx := context.Background()
+				_, err := driver.DeleteVolume(ctx, req)
+				if err == nil {
+					t.Fatal("DeleteVolume is not failed")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: DeleteFileSystem returns ErrNotFound",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: fileSystemId,
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(&cloud.FileSystem{FileSystemId: fileSystemId}, nil)
+				mockCloud.EXPECT().DeleteFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Any()).Return("", cloud.ErrNotFound)
+				_, err := driver.DeleteVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("DeleteVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: DeleteFileSystem returns other error",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: fileSystemId,
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(&cloud.FileSystem{FileSystemId: fileSystemId}, nil)
+				mockCloud.EXPECT().DeleteFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Any()).Return("", errors.New("DeleteFileSystem failed"))
+				_, err := driver.DeleteVolume(ctx, req)
+				if err == nil {
+					t.Fatal("DeleteVolume is not failed")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: DescribeFileSystem returns ErrNotFound",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: fileSystemId,
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil, cloud.ErrNotFound)
+				_, err := driver.DeleteVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("DeleteVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: export to data repository before deletion",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: fileSystemId,
+				}
+
+				ctx := context.Background()
+				fs := &cloud.FileSystem{
+					FileSystemId: fileSystemId,
+					Tags:         map[string]string{ExportOnDeleteTagKey: "true"},
+				}
+				task := &cloud.DataRepositoryTask{
+					TaskId:       taskId,
+					FileSystemId: fileSystemId,
+					Type:         dataRepositoryTaskTypeExport,
+					Lifecycle:    dataRepositoryTaskLifecyclePending,
+				}
+				gomock.InOrder(
+					mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil),
+					mockCloud.EXPECT().ListDataRepositoryTasks(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil, nil),
+					mockCloud.EXPECT().CreateDataRepositoryTask(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Eq(dataRepositoryTaskTypeExport)).Return(task, nil),
+					mockCloud.EXPECT().WaitForDataRepositoryTask(gomock.Eq(ctx), gomock.Eq(taskId)).Return(nil),
+					mockCloud.EXPECT().DeleteFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Eq(&cloud.DeleteFileSystemOptions{})).Return("", nil),
+				)
+				_, err := driver.DeleteVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("DeleteVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: export waits for the running export task",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: fileSystemId,
+				}
+
+				ctx := context.Background()
+				fs := &cloud.FileSystem{
+					FileSystemId: fileSystemId,
+					Tags:         map[string]string{ExportOnDeleteTagKey: "true"},
+				}
+				tasks := []*cloud.DataRepositoryTask{
+					{
+						TaskId:       "task-failed",
+						FileSystemId: fileSystemId,
+						Type:         dataRepositoryTaskTypeExport,
+						Lifecycle:    "FAILED",
+					},
+					{
+						TaskId:       taskId,
+						FileSystemId: fileSystemId,
+						Type:         dataRepositoryTaskTypeExport,
+						Lifecycle:    dataRepositoryTaskLifecycleExecuting,
+					},
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
+				mockCloud.EXPECT().ListDataRepositoryTasks(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(tasks, nil)
+				mockCloud.EXPECT().WaitForDataRepositoryTask(gomock.Eq(ctx), gomock.Eq(taskId)).Return(nil)
+				mockCloud.EXPECT().DeleteFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Eq(&cloud.DeleteFileSystemOptions{})).Return("", nil)
+				_, err := driver.DeleteVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("DeleteVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: export to data repository fails",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: fileSystemId,
+				}
+
+				ctx := context.Background()
+				fs := &cloud.FileSystem{
+					FileSystemId: fileSystemId,
+					Tags:         map[string]string{ExportOnDeleteTagKey: "true"},
+				}
+				task := &cloud.DataRepositoryTask{
+					TaskId:       taskId,
+					FileSystemId: fileSystemId,
+					Type:         dataRepositoryTaskTypeExport,
+					Lifecycle:    dataRepositoryTaskLifecyclePending,
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
+				mockCloud.EXPECT().ListDataRepositoryTasks(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil, nil)
+				mockCloud.EXPECT().CreateDataRepositoryTask(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Eq(dataRepositoryTaskTypeExport)).Return(task, nil)
+				mockCloud.EXPECT().WaitForDataRepositoryTask(gomock.Eq(ctx), gomock.Eq(taskId)).Return(errors.New("data repository task failed"))
+				_, err := driver.DeleteVolume(ctx, req)
+				if status.Code(err) != codes.Unavailable {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: final backup is recorded on the PersistentVolume",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				pv := &corev1.PersistentVolume{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: volumeName,
+						UID:  "pv-uid",
+					},
+				}
+				recorder := record.NewFakeRecorder(1)
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+					kubeClient:    fake.NewSimpleClientset(pv),
+					recorder:      recorder,
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: fileSystemId,
+				}
+
+				ctx := context.Background()
+				fs := &cloud.FileSystem{
+					FileSystemId: fileSystemId,
+					Tags: map[string]string{
+						cloud.VolumeNameTagKey:                volumeName,
+						FinalBackupTagKey:                     "true",
+						FinalBackupTagKeyPrefix + "retention": "30d",
+						"team":                                "ml",
+					},
+				}
+				deleteOptions := &cloud.DeleteFileSystemOptions{
+					FinalBackup:     true,
+					FinalBackupTags: []string{cloud.VolumeNameTagKey + "=" + volumeName, "retention=30d"},
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
+				mockCloud.EXPECT().DeleteFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Eq(deleteOptions)).Return(backupId, nil)
+				_, err := driver.DeleteVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("DeleteVolume is failed: %v", err)
+				}
+
+				select {
+				case event := <-recorder.Events:
+					if !strings.Contains(event, "FinalBackupCreated") || !strings.Contains(event, backupId) {
+						t.Fatalf("Unexpected event: %v", event)
+					}
+				default:
+					t.Fatal("No event is recorded")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: filesystem is protected by its tag",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				recorder := record.NewFakeRecorder(1)
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+					recorder:      recorder,
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: fileSystemId,
+				}
+
+				ctx := context.Background()
+				fs := &cloud.FileSystem{
+					FileSystemId: fileSystemId,
+					Tags: map[string]string{
+						cloud.VolumeNameTagKey: volumeName,
+						DeletionProtectionKey:  "true",
+					},
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
+				_, err := driver.DeleteVolume(ctx, req)
+				if status.Code(err) != codes.FailedPrecondition {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				select {
+				case event := <-recorder.Events:
+					if !strings.Contains(event, "DeletionProtected") {
+						t.Fatalf("Unexpected event: %v", event)
+					}
+				default:
+					t.Fatal("No event is recorded")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: filesystem is protected by the annotation of its PersistentVolume",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				pv := &corev1.PersistentVolume{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: volumeName,
+						Annotations: map[string]string{
+							DeletionProtectionKey: "true",
+						},
+					},
+				}
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+					kubeClient:    fake.NewSimpleClientset(pv),
+					recorder:      record.NewFakeRecorder(1),
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: fileSystemId,
+				}
+
+				ctx := context.Background()
+				fs := &cloud.FileSystem{
+					FileSystemId: fileSystemId,
+					Tags: map[string]string{
+						cloud.VolumeNameTagKey: volumeName,
+					},
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
+				_, err := driver.DeleteVolume(ctx, req)
+				if status.Code(err) != codes.FailedPrecondition {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: PersistentVolume without deletion protection",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				pv := &corev1.PersistentVolume{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: volumeName,
+						Annotations: map[string]string{
+							DeletionProtectionKey: "false",
+						},
+					},
+				}
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+					kubeClient:    fake.NewSimpleClientset(pv),
+					recorder:      record.NewFakeRecorder(1),
+				}
+
+				req := &csi.DeleteVolumeRequest{
+					VolumeId: fileSystemId,
+				}
+
+				ctx := context.Background()
+				fs := &cloud.FileSystem{
+					FileSystemId: fileSystemId,
+					Tags: map[string]string{
+						cloud.VolumeNameTagKey: volumeName,
+					},
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
+				mockCloud.EXPECT().DeleteFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Eq(&cloud.DeleteFileSystemOptions{})).Return("", nil)
+				_, err := driver.DeleteVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("DeleteVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestExpandVolume(t *testing.T) {
+	var (
+		fileSystemId         = "fs-1234"
+		initialSizeGiB int32 = 1200
+		finalSizeGiB   int32 = 2400
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: normal",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				ctx := context.Background()
+				expandRequest := &csi.ControllerExpandVolumeRequest{
+					VolumeId: fileSystemId,
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: util.GiBToBytes(1440),
+						LimitBytes:    util.GiBToBytes(3600),
+					},
+				}
+
+				initialFs := &cloud.FileSystem{
+					FileSystemId:             fileSystemId,
+					CapacityGiB:              initialSizeGiB,
+					DnsName:                  "test.us-east-1.fsx.amazonaws.com",
+					MountName:                "random",
+					StorageType:              "SSD",
+					DeploymentType:           "SCRATCH_2",
+					PerUnitStorageThroughput: 0,
+				}
+
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(initialFs, nil)
+				mockCloud.EXPECT().ResizeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Eq(finalSizeGiB)).Return(finalSizeGiB, nil)
+				mockCloud.EXPECT().WaitForFileSystemResize(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Eq(finalSizeGiB)).Return(nil)
+				_, err := driver.ControllerExpandVolume(ctx, expandRequest)
+				if err != nil {
+					t.Fatalf("ControllerExpandVolume failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: requested capacity does not exceed current capacity",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				ctx := context.Background()
+				expandRequest := &csi.ControllerExpandVolumeRequest{
+					VolumeId: fileSystemId,
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: util.GiBToBytes(initialSizeGiB),
+						LimitBytes:    util.GiBToBytes(3600),
+					},
+				}
+
+				initialFs := &cloud.FileSystem{
+					FileSystemId:             fileSystemId,
+					CapacityGiB:              initialSizeGiB,
+					DnsName:                  "test.us-east-1.fsx.amazonaws.com",
+					MountName:                "random",
+					StorageType:              "SSD",
+					DeploymentType:           "SCRATCH_2",
+					PerUnitStorageThroughput: 0,
+				}
+
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(initialFs, nil)
+				_, err := driver.ControllerExpandVolume(ctx, expandRequest)
+				if err != nil {
+					t.Fatalf("ControllerExpandVolume failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "failure: volume ID not provided",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				ctx := context.Background()
+				expandRequest := &csi.ControllerExpandVolumeRequest{
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: util.GiBToBytes(1440),
+					},
+				}
+				expandError := status.Error(codes.InvalidArgument, "Volume ID not provided")
+
+				_, err := driver.ControllerExpandVolume(ctx, expandRequest)
+				if err == nil {
+					t.Fatalf("ControllerExpandVolume did not return error, expected [%v]", expandError)
+				}
+				if err.Error() != expandError.Error() {
+					t.Fatalf("ControllerExpandVolume returned error [%v], expected [%v]", err, expandError)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "failure: capacity range not provided",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				ctx := context.Background()
+				expandRequest := &csi.ControllerExpandVolumeRequest{
+					VolumeId: fileSystemId,
+				}
+				expandError := status.Error(codes.InvalidArgument, "Capacity range not provided")
+
+				_, err := driver.ControllerExpandVolume(ctx, expandRequest)
+				if err == nil {
+					t.Fatalf("ControllerExpandVolume did not return error, expected [%v]", expandError)
+				}
+				if err.Error() != expandError.Error() {
+					t.Fatalf("ControllerExpandVolume returned error [%v], expected [%v]", err, expandError)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "failure: DescribeFileSystems not successful, filesystem not found",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				ctx := context.Background()
+				expandRequest := &csi.ControllerExpandVolumeRequest{
+					VolumeId: fileSystemId,
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: util.GiBToBytes(1440),
+					},
+				}
+				expandError := status.Errorf(codes.NotFound, "Filesystem not found: %v", cloud.ErrNotFound)
+
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil, cloud.ErrNotFound)
+				_, err := driver.ControllerExpandVolume(ctx, expandRequest)
+				if err == nil {
+					t.Fatalf("ControllerExpandVolume did not return error, expected [%v]", expandError)
+				}
+				if err.Error() != expandError.Error() {
+					t.Fatalf("ControllerExpandVolume returned error [%v], expected [%v]", err, expandError)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "failure: DescribeFileSystems not successful, other error",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				ctx := context.Background()
+				expandRequest := &csi.ControllerExpandVolumeRequest{
+					VolumeId: fileSystemId,
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: util.GiBToBytes(1440),
+					},
+				}
+				expandError := status.Errorf(codes.Internal, "DescribeFileSystem failed: %v", cloud.ErrMultiFileSystems)
+
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil, cloud.ErrMultiFileSystems)
+				_, err := driver.ControllerExpandVolume(ctx, expandRequest)
+				if err == nil {
+					t.Fatalf("ControllerExpandVolume did not return error, expected [%v]", expandError)
+				}
+				if err.Error() != expandError.Error() {
+					t.Fatalf("ControllerExpandVolume returned error [%v], expected [%v]", err, expandError)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "failure: capacity limit exceeds required bytes not successful",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				ctx := context.Background()
+				expandRequest := &csi.ControllerExpandVolumeRequest{
+					VolumeId: fileSystemId,
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: util.GiBToBytes(1440),
+						LimitBytes:    util.GiBToBytes(2000),
+					},
+				}
+				expandError := status.Errorf(codes.OutOfRange, "Requested storage capacity of %d bytes exceeds capacity limit of %d bytes.",
+					util.GiBToBytes(finalSizeGiB),
+					expandRequest.GetCapacityRange().GetLimitBytes())
+
+				initialFs := &cloud.FileSystem{
+					FileSystemId:             fileSystemId,
+					CapacityGiB:              initialSizeGiB,
+					DnsName:                  "test.us-east-1.fsx.amazonaws.com",
+					MountName:                "random",
+					StorageType:              "SSD",
+					DeploymentType:           "SCRATCH_2",
+					PerUnitStorageThroughput: 0,
+				}
+
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(initialFs, nil)
+				_, err := driver.ControllerExpandVolume(ctx, expandRequest)
+				if err == nil {
+					t.Fatalf("ControllerExpandVolume did not return error, expected [%v]", expandError)
+				}
+				if err.Error() != expandError.Error() {
+					t.Fatalf("ControllerExpandVolume returned error [%v], expected [%v]", err, expandError)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "failure: ResizeFileSystem failed",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				ctx := context.Background()
+				expandRequest := &csi.ControllerExpandVolumeRequest{
+					VolumeId: fileSystemId,
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: util.GiBToBytes(1440),
+					},
+				}
+				resizeError := fmt.Errorf("UpdateFileSystem failed: %v", &types.BadRequest{Message: aws.String("test")})
+				expandError := status.Errorf(codes.Internal, "resize failed: %v", resizeError)
+
+				initialFs := &cloud.FileSystem{
+					FileSystemId:             fileSystemId,
+					CapacityGiB:              initialSizeGiB,
+					DnsName:                  "test.us-east-1.fsx.amazonaws.com",
+					MountName:                "random",
+					StorageType:              "SSD",
+					DeploymentType:           "SCRATCH_2",
+					PerUnitStorageThroughput: 0,
+				}
+
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(initialFs, nil)
+				mockCloud.EXPECT().ResizeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Eq(finalSizeGiB)).Return(initialSizeGiB, resizeError)
+				_, err := driver.ControllerExpandVolume(ctx, expandRequest)
+				if err == nil {
+					t.Fatalf("ControllerExpandVolume did not return error, expected [%v]", expandError)
+				}
+				if err.Error() != expandError.Error() {
+					t.Fatalf("ControllerExpandVolume returned error [%v], expected [%v]", err, expandError)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "failure: WaitForFileSystemResize failed",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				ctx := context.Background()
+				expandRequest := &csi.ControllerExpandVolumeRequest{
+					VolumeId: fileSystemId,
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: util.GiBToBytes(1440),
+					},
+				}
+				waitError := fmt.Errorf("update failed for filesystem %s: test", fileSystemId)
+				expandError := status.Errorf(codes.Internal, "filesystem is not resized: %v", waitError)
+
+				initialFs := &cloud.FileSystem{
+					FileSystemId:             fileSystemId,
+					CapacityGiB:              initialSizeGiB,
+					DnsName:                  "test.us-east-1.fsx.amazonaws.com",
+					MountName:                "random",
+					StorageType:              "SSD",
+					DeploymentType:           "SCRATCH_2",
+					PerUnitStorageThroughput: 0,
+				}
+
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(initialFs, nil)
+				mockCloud.EXPECT().ResizeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Eq(finalSizeGiB)).Return(finalSizeGiB, nil)
+				mockCloud.EXPECT().WaitForFileSystemResize(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Eq(finalSizeGiB)).Return(waitError)
+				_, err := driver.ControllerExpandVolume(ctx, expandRequest)
+				if err == nil {
+					t.Fatalf("ControllerExpandVolume did not return error, expected [%v]", expandError)
+				}
+				if err.Error() != expandError.Error() {
+					t.Fatalf("ControllerExpandVolume returned error [%v], expected [%v]", err, expandError)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: subdirectory volume without project quota",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mockLustre := mocks.NewMockLustre(mockCtl)
+				mounter := newFakeFileSystemMounter(t)
+				if err := os.MkdirAll(filepath.Join(mounter.root, "teams", "pvc-1234"), 0755); err != nil {
+					t.Fatal(err)
+				}
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					mounter:       mounter,
+					lustre:        mockLustre,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				ctx := context.Background()
+				expandRequest := &csi.ControllerExpandVolumeRequest{
+					VolumeId: fileSystemId + "::/teams/pvc-1234",
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: util.GiBToBytes(20),
+					},
+				}
+
+				fs := &cloud.FileSystem{
+					FileSystemId: fileSystemId,
+					DnsName:      "test.fsx.us-west-2.amazoawd.com",
+					MountName:    "random",
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
+				mockLustre.EXPECT().GetProject(gomock.Any()).Return(uint32(0), nil)
+
+				resp, err := driver.ControllerExpandVolume(ctx, expandRequest)
+				if err != nil {
+					t.Fatalf("ControllerExpandVolume is failed: %v", err)
+				}
+
+				if resp.CapacityBytes != util.GiBToBytes(20) {
+					t.Fatalf("CapacityBytes mismatches. actual: %v expected: %v", resp.CapacityBytes, util.GiBToBytes(20))
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestControllerGetCapabilities(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	mockCloud := mocks.NewMockCloud(mockCtl)
+
+	driver := controllerService{
+		cloud:         mockCloud,
+		inFlight:      internal.NewInFlight(),
+		driverOptions: &DriverOptions{},
+	}
+
+	ctx := context.Background()
+	_, err := driver.ControllerGetCapabilities(ctx, &csi.ControllerGetCapabilitiesRequest{})
+	if err != nil {
+		t.Fatalf("ControllerGetCapabilities is failed: %v", err)
+	}
+}
+
+func TestValidateVolumeCapabilities(t *testing.T) {
+
+	var (
+		fileSystemId = "fs-12345"
+		stdVolCap    = &csi.VolumeCapability{
+			AccessType: &csi.VolumeCapability_Mount{
+				Mount: &csi.VolumeCapability_MountVolume{},
+			},
+			AccessMode: &csi.VolumeCapability_AccessMode{
+				Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+			},
+		}
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: normal",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				ctx := context.Background()
+
+				fs := &cloud.FileSystem{}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
+
+				req := &csi.ValidateVolumeCapabilitiesRequest{
+					VolumeId: fileSystemId,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+				}
+
+				resp, err := driver.ValidateVolumeCapabilities(ctx, req)
+				if err != nil {
+					t.Fatalf("ControllerGetCapabilities is failed: %v", err)
+				}
+				if resp.Confirmed == nil {
+					t.Fatal("capability is not supported")
+				}
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: volume ID is missing",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				ctx := context.Background()
+				req := &csi.ValidateVolumeCapabilitiesRequest{
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+				}
+
+				_, err := driver.ValidateVolumeCapabilities(ctx, req)
+				if err == nil {
+					t.Fatal("ControllerGetCapabilities is not failed")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: volume capability is missing",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				ctx := context.Background()
+				req := &csi.ValidateVolumeCapabilitiesRequest{
+					VolumeId: fileSystemId,
+				}
+
+				_, err := driver.ValidateVolumeCapabilities(ctx, req)
+				if err == nil {
+					t.Fatal("ControllerGetCapabilities is not failed")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestCreateSnapshot(t *testing.T) {
+	var (
+		snapshotName        = "snapshotName"
+		backupId            = "backup-1234"
+		fileSystemId        = "fs-1234"
+		volumeSizeGiB int32 = 1200
+		extraTags           = "key1=value1,key2=value2"
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: normal",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{extraTags: extraTags},
+				}
+
+				req := &csi.CreateSnapshotRequest{
+					Name:           snapshotName,
+					SourceVolumeId: fileSystemId,
+					Parameters: map[string]string{
+						volumeParamsExtraTags: "key3=value3",
+					},
+				}
+
+				ctx := context.Background()
+				backup := &cloud.Backup{
+					BackupId:     backupId,
+					FileSystemId: fileSystemId,
+					CapacityGiB:  volumeSizeGiB,
+					Lifecycle:    backupLifecycleAvailable,
+				}
+				backupOptions := &cloud.BackupOptions{
+					FileSystemId: fileSystemId,
+					ExtraTags:    []string{"key1=value1", "key2=value2", "key3=value3"},
+				}
+				mockCloud.EXPECT().CreateBackup(gomock.Eq(ctx), gomock.Eq(snapshotName), gomock.Eq(backupOptions)).Return(backup, nil)
+
+				resp, err := driver.CreateSnapshot(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateSnapshot is failed: %v", err)
+				}
+
+				if resp.Snapshot == nil {
+					t.Fatal("resp.Snapshot is nil")
+				}
+
+				if resp.Snapshot.SnapshotId != backupId {
+					t.Fatalf("SnapshotId mismatches. actual: %v expected: %v", resp.Snapshot.SnapshotId, backupId)
+				}
+
+				if resp.Snapshot.SourceVolumeId != fileSystemId {
+					t.Fatalf("SourceVolumeId mismatches. actual: %v expected: %v", resp.Snapshot.SourceVolumeId, fileSystemId)
+				}
+
+				if resp.Snapshot.SizeBytes != util.GiBToBytes(volumeSizeGiB) {
+					t.Fatalf("SizeBytes mismatches. actual: %v expected: %v", resp.Snapshot.SizeBytes, util.GiBToBytes(volumeSizeGiB))
+				}
+
+				if !resp.Snapshot.ReadyToUse {
+					t.Fatal("ReadyToUse should be true")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: backup still creating",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateSnapshotRequest{
+					Name:           snapshotName,
+					SourceVolumeId: fileSystemId,
+				}
+
+				ctx := context.Background()
+				backup := &cloud.Backup{
+					BackupId:     backupId,
+					FileSystemId: fileSystemId,
+					Lifecycle:    "CREATING",
+				}
+				mockCloud.EXPECT().CreateBackup(gomock.Eq(ctx), gomock.Eq(snapshotName), gomock.Any()).Return(backup, nil)
+
+				resp, err := driver.CreateSnapshot(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateSnapshot is failed: %v", err)
+				}
+
+				if resp.Snapshot.ReadyToUse {
+					t.Fatal("ReadyToUse should be false")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: snapshot name missing",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateSnapshotRequest{
+					SourceVolumeId: fileSystemId,
+				}
+
+				ctx := context.Background()
+				_, err := driver.CreateSnapshot(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: source volume ID missing",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateSnapshotRequest{
+					Name: snapshotName,
+				}
+
+				ctx := context.Background()
+				_, err := driver.CreateSnapshot(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: source volume not found",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateSnapshotRequest{
+					Name:           snapshotName,
+					SourceVolumeId: fileSystemId,
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().CreateBackup(gomock.Eq(ctx), gomock.Eq(snapshotName), gomock.Any()).Return(nil, cloud.ErrNotFound)
+
+				_, err := driver.CreateSnapshot(ctx, req)
+				if status.Code(err) != codes.NotFound {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: snapshot exists with different source volume",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateSnapshotRequest{
+					Name:           snapshotName,
+					SourceVolumeId: fileSystemId,
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().CreateBackup(gomock.Eq(ctx), gomock.Eq(snapshotName), gomock.Any()).Return(nil, cloud.ErrBackupExistsDiffFs)
+
+				_, err := driver.CreateSnapshot(ctx, req)
+				if status.Code(err) != codes.AlreadyExists {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: backup failed",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateSnapshotRequest{
+					Name:           snapshotName,
+					SourceVolumeId: fileSystemId,
+				}
+
+				ctx := context.Background()
+				backup := &cloud.Backup{
+					BackupId:       backupId,
+					FileSystemId:   fileSystemId,
+					Lifecycle:      backupLifecycleFailed,
+					FailureMessage: "backup failed",
+				}
+				mockCloud.EXPECT().CreateBackup(gomock.Eq(ctx), gomock.Eq(snapshotName), gomock.Any()).Return(backup, nil)
+
+				_, err := driver.CreateSnapshot(ctx, req)
+				if status.Code(err) != codes.Internal {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestDeleteSnapshot(t *testing.T) {
+	var (
+		backupId = "backup-1234"
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: normal",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.DeleteSnapshotRequest{
+					SnapshotId: backupId,
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DeleteBackup(gomock.Eq(ctx), gomock.Eq(backupId)).Return(nil)
+				_, err := driver.DeleteSnapshot(ctx, req)
+				if err != nil {
+					t.Fatalf("DeleteSnapshot is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: snapshot ID is missing",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.DeleteSnapshotRequest{}
+
+				ctx := context.Background()
+				_, err := driver.DeleteSnapshot(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: DeleteBackup returns ErrNotFound",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.DeleteSnapshotRequest{
+					SnapshotId: backupId,
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DeleteBackup(gomock.Eq(ctx), gomock.Eq(backupId)).Return(cloud.ErrNotFound)
+				_, err := driver.DeleteSnapshot(ctx, req)
+				if err != nil {
+					t.Fatalf("DeleteSnapshot is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: DeleteBackup returns other error",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.DeleteSnapshotRequest{
+					SnapshotId: backupId,
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DeleteBackup(gomock.Eq(ctx), gomock.Eq(backupId)).Return(errors.New("DeleteBackup failed"))
+				_, err := driver.DeleteSnapshot(ctx, req)
+				if status.Code(err) != codes.Internal {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestListSnapshots(t *testing.T) {
+	var (
+		backupId     = "backup-1234"
+		fileSystemId = "fs-1234"
+		nextToken    = "token"
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: filter by snapshot ID",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.ListSnapshotsRequest{
+					SnapshotId: backupId,
+				}
+
+				ctx := context.Background()
+				backup := &cloud.Backup{
+					BackupId:     backupId,
+					FileSystemId: fileSystemId,
+					Lifecycle:    backupLifecycleAvailable,
+				}
+				mockCloud.EXPECT().DescribeBackup(gomock.Eq(ctx), gomock.Eq(backupId)).Return(backup, nil)
+				resp, err := driver.ListSnapshots(ctx, req)
+				if err != nil {
+					t.Fatalf("ListSnapshots is failed: %v", err)
+				}
+
+				if len(resp.Entries) != 1 {
+					t.Fatalf("Entries length mismatches. actual: %v expected: %v", len(resp.Entries), 1)
+				}
+
+				if resp.Entries[0].Snapshot.SnapshotId != backupId {
+					t.Fatalf("SnapshotId mismatches. actual: %v expected: %v", resp.Entries[0].Snapshot.SnapshotId, backupId)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: snapshot ID not found",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.ListSnapshotsRequest{
+					SnapshotId: backupId,
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeBackup(gomock.Eq(ctx), gomock.Eq(backupId)).Return(nil, cloud.ErrNotFound)
+				resp, err := driver.ListSnapshots(ctx, req)
+				if err != nil {
+					t.Fatalf("ListSnapshots is failed: %v", err)
+				}
+
+				if len(resp.Entries) != 0 {
+					t.Fatalf("Entries length mismatches. actual: %v expected: %v", len(resp.Entries), 0)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: snapshot ID with different source volume",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.ListSnapshotsRequest{
+					SnapshotId:     backupId,
+					SourceVolumeId: "fs-5678",
+				}
+
+				ctx := context.Background()
+				backup := &cloud.Backup{
+					BackupId:     backupId,
+					FileSystemId: fileSystemId,
+				}
+				mockCloud.EXPECT().DescribeBackup(gomock.Eq(ctx), gomock.Eq(backupId)).Return(backup, nil)
+				resp, err := driver.ListSnapshots(ctx, req)
+				if err != nil {
+					t.Fatalf("ListSnapshots is failed: %v", err)
+				}
+
+				if len(resp.Entries) != 0 {
+					t.Fatalf("Entries length mismatches. actual: %v expected: %v", len(resp.Entries), 0)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: paginated by source volume",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.ListSnapshotsRequest{
+					SourceVolumeId: fileSystemId,
+					MaxEntries:     2,
+					StartingToken:  nextToken,
+				}
+
+				ctx := context.Background()
+				backups := []*cloud.Backup{
+					{BackupId: "backup-1", FileSystemId: fileSystemId},
+					{BackupId: "backup-2", FileSystemId: fileSystemId},
+				}
+				mockCloud.EXPECT().ListBackups(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Eq(int32(2)), gomock.Eq(nextToken)).Return(backups, "next", nil)
+				resp, err := driver.ListSnapshots(ctx, req)
+				if err != nil {
+					t.Fatalf("ListSnapshots is failed: %v", err)
+				}
+
+				if len(resp.Entries) != 2 {
+					t.Fatalf("Entries length mismatches. actual: %v expected: %v", len(resp.Entries), 2)
+				}
+
+				if resp.NextToken != "next" {
+					t.Fatalf("NextToken mismatches. actual: %v expected: %v", resp.NextToken, "next")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: intermediate clone backups are left out",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.ListSnapshotsRequest{
+					SourceVolumeId: fileSystemId,
+				}
+
+				ctx := context.Background()
+				backups := []*cloud.Backup{
+					{BackupId: "backup-1", FileSystemId: fileSystemId, Tags: map[string]string{cloud.SnapshotNameTagKey: "snapshot-1"}},
+					{BackupId: "backup-2", FileSystemId: fileSystemId, Tags: map[string]string{cloud.CloneVolumeNameTagKey: "pvc-clone"}},
+				}
+				mockCloud.EXPECT().ListBackups(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Eq(int32(0)), gomock.Eq("")).Return(backups, "", nil)
+				resp, err := driver.ListSnapshots(ctx, req)
+				if err != nil {
+					t.Fatalf("ListSnapshots is failed: %v", err)
+				}
+
+				if len(resp.Entries) != 1 {
+					t.Fatalf("Entries length mismatches. actual: %v expected: %v", len(resp.Entries), 1)
+				}
+
+				if resp.Entries[0].Snapshot.SnapshotId != "backup-1" {
+					t.Fatalf("SnapshotId mismatches. actual: %v expected: %v", resp.Entries[0].Snapshot.SnapshotId, "backup-1")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: negative max entries",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.ListSnapshotsRequest{
+					MaxEntries: -1,
+				}
+
+				ctx := context.Background()
+				_, err := driver.ListSnapshots(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: ListBackups returns error",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.ListSnapshotsRequest{}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().ListBackups(gomock.Eq(ctx), gomock.Eq(""), gomock.Eq(int32(0)), gomock.Eq("")).Return(nil, "", errors.New("ListBackups failed"))
+				_, err := driver.ListSnapshots(ctx, req)
+				if status.Code(err) != codes.Internal {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestListVolumes(t *testing.T) {
+	var (
+		fileSystems = []*cloud.FileSystem{
+			{FileSystemId: "fs-1", CapacityGiB: 1200, DnsName: "fs-1.fsx.us-west-2.amazonaws.com", MountName: "mount1"},
+			{FileSystemId: "fs-2", CapacityGiB: 2400, DnsName: "fs-2.fsx.us-west-2.amazonaws.com", MountName: "mount2"},
+			{FileSystemId: "fs-3", CapacityGiB: 3600, DnsName: "fs-3.fsx.us-west-2.amazonaws.com", MountName: "mount3"},
+		}
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: all volumes",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().ListFileSystems(gomock.Eq(ctx)).Return(fileSystems, nil)
+				resp, err := driver.ListVolumes(ctx, &csi.ListVolumesRequest{})
+				if err != nil {
+					t.Fatalf("ListVolumes is failed: %v", err)
+				}
+
+				if len(resp.Entries) != 3 {
+					t.Fatalf("Entries length mismatches. actual: %v expected: %v", len(resp.Entries), 3)
+				}
+
+				if resp.NextToken != "" {
+					t.Fatalf("NextToken mismatches. actual: %v expected: %v", resp.NextToken, "")
+				}
+
+				volume := resp.Entries[1].Volume
+				if volume.VolumeId != "fs-2" {
+					t.Fatalf("VolumeId mismatches. actual: %v expected: %v", volume.VolumeId, "fs-2")
+				}
+
+				if volume.CapacityBytes != util.GiBToBytes(2400) {
+					t.Fatalf("CapacityBytes mismatches. actual: %v expected: %v", volume.CapacityBytes, util.GiBToBytes(2400))
+				}
+
+				if volume.VolumeContext[volumeContextDnsName] != "fs-2.fsx.us-west-2.amazonaws.com" {
+					t.Fatalf("dnsname mismatches. actual: %v expected: %v", volume.VolumeContext[volumeContextDnsName], "fs-2.fsx.us-west-2.amazonaws.com")
+				}
+
+				if volume.VolumeContext[volumeContextMountName] != "mount2" {
+					t.Fatalf("mountname mismatches. actual: %v expected: %v", volume.VolumeContext[volumeContextMountName], "mount2")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: paginated",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().ListFileSystems(gomock.Eq(ctx)).Return(fileSystems, nil).Times(2)
+				resp, err := driver.ListVolumes(ctx, &csi.ListVolumesRequest{MaxEntries: 2})
+				if err != nil {
+					t.Fatalf("ListVolumes is failed: %v", err)
+				}
+
+				if len(resp.Entries) != 2 {
+					t.Fatalf("Entries length mismatches. actual: %v expected: %v", len(resp.Entries), 2)
+				}
+
+				if resp.NextToken != "fs-2" {
+					t.Fatalf("NextToken mismatches. actual: %v expected: %v", resp.NextToken, "fs-2")
+				}
+
+				resp, err = driver.ListVolumes(ctx, &csi.ListVolumesRequest{MaxEntries: 2, StartingToken: resp.NextToken})
+				if err != nil {
+					t.Fatalf("ListVolumes is failed: %v", err)
+				}
+
+				if len(resp.Entries) != 1 || resp.Entries[0].Volume.VolumeId != "fs-3" {
+					t.Fatalf("Entries mismatches. actual: %v", resp.Entries)
+				}
+
+				if resp.NextToken != "" {
+					t.Fatalf("NextToken mismatches. actual: %v expected: %v", resp.NextToken, "")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: paginated while volumes are deleted",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				ctx := context.Background()
+				gomock.InOrder(
+					mockCloud.EXPECT().ListFileSystems(gomock.Eq(ctx)).Return(fileSystems, nil),
+					// the last volume of the first page is deleted before the second page is requested
+					mockCloud.EXPECT().ListFileSystems(gomock.Eq(ctx)).Return([]*cloud.FileSystem{fileSystems[0], fileSystems[2]}, nil),
+				)
+				resp, err := driver.ListVolumes(ctx, &csi.ListVolumesRequest{MaxEntries: 2})
+				if err != nil {
+					t.Fatalf("ListVolumes is failed: %v", err)
+				}
+
+				resp, err = driver.ListVolumes(ctx, &csi.ListVolumesRequest{MaxEntries: 2, StartingToken: resp.NextToken})
+				if err != nil {
+					t.Fatalf("ListVolumes is failed: %v", err)
+				}
+
+				if len(resp.Entries) != 1 || resp.Entries[0].Volume.VolumeId != "fs-3" {
+					t.Fatalf("Entries mismatches. actual: %v", resp.Entries)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: abnormal volume condition",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				ctx := context.Background()
+				misconfigured := []*cloud.FileSystem{
+					{FileSystemId: "fs-1", CapacityGiB: 1200, DnsName: "fs-1.fsx.us-west-2.amazonaws.com", MountName: "mount1", Lifecycle: "AVAILABLE"},
+					{FileSystemId: "fs-2", CapacityGiB: 2400, DnsName: "fs-2.fsx.us-west-2.amazonaws.com", MountName: "mount2", Lifecycle: "MISCONFIGURED", FailureMessage: "subnet deleted"},
+				}
+				mockCloud.EXPECT().ListFileSystems(gomock.Eq(ctx)).Return(misconfigured, nil)
+				resp, err := driver.ListVolumes(ctx, &csi.ListVolumesRequest{})
+				if err != nil {
+					t.Fatalf("ListVolumes is failed: %v", err)
+				}
+
+				if condition := resp.Entries[0].GetStatus().GetVolumeCondition(); condition == nil || condition.Abnormal {
+					t.Fatalf("VolumeCondition of fs-1 is not normal: %v", condition)
+				}
+
+				condition := resp.Entries[1].GetStatus().GetVolumeCondition()
+				if condition == nil || !condition.Abnormal {
+					t.Fatalf("VolumeCondition of fs-2 is not abnormal: %v", condition)
+				}
+
+				if !strings.Contains(condition.Message, "subnet deleted") {
+					t.Fatalf("VolumeCondition message %q does not contain %q", condition.Message, "subnet deleted")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: invalid starting token",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().ListFileSystems(gomock.Eq(ctx)).Return(fileSystems, nil).Times(2)
+				_, err := driver.ListVolumes(ctx, &csi.ListVolumesRequest{StartingToken: "invalid"})
+				if status.Code(err) != codes.Aborted {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				_, err = driver.ListVolumes(ctx, &csi.ListVolumesRequest{StartingToken: "4"})
+				if status.Code(err) != codes.Aborted {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: negative max entries",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				ctx := context.Background()
+				_, err := driver.ListVolumes(ctx, &csi.ListVolumesRequest{MaxEntries: -1})
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestControllerGetVolume(t *testing.T) {
+	var (
+		fileSystemId = "fs-1234"
+		dnsname      = "fs-1234.fsx.us-west-2.amazonaws.com"
+		mountName    = "random"
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: normal",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				fs := &cloud.FileSystem{
+					FileSystemId: fileSystemId,
+					CapacityGiB:  1200,
+					DnsName:      dnsname,
+					MountName:    mountName,
+					Lifecycle:    "AVAILABLE",
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
+				resp, err := driver.ControllerGetVolume(ctx, &csi.ControllerGetVolumeRequest{VolumeId: fileSystemId})
+				if err != nil {
+					t.Fatalf("ControllerGetVolume is failed: %v", err)
+				}
+
+				if resp.Volume.VolumeId != fileSystemId {
+					t.Fatalf("VolumeId mismatches. actual: %v expected: %v", resp.Volume.VolumeId, fileSystemId)
+				}
+
+				if resp.Volume.CapacityBytes != util.GiBToBytes(1200) {
+					t.Fatalf("CapacityBytes mismatches. actual: %v expected: %v", resp.Volume.CapacityBytes, util.GiBToBytes(1200))
+				}
+
+				if resp.Volume.VolumeContext[volumeContextDnsName] != dnsname {
+					t.Fatalf("dnsname mismatches. actual: %v expected: %v", resp.Volume.VolumeContext[volumeContextDnsName], dnsname)
+				}
+
+				if resp.Status.VolumeCondition.Abnormal {
+					t.Fatalf("VolumeCondition is abnormal: %v", resp.Status.VolumeCondition.Message)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: misconfigured filesystem is abnormal",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				fs := &cloud.FileSystem{
+					FileSystemId:   fileSystemId,
+					CapacityGiB:    1200,
+					DnsName:        dnsname,
+					MountName:      mountName,
+					Lifecycle:      "MISCONFIGURED",
+					FailureMessage: "S3 bucket is not accessible",
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
+				resp, err := driver.ControllerGetVolume(ctx, &csi.ControllerGetVolumeRequest{VolumeId: fileSystemId})
+				if err != nil {
+					t.Fatalf("ControllerGetVolume is failed: %v", err)
+				}
+
+				if !resp.Status.VolumeCondition.Abnormal {
+					t.Fatalf("VolumeCondition is not abnormal")
+				}
+
+				if !strings.Contains(resp.Status.VolumeCondition.Message, fs.FailureMessage) {
+					t.Fatalf("VolumeCondition message %q does not contain %q", resp.Status.VolumeCondition.Message, fs.FailureMessage)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: failed update is abnormal",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				fs := &cloud.FileSystem{
+					FileSystemId: fileSystemId,
+					CapacityGiB:  1200,
+					DnsName:      dnsname,
+					MountName:    mountName,
+					Lifecycle:    "UPDATING",
+					AdministrativeActions: []cloud.AdministrativeAction{
+						{
+							ActionType:     "FILE_SYSTEM_UPDATE",
+							Status:         "FAILED",
+							RequestTime:    time.Now(),
+							FailureMessage: "update failed",
+						},
+					},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
+				resp, err := driver.ControllerGetVolume(ctx, &csi.ControllerGetVolumeRequest{VolumeId: fileSystemId})
+				if err != nil {
+					t.Fatalf("ControllerGetVolume is failed: %v", err)
+				}
+
+				if !resp.Status.VolumeCondition.Abnormal {
+					t.Fatalf("VolumeCondition is not abnormal")
+				}
+
+				if !strings.Contains(resp.Status.VolumeCondition.Message, "update failed") {
+					t.Fatalf("VolumeCondition message %q does not contain %q", resp.Status.VolumeCondition.Message, "update failed")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: failed latest update of available filesystem is abnormal",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				now := time.Now()
+				fs := &cloud.FileSystem{
+					FileSystemId: fileSystemId,
+					CapacityGiB:  1200,
+					DnsName:      dnsname,
+					MountName:    mountName,
+					Lifecycle:    "AVAILABLE",
+					AdministrativeActions: []cloud.AdministrativeAction{
+						{
+							ActionType:  "FILE_SYSTEM_UPDATE",
+							Status:      "COMPLETED",
+							RequestTime: now.Add(-2 * time.Hour),
+						},
+						{
+							ActionType:     "FILE_SYSTEM_UPDATE",
+							Status:         "FAILED",
+							RequestTime:    now.Add(-time.Minute),
+							FailureMessage: "update failed",
+						},
+					},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
+				resp, err := driver.ControllerGetVolume(ctx, &csi.ControllerGetVolumeRequest{VolumeId: fileSystemId})
+				if err != nil {
+					t.Fatalf("ControllerGetVolume is failed: %v", err)
+				}
+
+				if !resp.Status.VolumeCondition.Abnormal {
+					t.Fatalf("VolumeCondition is not abnormal")
+				}
+
+				if !strings.Contains(resp.Status.VolumeCondition.Message, "update failed") {
+					t.Fatalf("VolumeCondition message %q does not contain %q", resp.Status.VolumeCondition.Message, "update failed")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: failed update superseded by a later update is normal",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				now := time.Now()
+				fs := &cloud.FileSystem{
+					FileSystemId: fileSystemId,
+					CapacityGiB:  1200,
+					DnsName:      dnsname,
+					MountName:    mountName,
+					Lifecycle:    "AVAILABLE",
+					AdministrativeActions: []cloud.AdministrativeAction{
+						{
+							ActionType:     "FILE_SYSTEM_UPDATE",
+							Status:         "FAILED",
+							RequestTime:    now.Add(-2 * time.Hour),
+							FailureMessage: "update failed",
+						},
+						{
+							ActionType:  "FILE_SYSTEM_UPDATE",
+							Status:      "COMPLETED",
+							RequestTime: now.Add(-time.Minute),
+						},
+					},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
+				resp, err := driver.ControllerGetVolume(ctx, &csi.ControllerGetVolumeRequest{VolumeId: fileSystemId})
+				if err != nil {
+					t.Fatalf("ControllerGetVolume is failed: %v", err)
+				}
+
+				if resp.Status.VolumeCondition.Abnormal {
+					t.Fatalf("VolumeCondition is abnormal: %s", resp.Status.VolumeCondition.Message)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: stuck update is abnormal",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				fs := &cloud.FileSystem{
+					FileSystemId: fileSystemId,
+					CapacityGiB:  1200,
+					DnsName:      dnsname,
+					MountName:    mountName,
+					Lifecycle:    "UPDATING",
+					AdministrativeActions: []cloud.AdministrativeAction{
+						{
+							ActionType:  "FILE_SYSTEM_UPDATE",
+							Status:      "IN_PROGRESS",
+							RequestTime: time.Now().Add(-2 * fileSystemUpdateStuckTimeout),
+						},
+					},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
+				resp, err := driver.ControllerGetVolume(ctx, &csi.ControllerGetVolumeRequest{VolumeId: fileSystemId})
+				if err != nil {
+					t.Fatalf("ControllerGetVolume is failed: %v", err)
+				}
+
+				if !resp.Status.VolumeCondition.Abnormal {
+					t.Fatalf("VolumeCondition is not abnormal")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: update in progress is normal",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				fs := &cloud.FileSystem{
+					FileSystemId: fileSystemId,
+					CapacityGiB:  1200,
+					DnsName:      dnsname,
+					MountName:    mountName,
+					Lifecycle:    "UPDATING",
+					AdministrativeActions: []cloud.AdministrativeAction{
+						{
+							ActionType:  "FILE_SYSTEM_UPDATE",
+							Status:      "IN_PROGRESS",
+							RequestTime: time.Now(),
+						},
+					},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
+				resp, err := driver.ControllerGetVolume(ctx, &csi.ControllerGetVolumeRequest{VolumeId: fileSystemId})
+				if err != nil {
+					t.Fatalf("ControllerGetVolume is failed: %v", err)
+				}
+
+				if resp.Status.VolumeCondition.Abnormal {
+					t.Fatalf("VolumeCondition is abnormal: %v", resp.Status.VolumeCondition.Message)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: volume not found",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil, cloud.ErrNotFound)
+				_, err := driver.ControllerGetVolume(ctx, &csi.ControllerGetVolumeRequest{VolumeId: fileSystemId})
+				if status.Code(err) != codes.NotFound {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: missing volume ID",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				ctx := context.Background()
+				_, err := driver.ControllerGetVolume(ctx, &csi.ControllerGetVolumeRequest{})
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestControllerModifyVolume(t *testing.T) {
+	var (
+		fileSystemId = "fs-1234"
+		fs           = &cloud.FileSystem{
+			FileSystemId:                  fileSystemId,
+			CapacityGiB:                   1200,
+			DeploymentType:                "PERSISTENT_2",
+			PerUnitStorageThroughput:      125,
+			DataCompressionType:           "NONE",
+			MetadataConfigurationMode:     "AUTOMATIC",
+			MetadataIops:                  1500,
+			AutomaticBackupRetentionDays:  7,
+			DailyAutomaticBackupStartTime: "01:00",
+			WeeklyMaintenanceStartTime:    "7:09:00",
+		}
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: normal",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.ControllerModifyVolumeRequest{
+					VolumeId: fileSystemId,
+					MutableParameters: map[string]string{
+						volumeParamsPerUnitStorageThroughput:  "250",
+						volumeParamsDataCompressionType:       "LZ4",
+						volumeParamsMetadataConfigurationMode: "USER_PROVISIONED",
+						volumeParamsMetadataIops:              "6000",
 					},
 				}
-
-				initialFs := &cloud.FileSystem{
-					FileSystemId:             fileSystemId,
-					CapacityGiB:              initialSizeGiB,
-					DnsName:                  "test.us-east-1.fsx.amazonaws.com",
-					MountName:                "random",
-					StorageType:              "SSD",
-					DeploymentType:           "SCRATCH_2",
-					PerUnitStorageThroughput: 0,
+				updateOptions := &cloud.FileSystemUpdateOptions{
+					PerUnitStorageThroughput:  250,
+					DataCompressionType:       "LZ4",
+					MetadataConfigurationMode: "USER_PROVISIONED",
+					MetadataIops:              6000,
 				}
 
-				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(initialFs, nil)
-				_, err := driver.ControllerExpandVolume(ctx, expandRequest)
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
+				mockCloud.EXPECT().UpdateFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Eq(updateOptions)).Return(nil)
+				mockCloud.EXPECT().WaitForFileSystemUpdate(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Eq(updateOptions)).Return(nil)
+
+				_, err := driver.ControllerModifyVolume(ctx, req)
 				if err != nil {
-					t.Fatalf("ControllerExpandVolume failed: %v", err)
+					t.Fatalf("ControllerModifyVolume is failed: %v", err)
 				}
 
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "failure: volume ID not provided",
+			name: "success: only changed parameters are updated",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -842,27 +4828,32 @@ func TestExpandVolume(t *testing.T) {
 					driverOptions: &DriverOptions{},
 				}
 
-				ctx := context.Background()
-				expandRequest := &csi.ControllerExpandVolumeRequest{
-					CapacityRange: &csi.CapacityRange{
-						RequiredBytes: util.GiBToBytes(1440),
+				req := &csi.ControllerModifyVolumeRequest{
+					VolumeId: fileSystemId,
+					MutableParameters: map[string]string{
+						volumeParamsPerUnitStorageThroughput: "125",
+						volumeParamsDataCompressionType:      "LZ4",
 					},
 				}
-				expandError := status.Error(codes.InvalidArgument, "Volume ID not provided")
-
-				_, err := driver.ControllerExpandVolume(ctx, expandRequest)
-				if err == nil {
-					t.Fatalf("ControllerExpandVolume did not return error, expected [%v]", expandError)
+				updateOptions := &cloud.FileSystemUpdateOptions{
+					DataCompressionType: "LZ4",
 				}
-				if err.Error() != expandError.Error() {
-					t.Fatalf("ControllerExpandVolume returned error [%v], expected [%v]", err, expandError)
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
+				mockCloud.EXPECT().UpdateFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Eq(updateOptions)).Return(nil)
+				mockCloud.EXPECT().WaitForFileSystemUpdate(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Eq(updateOptions)).Return(nil)
+
+				_, err := driver.ControllerModifyVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("ControllerModifyVolume is failed: %v", err)
 				}
 
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "failure: capacity range not provided",
+			name: "success: backup and maintenance settings",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -873,25 +4864,35 @@ func TestExpandVolume(t *testing.T) {
 					driverOptions: &DriverOptions{},
 				}
 
-				ctx := context.Background()
-				expandRequest := &csi.ControllerExpandVolumeRequest{
+				req := &csi.ControllerModifyVolumeRequest{
 					VolumeId: fileSystemId,
+					MutableParameters: map[string]string{
+						volumeParamsAutomaticBackupRetentionDays:  "30",
+						volumeParamsDailyAutomaticBackupStartTime: "01:00",
+						volumeParamsWeeklyMaintenanceStartTime:    "1:02:00",
+					},
 				}
-				expandError := status.Error(codes.InvalidArgument, "Capacity range not provided")
-
-				_, err := driver.ControllerExpandVolume(ctx, expandRequest)
-				if err == nil {
-					t.Fatalf("ControllerExpandVolume did not return error, expected [%v]", expandError)
+				retentionDays := int32(30)
+				updateOptions := &cloud.FileSystemUpdateOptions{
+					AutomaticBackupRetentionDays: &retentionDays,
+					WeeklyMaintenanceStartTime:   "1:02:00",
 				}
-				if err.Error() != expandError.Error() {
-					t.Fatalf("ControllerExpandVolume returned error [%v], expected [%v]", err, expandError)
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
+				mockCloud.EXPECT().UpdateFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Eq(updateOptions)).Return(nil)
+				mockCloud.EXPECT().WaitForFileSystemUpdate(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Eq(updateOptions)).Return(nil)
+
+				_, err := driver.ControllerModifyVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("ControllerModifyVolume is failed: %v", err)
 				}
 
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "failure: DescribeFileSystems not successful, filesystem not found",
+			name: "success: disable automatic backups",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -902,29 +4903,32 @@ func TestExpandVolume(t *testing.T) {
 					driverOptions: &DriverOptions{},
 				}
 
-				ctx := context.Background()
-				expandRequest := &csi.ControllerExpandVolumeRequest{
+				req := &csi.ControllerModifyVolumeRequest{
 					VolumeId: fileSystemId,
-					CapacityRange: &csi.CapacityRange{
-						RequiredBytes: util.GiBToBytes(1440),
+					MutableParameters: map[string]string{
+						volumeParamsAutomaticBackupRetentionDays: "0",
 					},
 				}
-				expandError := status.Errorf(codes.NotFound, "Filesystem not found: %v", cloud.ErrNotFound)
-
-				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil, cloud.ErrNotFound)
-				_, err := driver.ControllerExpandVolume(ctx, expandRequest)
-				if err == nil {
-					t.Fatalf("ControllerExpandVolume did not return error, expected [%v]", expandError)
+				retentionDays := int32(0)
+				updateOptions := &cloud.FileSystemUpdateOptions{
+					AutomaticBackupRetentionDays: &retentionDays,
 				}
-				if err.Error() != expandError.Error() {
-					t.Fatalf("ControllerExpandVolume returned error [%v], expected [%v]", err, expandError)
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
+				mockCloud.EXPECT().UpdateFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Eq(updateOptions)).Return(nil)
+				mockCloud.EXPECT().WaitForFileSystemUpdate(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Eq(updateOptions)).Return(nil)
+
+				_, err := driver.ControllerModifyVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("ControllerModifyVolume is failed: %v", err)
 				}
 
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "failure: DescribeFileSystems not successful, other error",
+			name: "fail: copyTagsToBackups cannot be modified",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -935,29 +4939,24 @@ func TestExpandVolume(t *testing.T) {
 					driverOptions: &DriverOptions{},
 				}
 
-				ctx := context.Background()
-				expandRequest := &csi.ControllerExpandVolumeRequest{
+				req := &csi.ControllerModifyVolumeRequest{
 					VolumeId: fileSystemId,
-					CapacityRange: &csi.CapacityRange{
-						RequiredBytes: util.GiBToBytes(1440),
+					MutableParameters: map[string]string{
+						volumeParamsCopyTagsToBackups: "true",
 					},
 				}
-				expandError := status.Errorf(codes.Internal, "DescribeFileSystem failed: %v", cloud.ErrMultiFileSystems)
 
-				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil, cloud.ErrMultiFileSystems)
-				_, err := driver.ControllerExpandVolume(ctx, expandRequest)
-				if err == nil {
-					t.Fatalf("ControllerExpandVolume did not return error, expected [%v]", expandError)
-				}
-				if err.Error() != expandError.Error() {
-					t.Fatalf("ControllerExpandVolume returned error [%v], expected [%v]", err, expandError)
+				ctx := context.Background()
+				_, err := driver.ControllerModifyVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
 				}
 
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "failure: capacity limit exceeds required bytes not successful",
+			name: "fail: invalid automaticBackupRetentionDays",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -968,42 +4967,24 @@ func TestExpandVolume(t *testing.T) {
 					driverOptions: &DriverOptions{},
 				}
 
-				ctx := context.Background()
-				expandRequest := &csi.ControllerExpandVolumeRequest{
+				req := &csi.ControllerModifyVolumeRequest{
 					VolumeId: fileSystemId,
-					CapacityRange: &csi.CapacityRange{
-						RequiredBytes: util.GiBToBytes(1440),
-						LimitBytes:    util.GiBToBytes(2000),
+					MutableParameters: map[string]string{
+						volumeParamsAutomaticBackupRetentionDays: "week",
 					},
 				}
-				expandError := status.Errorf(codes.OutOfRange, "Requested storage capacity of %d bytes exceeds capacity limit of %d bytes.",
-					util.GiBToBytes(finalSizeGiB),
-					expandRequest.GetCapacityRange().GetLimitBytes())
-
-				initialFs := &cloud.FileSystem{
-					FileSystemId:             fileSystemId,
-					CapacityGiB:              initialSizeGiB,
-					DnsName:                  "test.us-east-1.fsx.amazonaws.com",
-					MountName:                "random",
-					StorageType:              "SSD",
-					DeploymentType:           "SCRATCH_2",
-					PerUnitStorageThroughput: 0,
-				}
 
-				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(initialFs, nil)
-				_, err := driver.ControllerExpandVolume(ctx, expandRequest)
-				if err == nil {
-					t.Fatalf("ControllerExpandVolume did not return error, expected [%v]", expandError)
-				}
-				if err.Error() != expandError.Error() {
-					t.Fatalf("ControllerExpandVolume returned error [%v], expected [%v]", err, expandError)
+				ctx := context.Background()
+				_, err := driver.ControllerModifyVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
 				}
 
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "failure: ResizeFileSystem failed",
+			name: "success: filesystem already matches",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -1014,41 +4995,27 @@ func TestExpandVolume(t *testing.T) {
 					driverOptions: &DriverOptions{},
 				}
 
-				ctx := context.Background()
-				expandRequest := &csi.ControllerExpandVolumeRequest{
+				req := &csi.ControllerModifyVolumeRequest{
 					VolumeId: fileSystemId,
-					CapacityRange: &csi.CapacityRange{
-						RequiredBytes: util.GiBToBytes(1440),
+					MutableParameters: map[string]string{
+						volumeParamsPerUnitStorageThroughput:  "125",
+						volumeParamsMetadataConfigurationMode: "AUTOMATIC",
 					},
 				}
-				resizeError := fmt.Errorf("UpdateFileSystem failed: %v", &types.BadRequest{Message: aws.String("test")})
-				expandError := status.Errorf(codes.Internal, "resize failed: %v", resizeError)
 
-				initialFs := &cloud.FileSystem{
-					FileSystemId:             fileSystemId,
-					CapacityGiB:              initialSizeGiB,
-					DnsName:                  "test.us-east-1.fsx.amazonaws.com",
-					MountName:                "random",
-					StorageType:              "SSD",
-					DeploymentType:           "SCRATCH_2",
-					PerUnitStorageThroughput: 0,
-				}
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
 
-				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(initialFs, nil)
-				mockCloud.EXPECT().ResizeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Eq(finalSizeGiB)).Return(initialSizeGiB, resizeError)
-				_, err := driver.ControllerExpandVolume(ctx, expandRequest)
-				if err == nil {
-					t.Fatalf("ControllerExpandVolume did not return error, expected [%v]", expandError)
-				}
-				if err.Error() != expandError.Error() {
-					t.Fatalf("ControllerExpandVolume returned error [%v], expected [%v]", err, expandError)
+				_, err := driver.ControllerModifyVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("ControllerModifyVolume is failed: %v", err)
 				}
 
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "failure: WaitForFileSystemResize failed",
+			name: "fail: missing volume ID",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -1059,83 +5026,51 @@ func TestExpandVolume(t *testing.T) {
 					driverOptions: &DriverOptions{},
 				}
 
-				ctx := context.Background()
-				expandRequest := &csi.ControllerExpandVolumeRequest{
-					VolumeId: fileSystemId,
-					CapacityRange: &csi.CapacityRange{
-						RequiredBytes: util.GiBToBytes(1440),
+				req := &csi.ControllerModifyVolumeRequest{
+					MutableParameters: map[string]string{
+						volumeParamsDataCompressionType: "LZ4",
 					},
 				}
-				waitError := fmt.Errorf("update failed for filesystem %s: test", fileSystemId)
-				expandError := status.Errorf(codes.Internal, "filesystem is not resized: %v", waitError)
-
-				initialFs := &cloud.FileSystem{
-					FileSystemId:             fileSystemId,
-					CapacityGiB:              initialSizeGiB,
-					DnsName:                  "test.us-east-1.fsx.amazonaws.com",
-					MountName:                "random",
-					StorageType:              "SSD",
-					DeploymentType:           "SCRATCH_2",
-					PerUnitStorageThroughput: 0,
-				}
 
-				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(initialFs, nil)
-				mockCloud.EXPECT().ResizeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Eq(finalSizeGiB)).Return(finalSizeGiB, nil)
-				mockCloud.EXPECT().WaitForFileSystemResize(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Eq(finalSizeGiB)).Return(waitError)
-				_, err := driver.ControllerExpandVolume(ctx, expandRequest)
-				if err == nil {
-					t.Fatalf("ControllerExpandVolume did not return error, expected [%v]", expandError)
-				}
-				if err.Error() != expandError.Error() {
-					t.Fatalf("ControllerExpandVolume returned error [%v], expected [%v]", err, expandError)
+				ctx := context.Background()
+				_, err := driver.ControllerModifyVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
 				}
 
 				mockCtl.Finish()
 			},
 		},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.name, tc.testFunc)
-	}
-}
-
-func TestControllerGetCapabilities(t *testing.T) {
-	mockCtl := gomock.NewController(t)
-	mockCloud := mocks.NewMockCloud(mockCtl)
+		{
+			name: "fail: parameter cannot be modified",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
 
-	driver := controllerService{
-		cloud:         mockCloud,
-		inFlight:      internal.NewInFlight(),
-		driverOptions: &DriverOptions{},
-	}
+				driver := controllerService{
+					cloud:         mockCloud,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
 
-	ctx := context.Background()
-	_, err := driver.ControllerGetCapabilities(ctx, &csi.ControllerGetCapabilitiesRequest{})
-	if err != nil {
-		t.Fatalf("ControllerGetCapabilities is failed: %v", err)
-	}
-}
+				req := &csi.ControllerModifyVolumeRequest{
+					VolumeId: fileSystemId,
+					MutableParameters: map[string]string{
+						volumeParamsDeploymentType: "PERSISTENT_1",
+					},
+				}
 
-func TestValidateVolumeCapabilities(t *testing.T) {
+				ctx := context.Background()
+				_, err := driver.ControllerModifyVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
 
-	var (
-		fileSystemId = "fs-12345"
-		stdVolCap    = &csi.VolumeCapability{
-			AccessType: &csi.VolumeCapability_Mount{
-				Mount: &csi.VolumeCapability_MountVolume{},
-			},
-			AccessMode: &csi.VolumeCapability_AccessMode{
-				Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+				mockCtl.Finish()
 			},
-		}
-	)
-	testCases := []struct {
-		name     string
-		testFunc func(t *testing.T)
-	}{
+		},
 		{
-			name: "success: normal",
+			name: "fail: invalid perUnitStorageThroughput",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -1146,30 +5081,24 @@ func TestValidateVolumeCapabilities(t *testing.T) {
 					driverOptions: &DriverOptions{},
 				}
 
-				ctx := context.Background()
-
-				fs := &cloud.FileSystem{}
-				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
-
-				req := &csi.ValidateVolumeCapabilitiesRequest{
+				req := &csi.ControllerModifyVolumeRequest{
 					VolumeId: fileSystemId,
-					VolumeCapabilities: []*csi.VolumeCapability{
-						stdVolCap,
+					MutableParameters: map[string]string{
+						volumeParamsPerUnitStorageThroughput: "fast",
 					},
 				}
 
-				resp, err := driver.ValidateVolumeCapabilities(ctx, req)
-				if err != nil {
-					t.Fatalf("ControllerGetCapabilities is failed: %v", err)
-				}
-				if resp.Confirmed == nil {
-					t.Fatal("capability is not supported")
+				ctx := context.Background()
+				_, err := driver.ControllerModifyVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
 				}
+
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "fail: volume ID is missing",
+			name: "fail: volume not found",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -1180,23 +5109,26 @@ func TestValidateVolumeCapabilities(t *testing.T) {
 					driverOptions: &DriverOptions{},
 				}
 
-				ctx := context.Background()
-				req := &csi.ValidateVolumeCapabilitiesRequest{
-					VolumeCapabilities: []*csi.VolumeCapability{
-						stdVolCap,
+				req := &csi.ControllerModifyVolumeRequest{
+					VolumeId: fileSystemId,
+					MutableParameters: map[string]string{
+						volumeParamsDataCompressionType: "LZ4",
 					},
 				}
 
-				_, err := driver.ValidateVolumeCapabilities(ctx, req)
-				if err == nil {
-					t.Fatal("ControllerGetCapabilities is not failed")
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil, cloud.ErrNotFound)
+
+				_, err := driver.ControllerModifyVolume(ctx, req)
+				if status.Code(err) != codes.NotFound {
+					t.Fatalf("Unexpected error: %v", err)
 				}
 
 				mockCtl.Finish()
 			},
 		},
 		{
-			name: "fail: volume capability is missing",
+			name: "fail: update failed",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				mockCloud := mocks.NewMockCloud(mockCtl)
@@ -1207,20 +5139,28 @@ func TestValidateVolumeCapabilities(t *testing.T) {
 					driverOptions: &DriverOptions{},
 				}
 
-				ctx := context.Background()
-				req := &csi.ValidateVolumeCapabilitiesRequest{
+				req := &csi.ControllerModifyVolumeRequest{
 					VolumeId: fileSystemId,
+					MutableParameters: map[string]string{
+						volumeParamsDataCompressionType: "LZ4",
+					},
 				}
 
-				_, err := driver.ValidateVolumeCapabilities(ctx, req)
-				if err == nil {
-					t.Fatal("ControllerGetCapabilities is not failed")
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
+				mockCloud.EXPECT().UpdateFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Any()).Return(nil)
+				mockCloud.EXPECT().WaitForFileSystemUpdate(gomock.Eq(ctx), gomock.Eq(fileSystemId), gomock.Any()).Return(errors.New("update failed"))
+
+				_, err := driver.ControllerModifyVolume(ctx, req)
+				if status.Code(err) != codes.Internal {
+					t.Fatalf("Unexpected error: %v", err)
 				}
 
 				mockCtl.Finish()
 			},
 		},
 	}
+
 	for _, tc := range testCases {
 		t.Run(tc.name, tc.testFunc)
 	}
@@ -20,9 +20,11 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"time"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	"google.golang.org/grpc"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/aws-fsx-csi-driver/pkg/util"
 )
@@ -41,6 +43,10 @@ const (
 
 const (
 	DriverName = "fsx.csi.aws.com"
+	// TopologyKey is the well-known node label of the Availability Zone that volumes are accessible from
+	TopologyKey = "topology.kubernetes.io/zone"
+	// securityGroupGCInterval is the interval to delete unused cluster security groups
+	securityGroupGCInterval = 10 * time.Minute
 )
 
 type Driver struct {
@@ -53,37 +59,95 @@ type Driver struct {
 }
 
 type DriverOptions struct {
-	endpoint  string
-	mode      string
-	extraTags string
+	endpoint             string
+	mode                 string
+	extraTags            string
+	clusterName          string
+	nodeSecurityGroupIds []string
+	createSecurityGroup  bool
+	// subdirectoryDeletePolicy controls what DeleteVolume does with the subdirectory of a subdirectory volume
+	subdirectoryDeletePolicy string
+	// mountTimeout bounds the Lustre mounts of the node, 0 disables the timeout
+	mountTimeout time.Duration
+	// mountHealthCheckInterval is the interval to probe the Lustre mounts of the node, 0 disables the check
+	mountHealthCheckInterval time.Duration
+	// mountRecovery controls how the mount health check recovers the Lustre mounts of an evicted client
+	mountRecovery string
+	// unmountPolicy controls how far the node escalates unmounts that fail or time out
+	unmountPolicy string
+	// unmountTimeout bounds each attempt to unmount a target before it is escalated
+	unmountTimeout time.Duration
+	// orphanedMountCleanup controls whether the Lustre mounts of deleted pods are unmounted or only reported
+	orphanedMountCleanup string
+	// orphanedMountCleanupInterval is the interval to clean up orphaned mounts after startup, 0 only cleans up at startup
+	orphanedMountCleanupInterval time.Duration
+	// kubeletPath is the root directory of the kubelet
+	kubeletPath string
 }
 
 func NewDriver(options ...func(*DriverOptions)) (*Driver, error) {
 	klog.InfoS("Driver Information", "Driver", DriverName, "Version", driverVersion)
 
 	driverOptions := DriverOptions{
-		endpoint: DefaultCSIEndpoint,
-		mode:     AllMode,
+		endpoint:                 DefaultCSIEndpoint,
+		mode:                     AllMode,
+		subdirectoryDeletePolicy: SubdirectoryDeletePolicyDelete,
+		mountRecovery:            MountRecoveryNone,
+		unmountPolicy:            UnmountPolicyNone,
+		orphanedMountCleanup:     OrphanedMountCleanupDisabled,
+		kubeletPath:              DefaultKubeletPath,
 	}
 	for _, option := range options {
 		option(&driverOptions)
 	}
 
+	if driverOptions.createSecurityGroup && (driverOptions.clusterName == "" || len(driverOptions.nodeSecurityGroupIds) == 0) {
+		return nil, fmt.Errorf("cluster name and node security group IDs are required to create the cluster security group")
+	}
+
+	switch driverOptions.subdirectoryDeletePolicy {
+	case SubdirectoryDeletePolicyDelete, SubdirectoryDeletePolicyArchive, SubdirectoryDeletePolicyRetain:
+	default:
+		return nil, fmt.Errorf("unknown subdirectory delete policy: %s", driverOptions.subdirectoryDeletePolicy)
+	}
+
+	switch driverOptions.mountRecovery {
+	case MountRecoveryNone, MountRecoveryLctl, MountRecoveryRemount:
+	default:
+		return nil, fmt.Errorf("unknown mount recovery: %s", driverOptions.mountRecovery)
+	}
+
+	switch driverOptions.unmountPolicy {
+	case UnmountPolicyNone, UnmountPolicyForce, UnmountPolicyLazy:
+	default:
+		return nil, fmt.Errorf("unknown unmount policy: %s", driverOptions.unmountPolicy)
+	}
+
+	switch driverOptions.orphanedMountCleanup {
+	case OrphanedMountCleanupDisabled, OrphanedMountCleanupDryRun, OrphanedMountCleanupEnabled:
+	default:
+		return nil, fmt.Errorf("unknown orphaned mount cleanup: %s", driverOptions.orphanedMountCleanup)
+	}
+
 	driver := Driver{
 		options: &driverOptions,
 	}
 
+	var err error
 	switch driverOptions.mode {
 	case ControllerMode:
 		driver.controllerService = newControllerService(&driverOptions)
 	case NodeMode:
-		driver.nodeService = newNodeService(&driverOptions)
+		driver.nodeService, err = newNodeService(&driverOptions)
 	case AllMode:
 		driver.controllerService = newControllerService(&driverOptions)
-		driver.nodeService = newNodeService(&driverOptions)
+		driver.nodeService, err = newNodeService(&driverOptions)
 	default:
 		return nil, fmt.Errorf("unknown mode: %s", driverOptions.mode)
 	}
+	if err != nil {
+		return nil, err
+	}
 
 	return &driver, nil
 }
@@ -125,6 +189,23 @@ func (d *Driver) Run() error {
 		return fmt.Errorf("unknown mode: %s", d.options.mode)
 	}
 
+	if d.options.createSecurityGroup && d.options.mode != NodeMode {
+		go wait.Until(d.garbageCollectSecurityGroups, securityGroupGCInterval, wait.NeverStop)
+	}
+
+	if d.options.mountHealthCheckInterval > 0 && d.options.mode != ControllerMode {
+		go wait.Until(d.checkMountHealth, d.options.mountHealthCheckInterval, wait.NeverStop)
+	}
+
+	cleanup := d.options.orphanedMountCleanup
+	if (cleanup == OrphanedMountCleanupDryRun || cleanup == OrphanedMountCleanupEnabled) && d.options.mode != ControllerMode {
+		if d.options.orphanedMountCleanupInterval > 0 {
+			go wait.Until(d.cleanupOrphanedMounts, d.options.orphanedMountCleanupInterval, wait.NeverStop)
+		} else {
+			go d.cleanupOrphanedMounts()
+		}
+	}
+
 	klog.V(4).InfoS("Listening for connections", "address", listener.Addr())
 	return d.srv.Serve(listener)
 }
@@ -151,3 +232,75 @@ func WithExtraTags(extraTags string) func(*DriverOptions) {
 		o.extraTags = extraTags
 	}
 }
+
+func WithClusterName(clusterName string) func(*DriverOptions) {
+	return func(o *DriverOptions) {
+		o.clusterName = clusterName
+	}
+}
+
+func WithNodeSecurityGroupIds(nodeSecurityGroupIds []string) func(*DriverOptions) {
+	return func(o *DriverOptions) {
+		o.nodeSecurityGroupIds = nodeSecurityGroupIds
+	}
+}
+
+func WithCreateSecurityGroup(createSecurityGroup bool) func(*DriverOptions) {
+	return func(o *DriverOptions) {
+		o.createSecurityGroup = createSecurityGroup
+	}
+}
+
+func WithMountTimeout(mountTimeout time.Duration) func(*DriverOptions) {
+	return func(o *DriverOptions) {
+		o.mountTimeout = mountTimeout
+	}
+}
+
+func WithMountHealthCheckInterval(mountHealthCheckInterval time.Duration) func(*DriverOptions) {
+	return func(o *DriverOptions) {
+		o.mountHealthCheckInterval = mountHealthCheckInterval
+	}
+}
+
+func WithMountRecovery(mountRecovery string) func(*DriverOptions) {
+	return func(o *DriverOptions) {
+		o.mountRecovery = mountRecovery
+	}
+}
+
+func WithUnmountPolicy(unmountPolicy string) func(*DriverOptions) {
+	return func(o *DriverOptions) {
+		o.unmountPolicy = unmountPolicy
+	}
+}
+
+func WithUnmountTimeout(unmountTimeout time.Duration) func(*DriverOptions) {
+	return func(o *DriverOptions) {
+		o.unmountTimeout = unmountTimeout
+	}
+}
+
+func WithOrphanedMountCleanup(orphanedMountCleanup string) func(*DriverOptions) {
+	return func(o *DriverOptions) {
+		o.orphanedMountCleanup = orphanedMountCleanup
+	}
+}
+
+func WithOrphanedMountCleanupInterval(orphanedMountCleanupInterval time.Duration) func(*DriverOptions) {
+	return func(o *DriverOptions) {
+		o.orphanedMountCleanupInterval = orphanedMountCleanupInterval
+	}
+}
+
+func WithKubeletPath(kubeletPath string) func(*DriverOptions) {
+	return func(o *DriverOptions) {
+		o.kubeletPath = kubeletPath
+	}
+}
+
+func WithSubdirectoryDeletePolicy(subdirectoryDeletePolicy string) func(*DriverOptions) {
+	return func(o *DriverOptions) {
+		o.subdirectoryDeletePolicy = subdirectoryDeletePolicy
+	}
+}
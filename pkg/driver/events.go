@@ -0,0 +1,132 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"k8s.io/mount-utils"
+	"sigs.k8s.io/aws-fsx-csi-driver/pkg/cloud"
+)
+
+// newEventRecorder returns a recorder that records events in the Kubernetes API. When the Kubernetes API is not
+// reachable, e.g. when the driver runs outside of a cluster, the returned recorder drops all events.
+func newEventRecorder(k8sClient cloud.KubernetesAPIClient) (kubernetes.Interface, record.EventRecorder) {
+	clientset, err := k8sClient()
+	if err != nil {
+		klog.InfoS("Could not create Kubernetes client, events are not recorded", "err", err)
+		return nil, &record.FakeRecorder{}
+	}
+
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartStructuredLogging(4)
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: clientset.CoreV1().Events("")})
+	return clientset, broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: DriverName})
+}
+
+// recordPersistentVolumeEvent records an event on the PersistentVolume with the given name.
+func (d *controllerService) recordPersistentVolumeEvent(ctx context.Context, pvName string, eventType string, reason string, messageFmt string, args ...interface{}) {
+	if d.recorder == nil || pvName == "" {
+		return
+	}
+
+	ref := &corev1.ObjectReference{
+		APIVersion: "v1",
+		Kind:       "PersistentVolume",
+		Name:       pvName,
+	}
+	if d.kubeClient != nil {
+		pv, err := d.kubeClient.CoreV1().PersistentVolumes().Get(ctx, pvName, metav1.GetOptions{})
+		if err != nil {
+			klog.V(4).InfoS("Could not get PersistentVolume, recording event by name", "pv", pvName, "err", err)
+		} else {
+			ref.UID = pv.UID
+		}
+	}
+	d.recorder.Eventf(ref, eventType, reason, messageFmt, args...)
+}
+
+// listNodePods returns the pods on the node by UID, or nil when the Kubernetes API is not reachable
+func (d *nodeService) listNodePods(ctx context.Context) map[types.UID]*corev1.Pod {
+	if d.kubeClient == nil || d.nodeName == "" {
+		return nil
+	}
+	pods, err := d.kubeClient.CoreV1().Pods("").List(ctx, metav1.ListOptions{FieldSelector: "spec.nodeName=" + d.nodeName})
+	if err != nil {
+		klog.V(4).InfoS("Could not list pods of node, events are not recorded on pods", "node", d.nodeName, "err", err)
+		return nil
+	}
+	podsByUID := make(map[types.UID]*corev1.Pod, len(pods.Items))
+	for i := range pods.Items {
+		podsByUID[pods.Items[i].UID] = &pods.Items[i]
+	}
+	return podsByUID
+}
+
+// recordPodEvents records an event on each pod that one of the mounts is published to.
+func (d *nodeService) recordPodEvents(pods map[types.UID]*corev1.Pod, mounts []mount.MountPoint, eventType string, reason string, messageFmt string, args ...interface{}) {
+	if d.recorder == nil {
+		return
+	}
+	for _, mountPoint := range mounts {
+		match := podVolumeMountPattern.FindStringSubmatch(mountPoint.Path)
+		if match == nil {
+			continue
+		}
+		pod, ok := pods[types.UID(match[1])]
+		if !ok {
+			klog.V(4).InfoS("Could not find pod of mount, recording no event", "path", mountPoint.Path, "reason", reason)
+			continue
+		}
+		d.recorder.Eventf(pod, eventType, reason, messageFmt, args...)
+	}
+}
+
+// recordTargetEvent records an event on the pod that target is published to, or on the node for other targets such as
+// staging target paths.
+func (d *nodeService) recordTargetEvent(target string, eventType string, reason string, messageFmt string, args ...interface{}) {
+	if d.recorder == nil {
+		return
+	}
+	if podVolumeMountPattern.MatchString(target) {
+		d.recordPodEvents(d.listNodePods(context.Background()), []mount.MountPoint{{Path: target}}, eventType, reason, messageFmt, args...)
+		return
+	}
+	d.recordNodeEvent(eventType, reason, messageFmt, args...)
+}
+
+// recordNodeEvent records an event on the node.
+func (d *nodeService) recordNodeEvent(eventType string, reason string, messageFmt string, args ...interface{}) {
+	if d.recorder == nil || d.nodeName == "" {
+		return
+	}
+	// the kubelet records node events with the node name as UID
+	ref := &corev1.ObjectReference{
+		Kind: "Node",
+		Name: d.nodeName,
+		UID:  types.UID(d.nodeName),
+	}
+	d.recorder.Eventf(ref, eventType, reason, messageFmt, args...)
+}
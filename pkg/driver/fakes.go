@@ -17,19 +17,36 @@ limitations under the License.
 package driver
 
 import (
+	"context"
+
 	"k8s.io/mount-utils"
 	"sigs.k8s.io/aws-fsx-csi-driver/pkg/cloud"
 	"sigs.k8s.io/aws-fsx-csi-driver/pkg/driver/internal"
 )
 
+// fakeMounter mounts with the fake mounter of mount-utils, which completes mounts immediately
+type fakeMounter struct {
+	NodeMounter
+}
+
 func NewFakeMounter() Mounter {
-	return &NodeMounter{
-		Interface: &mount.FakeMounter{
-			MountPoints: []mount.MountPoint{},
+	return &fakeMounter{
+		NodeMounter: NodeMounter{
+			Interface: &mount.FakeMounter{
+				MountPoints: []mount.MountPoint{},
+			},
 		},
 	}
 }
 
+func (m *fakeMounter) MountWithContext(_ context.Context, source string, target string, fstype string, options []string) error {
+	return m.Mount(source, target, fstype, options)
+}
+
+func (m *fakeMounter) UnmountWithContext(_ context.Context, target string, _ []string) error {
+	return m.Unmount(target)
+}
+
 // NewFakeDriver creates a new mock driver used for testing
 func NewFakeDriver(endpoint string) *Driver {
 	driverOptions := DriverOptions{
@@ -45,6 +62,11 @@ func NewFakeDriver(endpoint string) *Driver {
 			driverOptions: &driverOptions,
 		},
 		nodeService: nodeService{
+			metadata: &cloud.Metadata{
+				InstanceID:       "i-1234567890abcdef0",
+				Region:           "us-east-1",
+				AvailabilityZone: cloud.FakeAvailabilityZone,
+			},
 			mounter:       NewFakeMounter(),
 			inFlight:      internal.NewInFlight(),
 			driverOptions: &DriverOptions{},
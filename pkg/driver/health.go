@@ -0,0 +1,333 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/mount-utils"
+)
+
+// Mount recoveries, which control what the mount health check does with the Lustre mounts of an evicted client
+const (
+	MountRecoveryNone    = "none"
+	MountRecoveryLctl    = "lctl"
+	MountRecoveryRemount = "remount"
+)
+
+// mountProbeTimeout bounds a probe of a Lustre mount, a statfs of the mount of an evicted client may block until the
+// client reconnects
+var mountProbeTimeout = 30 * time.Second
+
+// podVolumeMountPattern matches the target paths that the kubelet publishes CSI volumes of pods at and captures the pod
+// UID
+var podVolumeMountPattern = regexp.MustCompile(`/pods/([^/]+)/volumes/kubernetes\.io~csi/[^/]+/mount$`)
+
+// stagingMountPattern matches the staging target paths that the kubelet stages CSI volumes at
+var stagingMountPattern = regexp.MustCompile(`/plugins/kubernetes\.io/csi/.+/globalmount$`)
+
+// mountHealth holds the Lustre mounts that the last mount health check found unhealthy and why, keyed by path, and
+// the paths whose probe has not returned yet
+type mountHealth struct {
+	mutex     sync.Mutex
+	unhealthy map[string]string
+	probing   map[string]bool
+}
+
+func newMountHealth() *mountHealth {
+	return &mountHealth{
+		unhealthy: map[string]string{},
+		probing:   map[string]bool{},
+	}
+}
+
+// get returns why the mount at path is unhealthy. A nil mountHealth reports all mounts as healthy.
+func (h *mountHealth) get(path string) (string, bool) {
+	if h == nil {
+		return "", false
+	}
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	message, ok := h.unhealthy[path]
+	return message, ok
+}
+
+func (h *mountHealth) set(unhealthy map[string]string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.unhealthy = unhealthy
+}
+
+func (h *mountHealth) delete(path string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	delete(h.unhealthy, path)
+}
+
+// startProbe marks the probe of the mount at path as pending. It returns false if the previous probe of path is still
+// pending.
+func (h *mountHealth) startProbe(path string) bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.probing[path] {
+		return false
+	}
+	h.probing[path] = true
+	return true
+}
+
+func (h *mountHealth) finishProbe(path string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	delete(h.probing, path)
+}
+
+// checkMountHealth probes the Lustre mounts of the node at the staging target paths and pod target paths in the
+// kubelet directory. The pods of unhealthy mounts get an event, and NodeGetVolumeStats reports their volumes as
+// abnormal until a later check finds them healthy. Unhealthy mounts are recovered with the mount recovery of the
+// driver. Other Lustre mounts of the node, e.g. of administrators or the temporary mounts of the driver, are left alone.
+func (d *nodeService) checkMountHealth() {
+	mountPoints, err := d.mounter.List()
+	if err != nil {
+		klog.ErrorS(err, "checkMountHealth: could not list mounts")
+		return
+	}
+
+	kubeletPath := DefaultKubeletPath
+	if d.driverOptions != nil && d.driverOptions.kubeletPath != "" {
+		kubeletPath = d.driverOptions.kubeletPath
+	}
+	unhealthy := map[string]string{}
+	// all mounts of a device share the client of the filesystem, so they are recovered together
+	var unhealthyDevices []string
+	mountsByDevice := map[string][]mount.MountPoint{}
+	for _, mountPoint := range mountPoints {
+		if mountPoint.Type != "lustre" || !isVolumeMount(kubeletPath, mountPoint.Path) {
+			continue
+		}
+		mountsByDevice[mountPoint.Device] = append(mountsByDevice[mountPoint.Device], mountPoint)
+		if err := d.probeMount(mountPoint.Path); err != nil {
+			klog.InfoS("checkMountHealth: Lustre mount is unhealthy", "path", mountPoint.Path, "device", mountPoint.Device, "err", err)
+			unhealthy[mountPoint.Path] = err.Error()
+			if !slices.Contains(unhealthyDevices, mountPoint.Device) {
+				unhealthyDevices = append(unhealthyDevices, mountPoint.Device)
+			}
+		}
+	}
+	d.mountHealth.set(unhealthy)
+	if len(unhealthyDevices) == 0 {
+		return
+	}
+
+	ctx := context.Background()
+	pods := d.listNodePods(ctx)
+	recovery := MountRecoveryNone
+	if d.driverOptions != nil && d.driverOptions.mountRecovery != "" {
+		recovery = d.driverOptions.mountRecovery
+	}
+	for _, device := range unhealthyDevices {
+		mounts := mountsByDevice[device]
+		var reason string
+		for _, mountPoint := range mounts {
+			if message, ok := unhealthy[mountPoint.Path]; ok {
+				reason = message
+				break
+			}
+		}
+		d.recordPodEvents(pods, mounts, corev1.EventTypeWarning, "LustreMountUnhealthy", "Lustre mount of %s is unhealthy: %s", device, reason)
+		if recovery == MountRecoveryNone {
+			continue
+		}
+
+		klog.InfoS("checkMountHealth: recovering Lustre mounts", "device", device, "recovery", recovery)
+		if err := d.recoverMounts(ctx, recovery, device, mounts); err != nil {
+			klog.ErrorS(err, "checkMountHealth: could not recover Lustre mounts", "device", device, "recovery", recovery)
+			d.recordPodEvents(pods, mounts, corev1.EventTypeWarning, "LustreMountRecoveryFailed", "Could not recover Lustre mount of %s with %s: %v", device, recovery, err)
+			continue
+		}
+		for _, mountPoint := range mounts {
+			if err := d.probeMount(mountPoint.Path); err == nil {
+				d.mountHealth.delete(mountPoint.Path)
+			}
+		}
+		message := "Recovered Lustre mount of %s with %s"
+		if recovery == MountRecoveryRemount {
+			// containers only see the new mount if their volume mount propagates mounts from the host
+			message += ", restart the pod if its containers still cannot access the volume"
+		}
+		d.recordPodEvents(pods, mounts, corev1.EventTypeNormal, "LustreMountRecovered", message, device, recovery)
+	}
+}
+
+// isVolumeMount checks if path is a staging target path or a pod target path of a CSI volume in the kubelet directory
+func isVolumeMount(kubeletPath string, path string) bool {
+	if strings.HasPrefix(path, filepath.Join(kubeletPath, "plugins")+"/") {
+		return stagingMountPattern.MatchString(path)
+	}
+	return strings.HasPrefix(path, filepath.Join(kubeletPath, "pods")+"/") && podVolumeMountPattern.MatchString(path)
+}
+
+// probeMount checks that the mount at path responds to statfs within mountProbeTimeout. Errors that do not indicate
+// an unhealthy mount, e.g. of a path that was unmounted after the mount table was listed, are ignored. A statfs that
+// blocks is not abandoned, so a mount whose previous probe is still pending is not probed again and reported as
+// unhealthy.
+func (d *nodeService) probeMount(path string) error {
+	if ok := d.mountHealth.startProbe(path); !ok {
+		return fmt.Errorf("statfs of a previous check has not returned yet")
+	}
+	result := make(chan error, 1)
+	go func() {
+		_, err := d.mounter.Statfs(path)
+		d.mountHealth.finishProbe(path)
+		result <- err
+	}()
+
+	select {
+	case err := <-result:
+		if err != nil && d.mounter.IsCorruptedMnt(err) {
+			return err
+		}
+		if err != nil {
+			klog.V(4).InfoS("probeMount: could not probe mount", "path", path, "err", err)
+		}
+		return nil
+	case <-time.After(mountProbeTimeout):
+		return fmt.Errorf("statfs did not respond within %v", mountProbeTimeout)
+	}
+}
+
+// recoverMounts recovers the mounts of the Lustre device with recovery
+func (d *nodeService) recoverMounts(ctx context.Context, recovery string, device string, mounts []mount.MountPoint) error {
+	switch recovery {
+	case MountRecoveryLctl:
+		fsName, ok := lustreFsName(device)
+		if !ok {
+			return fmt.Errorf("could not parse filesystem name of device %q", device)
+		}
+		return d.lustre.RecoverImports(fsName)
+	case MountRecoveryRemount:
+		return d.remountDevice(ctx, device, mounts)
+	default:
+		return fmt.Errorf("unknown mount recovery: %s", recovery)
+	}
+}
+
+// remountDevice replaces the mounts of the Lustre device with new mounts. Staging target paths are mounted from the
+// device again and pod target paths are bind mounted from a remounted staging target path, or mounted from the device
+// if the volume is not staged.
+func (d *nodeService) remountDevice(ctx context.Context, device string, mounts []mount.MountPoint) error {
+	var stagingTarget string
+	var errs []error
+	for _, mountPoint := range mounts {
+		if podVolumeMountPattern.MatchString(mountPoint.Path) {
+			continue
+		}
+		remounted, err := d.remount(ctx, device, mountPoint, "")
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if remounted && stagingTarget == "" {
+			stagingTarget = mountPoint.Path
+		}
+	}
+	for _, mountPoint := range mounts {
+		if !podVolumeMountPattern.MatchString(mountPoint.Path) {
+			continue
+		}
+		if _, err := d.remount(ctx, device, mountPoint, stagingTarget); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// remount unmounts the mount at mountPoint and mounts it from device again, or bind mounts it from stagingTarget if
+// set. It holds the key of the volume operations for the volume and path, and skips the mount if a volume operation
+// is in progress for it or it was unmounted in the meantime. It returns whether the mount was remounted.
+func (d *nodeService) remount(ctx context.Context, device string, mountPoint mount.MountPoint, stagingTarget string) (bool, error) {
+	volumeID, err := podVolumeID(mountPoint.Path)
+	if err != nil {
+		return false, fmt.Errorf("could not get volume ID of %q: %v", mountPoint.Path, err)
+	}
+	rpcKey := fmt.Sprintf("%s-%s", volumeID, mountPoint.Path)
+	if ok := d.inFlight.Insert(rpcKey); !ok {
+		klog.V(4).InfoS("remount: volume operation in progress, skipping mount", "volumeID", volumeID, "target", mountPoint.Path)
+		return false, nil
+	}
+	defer d.inFlight.Delete(rpcKey)
+
+	current, err := d.findMountPoint(mountPoint.Path)
+	if err != nil {
+		return false, fmt.Errorf("could not list mounts: %v", err)
+	}
+	if current == nil {
+		klog.V(4).InfoS("remount: mount was unmounted in the meantime", "volumeID", volumeID, "target", mountPoint.Path)
+		return false, nil
+	}
+
+	klog.V(4).InfoS("remount: unmounting", "target", mountPoint.Path)
+	if err := d.unmount(mountPoint.Path); err != nil {
+		return false, fmt.Errorf("could not unmount %q: %v", mountPoint.Path, err)
+	}
+
+	if stagingTarget != "" {
+		mountOptions := []string{"bind"}
+		if hasMountOption(mountPoint.Opts, "ro") {
+			mountOptions = append(mountOptions, "ro")
+		}
+		klog.V(4).InfoS("remount: bind mounting", "source", stagingTarget, "target", mountPoint.Path, "mountOptions", mountOptions)
+		if err := d.mounter.Mount(stagingTarget, mountPoint.Path, "", mountOptions); err != nil {
+			return true, fmt.Errorf("could not bind mount %q at %q: %v", stagingTarget, mountPoint.Path, err)
+		}
+		return true, nil
+	}
+
+	// the mount table shows the options that the mount was created with next to defaults, which are left out
+	var mountOptions []string
+	for _, option := range mountPoint.Opts {
+		if option != "rw" && option != "seclabel" {
+			mountOptions = append(mountOptions, option)
+		}
+	}
+	klog.V(4).InfoS("remount: mounting", "source", device, "target", mountPoint.Path, "mountOptions", mountOptions)
+	if err := d.mountLustre(ctx, device, mountPoint.Path, mountOptions); err != nil {
+		return true, fmt.Errorf("could not mount %q at %q: %v", device, mountPoint.Path, err)
+	}
+	return true, nil
+}
+
+// lustreFsName returns the name of the filesystem of a Lustre device, e.g. mountname for 10.0.1.5@tcp:/mountname/dir.
+// The mount name of an FSx for Lustre filesystem is the name of its Lustre filesystem.
+func lustreFsName(device string) (string, bool) {
+	_, devicePath, ok := splitLustreDevice(device)
+	if !ok {
+		return "", false
+	}
+	fsName, _, _ := strings.Cut(strings.TrimPrefix(devicePath, "/"), "/")
+	return fsName, fsName != ""
+}
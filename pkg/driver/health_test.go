@@ -0,0 +1,355 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+	"golang.org/x/sys/unix"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/mount-utils"
+	"sigs.k8s.io/aws-fsx-csi-driver/pkg/driver/internal"
+	driverMocks "sigs.k8s.io/aws-fsx-csi-driver/pkg/driver/mocks"
+)
+
+func TestCheckMountHealth(t *testing.T) {
+	var (
+		device        = "10.0.1.5@tcp:/mountname"
+		volumeID      = "fs-0123456789abcdef0"
+		kubeletPath   = t.TempDir()
+		stagingTarget = filepath.Join(kubeletPath, "plugins/kubernetes.io/csi/fsx.csi.aws.com/0123abcd/globalmount")
+		podTarget     = filepath.Join(kubeletPath, "pods/pod-uid/volumes/kubernetes.io~csi/pv-1/mount")
+		mountPoints   = []mount.MountPoint{
+			{Device: "/dev/nvme0n1p1", Path: "/", Type: "xfs"},
+			{Device: device, Path: stagingTarget, Type: "lustre", Opts: []string{"rw", "flock"}},
+			{Device: device, Path: podTarget, Type: "lustre", Opts: []string{"rw", "flock"}},
+		}
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "training",
+				Namespace: "ml",
+				UID:       "pod-uid",
+			},
+			Spec: corev1.PodSpec{
+				NodeName: "test-node",
+			},
+		}
+		evicted = errors.New("cannot send after transport endpoint shutdown")
+	)
+
+	for _, target := range []string{stagingTarget, podTarget} {
+		if err := os.MkdirAll(target, 0750); err != nil {
+			t.Fatalf("Could not create target path: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(filepath.Dir(target), "vol_data.json"), []byte(`{"volumeHandle":"`+volumeID+`"}`), 0640); err != nil {
+			t.Fatalf("Could not write volume data: %v", err)
+		}
+	}
+
+	newNodeService := func(mockMounter *driverMocks.MockMounter, mockLustre *driverMocks.MockLustre, recorder record.EventRecorder, recovery string) *nodeService {
+		return &nodeService{
+			mounter:       mockMounter,
+			lustre:        mockLustre,
+			inFlight:      internal.NewInFlight(),
+			driverOptions: &DriverOptions{mountRecovery: recovery, kubeletPath: kubeletPath},
+			mountHealth:   newMountHealth(),
+			kubeClient:    fake.NewSimpleClientset(pod),
+			recorder:      recorder,
+			nodeName:      "test-node",
+		}
+	}
+
+	expectEvents := func(t *testing.T, recorder *record.FakeRecorder, reasons ...string) {
+		for _, reason := range reasons {
+			select {
+			case event := <-recorder.Events:
+				if !strings.Contains(event, reason) {
+					t.Fatalf("Unexpected event: %v, expected reason: %v", event, reason)
+				}
+			default:
+				t.Fatalf("No %v event is recorded", reason)
+			}
+		}
+		select {
+		case event := <-recorder.Events:
+			t.Fatalf("Unexpected event: %v", event)
+		default:
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: healthy mounts",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+				mockLustre := driverMocks.NewMockLustre(mockCtl)
+				recorder := record.NewFakeRecorder(10)
+				driver := newNodeService(mockMounter, mockLustre, recorder, MountRecoveryLctl)
+
+				mockMounter.EXPECT().List().Return(mountPoints, nil)
+				mockMounter.EXPECT().Statfs(gomock.Eq(stagingTarget)).Return(&unix.Statfs_t{}, nil)
+				mockMounter.EXPECT().Statfs(gomock.Eq(podTarget)).Return(&unix.Statfs_t{}, nil)
+				driver.checkMountHealth()
+
+				if _, unhealthy := driver.mountHealth.get(podTarget); unhealthy {
+					t.Fatalf("Healthy mount %q is reported as unhealthy", podTarget)
+				}
+				expectEvents(t, recorder)
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: evicted mounts are reported without recovery",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+				mockLustre := driverMocks.NewMockLustre(mockCtl)
+				recorder := record.NewFakeRecorder(10)
+				driver := newNodeService(mockMounter, mockLustre, recorder, MountRecoveryNone)
+
+				mockMounter.EXPECT().List().Return(mountPoints, nil)
+				mockMounter.EXPECT().Statfs(gomock.Eq(stagingTarget)).Return(nil, evicted)
+				mockMounter.EXPECT().Statfs(gomock.Eq(podTarget)).Return(nil, evicted)
+				mockMounter.EXPECT().IsCorruptedMnt(gomock.Eq(evicted)).Return(true).Times(2)
+				driver.checkMountHealth()
+
+				for _, target := range []string{stagingTarget, podTarget} {
+					if message, unhealthy := driver.mountHealth.get(target); !unhealthy || message != evicted.Error() {
+						t.Fatalf("Unexpected health of mount %q: %v, %v", target, unhealthy, message)
+					}
+				}
+				expectEvents(t, recorder, "LustreMountUnhealthy")
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: evicted mounts are recovered with lctl",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+				mockLustre := driverMocks.NewMockLustre(mockCtl)
+				recorder := record.NewFakeRecorder(10)
+				driver := newNodeService(mockMounter, mockLustre, recorder, MountRecoveryLctl)
+
+				mockMounter.EXPECT().List().Return(mountPoints, nil)
+				mockMounter.EXPECT().Statfs(gomock.Eq(stagingTarget)).Return(nil, evicted)
+				mockMounter.EXPECT().Statfs(gomock.Eq(podTarget)).Return(nil, evicted)
+				mockMounter.EXPECT().IsCorruptedMnt(gomock.Eq(evicted)).Return(true).Times(2)
+				mockLustre.EXPECT().RecoverImports(gomock.Eq("mountname")).Return(nil)
+				mockMounter.EXPECT().Statfs(gomock.Eq(stagingTarget)).Return(&unix.Statfs_t{}, nil)
+				mockMounter.EXPECT().Statfs(gomock.Eq(podTarget)).Return(&unix.Statfs_t{}, nil)
+				driver.checkMountHealth()
+
+				if _, unhealthy := driver.mountHealth.get(podTarget); unhealthy {
+					t.Fatalf("Recovered mount %q is reported as unhealthy", podTarget)
+				}
+				expectEvents(t, recorder, "LustreMountUnhealthy", "LustreMountRecovered")
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: evicted mounts are remounted",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+				mockLustre := driverMocks.NewMockLustre(mockCtl)
+				recorder := record.NewFakeRecorder(10)
+				driver := newNodeService(mockMounter, mockLustre, recorder, MountRecoveryRemount)
+
+				mockMounter.EXPECT().List().Return(mountPoints, nil)
+				mockMounter.EXPECT().Statfs(gomock.Eq(stagingTarget)).Return(nil, evicted)
+				mockMounter.EXPECT().Statfs(gomock.Eq(podTarget)).Return(nil, evicted)
+				mockMounter.EXPECT().IsCorruptedMnt(gomock.Eq(evicted)).Return(true).Times(2)
+				gomock.InOrder(
+					mockMounter.EXPECT().List().Return(mountPoints, nil),
+					mockMounter.EXPECT().Unmount(gomock.Eq(stagingTarget)).Return(nil),
+					mockMounter.EXPECT().Mount(gomock.Eq(device), gomock.Eq(stagingTarget), gomock.Eq("lustre"), gomock.Eq([]string{"flock"})).Return(nil),
+					mockMounter.EXPECT().List().Return(mountPoints, nil),
+					mockMounter.EXPECT().Unmount(gomock.Eq(podTarget)).Return(nil),
+					mockMounter.EXPECT().Mount(gomock.Eq(stagingTarget), gomock.Eq(podTarget), gomock.Eq(""), gomock.Eq([]string{"bind"})).Return(nil),
+				)
+				mockMounter.EXPECT().Statfs(gomock.Eq(stagingTarget)).Return(&unix.Statfs_t{}, nil)
+				mockMounter.EXPECT().Statfs(gomock.Eq(podTarget)).Return(&unix.Statfs_t{}, nil)
+				driver.checkMountHealth()
+
+				if _, unhealthy := driver.mountHealth.get(podTarget); unhealthy {
+					t.Fatalf("Remounted mount %q is reported as unhealthy", podTarget)
+				}
+				expectEvents(t, recorder, "LustreMountUnhealthy", "LustreMountRecovered")
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: mounts with a volume operation in progress are not remounted",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+				mockLustre := driverMocks.NewMockLustre(mockCtl)
+				recorder := record.NewFakeRecorder(10)
+				driver := newNodeService(mockMounter, mockLustre, recorder, MountRecoveryRemount)
+				driver.inFlight.Insert(volumeID + "-" + podTarget)
+
+				mockMounter.EXPECT().List().Return(mountPoints, nil)
+				mockMounter.EXPECT().Statfs(gomock.Eq(stagingTarget)).Return(nil, evicted)
+				mockMounter.EXPECT().Statfs(gomock.Eq(podTarget)).Return(nil, evicted)
+				mockMounter.EXPECT().IsCorruptedMnt(gomock.Eq(evicted)).Return(true).Times(2)
+				gomock.InOrder(
+					mockMounter.EXPECT().List().Return(mountPoints, nil),
+					mockMounter.EXPECT().Unmount(gomock.Eq(stagingTarget)).Return(nil),
+					mockMounter.EXPECT().Mount(gomock.Eq(device), gomock.Eq(stagingTarget), gomock.Eq("lustre"), gomock.Eq([]string{"flock"})).Return(nil),
+				)
+				mockMounter.EXPECT().Statfs(gomock.Eq(stagingTarget)).Return(&unix.Statfs_t{}, nil)
+				mockMounter.EXPECT().Statfs(gomock.Eq(podTarget)).Return(nil, evicted)
+				mockMounter.EXPECT().IsCorruptedMnt(gomock.Eq(evicted)).Return(true)
+				driver.checkMountHealth()
+
+				if _, unhealthy := driver.mountHealth.get(stagingTarget); unhealthy {
+					t.Fatalf("Remounted mount %q is reported as unhealthy", stagingTarget)
+				}
+				if _, unhealthy := driver.mountHealth.get(podTarget); !unhealthy {
+					t.Fatalf("Mount %q that was not remounted is reported as healthy", podTarget)
+				}
+				expectEvents(t, recorder, "LustreMountUnhealthy", "LustreMountRecovered")
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: Lustre mounts outside of the kubelet directory are not probed",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+				mockLustre := driverMocks.NewMockLustre(mockCtl)
+				recorder := record.NewFakeRecorder(10)
+				driver := newNodeService(mockMounter, mockLustre, recorder, MountRecoveryRemount)
+
+				otherMountPoints := []mount.MountPoint{
+					{Device: device, Path: "/fsx", Type: "lustre"},
+					{Device: device, Path: "/tmp/fsx-subpath-123456", Type: "lustre"},
+					{Device: device, Path: filepath.Join(kubeletPath, "pods/pod-uid/volumes/kubernetes.io~empty-dir/cache/fsx"), Type: "lustre"},
+					{Device: device, Path: podTarget, Type: "lustre"},
+				}
+				mockMounter.EXPECT().List().Return(otherMountPoints, nil)
+				mockMounter.EXPECT().Statfs(gomock.Eq(podTarget)).Return(&unix.Statfs_t{}, nil)
+				driver.checkMountHealth()
+
+				expectEvents(t, recorder)
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: mounts whose previous probe is pending are unhealthy",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+				mockLustre := driverMocks.NewMockLustre(mockCtl)
+				recorder := record.NewFakeRecorder(10)
+				driver := newNodeService(mockMounter, mockLustre, recorder, MountRecoveryNone)
+
+				probeTimeout := mountProbeTimeout
+				mountProbeTimeout = 10 * time.Millisecond
+				defer func() { mountProbeTimeout = probeTimeout }()
+
+				blocked := make(chan struct{})
+				podMountPoints := []mount.MountPoint{{Device: device, Path: podTarget, Type: "lustre"}}
+				mockMounter.EXPECT().List().Return(podMountPoints, nil).Times(2)
+				// the second check must not probe the mount again while the first statfs blocks
+				mockMounter.EXPECT().Statfs(gomock.Eq(podTarget)).DoAndReturn(func(string) (*unix.Statfs_t, error) {
+					<-blocked
+					return &unix.Statfs_t{}, nil
+				})
+				for i := 0; i < 2; i++ {
+					driver.checkMountHealth()
+					if _, unhealthy := driver.mountHealth.get(podTarget); !unhealthy {
+						t.Fatalf("Mount %q that does not respond is reported as healthy", podTarget)
+					}
+				}
+				close(blocked)
+				expectEvents(t, recorder, "LustreMountUnhealthy", "LustreMountUnhealthy")
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: recovery of evicted mounts failed",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+				mockLustre := driverMocks.NewMockLustre(mockCtl)
+				recorder := record.NewFakeRecorder(10)
+				driver := newNodeService(mockMounter, mockLustre, recorder, MountRecoveryLctl)
+
+				mockMounter.EXPECT().List().Return(mountPoints, nil)
+				mockMounter.EXPECT().Statfs(gomock.Eq(stagingTarget)).Return(nil, evicted)
+				mockMounter.EXPECT().Statfs(gomock.Eq(podTarget)).Return(nil, evicted)
+				mockMounter.EXPECT().IsCorruptedMnt(gomock.Eq(evicted)).Return(true).Times(2)
+				mockLustre.EXPECT().RecoverImports(gomock.Eq("mountname")).Return(errors.New("lctl --device mountname-MDT0000-mdc-ffff8e3a1c2d3000 recover failed"))
+				driver.checkMountHealth()
+
+				if _, unhealthy := driver.mountHealth.get(podTarget); !unhealthy {
+					t.Fatalf("Mount %q that could not be recovered is reported as healthy", podTarget)
+				}
+				expectEvents(t, recorder, "LustreMountUnhealthy", "LustreMountRecoveryFailed")
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestLustreFsName(t *testing.T) {
+	testCases := []struct {
+		device         string
+		expectedFsName string
+		expectedOk     bool
+	}{
+		{device: "10.0.1.5@tcp:/mountname", expectedFsName: "mountname", expectedOk: true},
+		{device: "10.0.1.5@tcp:10.0.1.6@tcp:/mountname/teams/ml", expectedFsName: "mountname", expectedOk: true},
+		{device: "/dev/nvme0n1p1", expectedOk: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.device, func(t *testing.T) {
+			fsName, ok := lustreFsName(tc.device)
+			if fsName != tc.expectedFsName || ok != tc.expectedOk {
+				t.Fatalf("Unexpected filesystem name: %q, %v, expected: %q, %v", fsName, ok, tc.expectedFsName, tc.expectedOk)
+			}
+		})
+	}
+}
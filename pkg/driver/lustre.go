@@ -0,0 +1,230 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/utils/exec"
+)
+
+// lustreTunablePrefix prefixes the StorageClass parameters and volume attributes that set Lustre client tunables
+const lustreTunablePrefix = "lustre."
+
+// lustreTunables are the Lustre client tunables that can be set per volume. They are applied to the llite, osc and mdc
+// instances of the mount of the volume only.
+var lustreTunables = map[string]bool{
+	"llite.max_cached_mb":              true,
+	"llite.max_read_ahead_mb":          true,
+	"llite.max_read_ahead_per_file_mb": true,
+	"llite.statahead_max":              true,
+	"osc.max_dirty_mb":                 true,
+	"osc.max_pages_per_rpc":            true,
+	"osc.max_rpcs_in_flight":           true,
+	"mdc.max_rpcs_in_flight":           true,
+	"mdc.max_mod_rpcs_in_flight":       true,
+}
+
+// Lustre is an interface for the Lustre client utilities
+type Lustre interface {
+	// GetProject returns the project ID of a file or directory
+	GetProject(path string) (uint32, error)
+	// SetProject sets the project ID of a directory and makes files and directories created below it inherit the
+	// project ID
+	SetProject(dir string, projectId uint32) error
+	// SetProjectQuota sets the block hard limit of a project on the filesystem mounted at mountPoint. A limit of 0
+	// removes the limit.
+	SetProjectQuota(mountPoint string, projectId uint32, limitBytes int64) error
+	// GetProjectQuota returns the used bytes, the used files and the block hard limit of a project on the filesystem
+	// mounted at mountPoint. A limit of 0 means no limit.
+	GetProjectQuota(mountPoint string, projectId uint32) (usedBytes, usedFiles, limitBytes int64, err error)
+	// GetInstance returns the name of the client instance of the filesystem mounted at mountPoint, e.g.
+	// fsx-ffff8e3a1c2d3000
+	GetInstance(mountPoint string) (string, error)
+	// SetParam sets a Lustre parameter, which may contain wildcards
+	SetParam(name string, value string) error
+	// RecoverImports reconnects the client to the MDTs and OSTs of the filesystem fsName, e.g. after the client was
+	// evicted
+	RecoverImports(fsName string) error
+}
+
+type NodeLustre struct {
+	exec exec.Interface
+}
+
+func newNodeLustre() Lustre {
+	return &NodeLustre{
+		exec: exec.New(),
+	}
+}
+
+func (l *NodeLustre) GetProject(path string) (uint32, error) {
+	// lfs project -d prints the project ID, the inherit flag and the path of a directory
+	output, err := l.lfs("project", "-d", path)
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("could not parse project of %q from output %q", path, output)
+	}
+	projectId, err := strconv.ParseUint(fields[0], 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse project of %q from output %q: %v", path, output, err)
+	}
+	return uint32(projectId), nil
+}
+
+func (l *NodeLustre) SetProject(dir string, projectId uint32) error {
+	_, err := l.lfs("project", "-p", strconv.FormatUint(uint64(projectId), 10), "-s", "-r", dir)
+	return err
+}
+
+func (l *NodeLustre) SetProjectQuota(mountPoint string, projectId uint32, limitBytes int64) error {
+	// block limits are in KiB, the hard limit is rounded up so the quota is never smaller than the requested bytes
+	limitKiB := (limitBytes + 1023) / 1024
+	_, err := l.lfs("setquota", "-p", strconv.FormatUint(uint64(projectId), 10), "-b", "0", "-B", strconv.FormatInt(limitKiB, 10), mountPoint)
+	return err
+}
+
+func (l *NodeLustre) GetProjectQuota(mountPoint string, projectId uint32) (int64, int64, int64, error) {
+	// lfs quota -q prints the mount point followed by the used KiB, quota, limit and grace of blocks and the used
+	// count, quota, limit and grace of inodes. A long mount point is printed on a line of its own.
+	output, err := l.lfs("quota", "-q", "-p", strconv.FormatUint(uint64(projectId), 10), mountPoint)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	fields := strings.Fields(output)
+	if len(fields) < 6 {
+		return 0, 0, 0, fmt.Errorf("could not parse quota of project %d from output %q", projectId, output)
+	}
+	// usage over the quota is marked with an asterisk
+	var values [3]int64
+	for i, field := range []string{fields[1], fields[3], fields[5]} {
+		values[i], err = strconv.ParseInt(strings.TrimSuffix(field, "*"), 10, 64)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("could not parse quota of project %d from output %q: %v", projectId, output, err)
+		}
+	}
+	return values[0] * 1024, values[2], values[1] * 1024, nil
+}
+
+func (l *NodeLustre) GetInstance(mountPoint string) (string, error) {
+	// lfs getname prints the instance and the mount point
+	output, err := l.lfs("getname", mountPoint)
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(output)
+	if len(fields) == 0 || !strings.Contains(fields[0], "-") {
+		return "", fmt.Errorf("could not parse instance of %q from output %q", mountPoint, output)
+	}
+	return fields[0], nil
+}
+
+func (l *NodeLustre) SetParam(name string, value string) error {
+	_, err := l.lctl("set_param", name+"="+value)
+	return err
+}
+
+func (l *NodeLustre) RecoverImports(fsName string) error {
+	// lctl dl lists the devices of the client with their index, status, type, name, UUID and reference count, e.g.
+	//   5 UP osc fsx-OST0000-osc-ffff8e3a1c2d3000 3c2a9e4b-5d1f-4a7e-9b0c-2f6e8d1a7c3b 4
+	output, err := l.lctl("dl")
+	if err != nil {
+		return err
+	}
+	var devices []string
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || (fields[2] != "mdc" && fields[2] != "osc") || !strings.HasPrefix(fields[3], fsName+"-") {
+			continue
+		}
+		devices = append(devices, fields[3])
+	}
+	if len(devices) == 0 {
+		return fmt.Errorf("no MDT or OST imports of filesystem %q found", fsName)
+	}
+	var errs []error
+	for _, device := range devices {
+		if _, err := l.lctl("--device", device, "recover"); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// parseLustreTunables returns the Lustre client tunables in the StorageClass parameters or the volume attributes of a
+// volume, keyed by tunable without lustreTunablePrefix.
+func parseLustreTunables(attributes map[string]string) (map[string]string, error) {
+	tunables := map[string]string{}
+	for key, value := range attributes {
+		tunable, ok := strings.CutPrefix(key, lustreTunablePrefix)
+		if !ok {
+			continue
+		}
+		if !lustreTunables[tunable] {
+			supported := make([]string, 0, len(lustreTunables))
+			for name := range lustreTunables {
+				supported = append(supported, lustreTunablePrefix+name)
+			}
+			sort.Strings(supported)
+			return nil, fmt.Errorf("lustre tunable %q is not supported, supported tunables are: %s", key, strings.Join(supported, ", "))
+		}
+		if _, err := strconv.ParseUint(value, 10, 32); err != nil {
+			return nil, fmt.Errorf("lustre tunable %q must be a non-negative integer", key)
+		}
+		tunables[tunable] = value
+	}
+	return tunables, nil
+}
+
+// lustreTunableParam returns the parameter that sets tunable for the llite, osc or mdc instances of the client
+// instance, e.g. osc.fsx-OST*-osc-ffff8e3a1c2d3000.max_rpcs_in_flight.
+func lustreTunableParam(instance string, tunable string) string {
+	device, param, _ := strings.Cut(tunable, ".")
+	separator := strings.LastIndex(instance, "-")
+	fsName, id := instance[:separator], instance[separator+1:]
+	switch device {
+	case "osc":
+		return fmt.Sprintf("osc.%s-OST*-osc-%s.%s", fsName, id, param)
+	case "mdc":
+		return fmt.Sprintf("mdc.%s-MDT*-mdc-%s.%s", fsName, id, param)
+	default:
+		return fmt.Sprintf("%s.%s.%s", device, instance, param)
+	}
+}
+
+func (l *NodeLustre) lfs(args ...string) (string, error) {
+	output, err := l.exec.Command("lfs", args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("lfs %s failed: %v, output: %q", strings.Join(args, " "), err, string(output))
+	}
+	return string(output), nil
+}
+
+func (l *NodeLustre) lctl(args ...string) (string, error) {
+	output, err := l.exec.Command("lctl", args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("lctl %s failed: %v, output: %q", strings.Join(args, " "), err, string(output))
+	}
+	return string(output), nil
+}
@@ -0,0 +1,318 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"k8s.io/utils/exec"
+	testingexec "k8s.io/utils/exec/testing"
+)
+
+func newFakeLustre(t *testing.T, expectedArgs []string, output string, err error) *NodeLustre {
+	fakeExec := &testingexec.FakeExec{
+		CommandScript: []testingexec.FakeCommandAction{
+			func(cmd string, args ...string) exec.Cmd {
+				if cmd != "lfs" || !reflect.DeepEqual(args, expectedArgs) {
+					t.Fatalf("Command mismatches. actual: %v %v expected: lfs %v", cmd, args, expectedArgs)
+				}
+				return &testingexec.FakeCmd{
+					CombinedOutputScript: []testingexec.FakeAction{
+						func() ([]byte, []byte, error) { return []byte(output), nil, err },
+					},
+				}
+			},
+		},
+	}
+	return &NodeLustre{exec: fakeExec}
+}
+
+func TestGetProject(t *testing.T) {
+	testCases := []struct {
+		name       string
+		output     string
+		err        error
+		expectedId uint32
+		expectErr  bool
+	}{
+		{
+			name:       "success: project with inherit flag",
+			output:     " 65578 P /mnt/fsx/teams/pvc-1234\n",
+			expectedId: 65578,
+		},
+		{
+			name:       "success: no project",
+			output:     "    0 - /mnt/fsx/teams/pvc-1234\n",
+			expectedId: 0,
+		},
+		{
+			name:      "fail: unexpected output",
+			output:    "lfs: unknown command\n",
+			expectErr: true,
+		},
+		{
+			name:      "fail: command failed",
+			err:       errors.New("exit status 1"),
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lustre := newFakeLustre(t, []string{"project", "-d", "/mnt/fsx/teams/pvc-1234"}, tc.output, tc.err)
+			projectId, err := lustre.GetProject("/mnt/fsx/teams/pvc-1234")
+			if tc.expectErr {
+				if err == nil {
+					t.Fatalf("GetProject is not failed")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetProject is failed: %v", err)
+			}
+			if projectId != tc.expectedId {
+				t.Fatalf("Project ID mismatches. actual: %v expected: %v", projectId, tc.expectedId)
+			}
+		})
+	}
+}
+
+func TestSetProjectQuota(t *testing.T) {
+	// the limit is rounded up to whole KiB
+	lustre := newFakeLustre(t, []string{"setquota", "-p", "65578", "-b", "0", "-B", "10485761", "/mnt/fsx"}, "", nil)
+	if err := lustre.SetProjectQuota("/mnt/fsx", 65578, 10*1024*1024*1024+1); err != nil {
+		t.Fatalf("SetProjectQuota is failed: %v", err)
+	}
+}
+
+func TestGetProjectQuota(t *testing.T) {
+	testCases := []struct {
+		name               string
+		output             string
+		err                error
+		expectedUsedBytes  int64
+		expectedUsedFiles  int64
+		expectedLimitBytes int64
+		expectErr          bool
+	}{
+		{
+			name:               "success: used by files",
+			output:             "       /mnt/fsx       4       0 10485760       -       1       0       0       -\n",
+			expectedUsedBytes:  4096,
+			expectedUsedFiles:  1,
+			expectedLimitBytes: 10737418240,
+		},
+		{
+			name:               "success: over quota on a wrapped line",
+			output:             "/mnt/fsx/with/a/long/mount/point\n                10485764*      0 10485760       -       3       0       0       -\n",
+			expectedUsedBytes:  10737422336,
+			expectedUsedFiles:  3,
+			expectedLimitBytes: 10737418240,
+		},
+		{
+			name:               "success: unused project with leftover limit",
+			output:             "       /mnt/fsx       0       0 10485760       -       0       0       0       -\n",
+			expectedLimitBytes: 10737418240,
+		},
+		{
+			name:      "fail: unexpected output",
+			output:    "quotactl failed\n",
+			expectErr: true,
+		},
+		{
+			name:      "fail: lfs failed",
+			err:       errors.New("exit status 1"),
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lustre := newFakeLustre(t, []string{"quota", "-q", "-p", "65578", "/mnt/fsx"}, tc.output, tc.err)
+			usedBytes, usedFiles, limitBytes, err := lustre.GetProjectQuota("/mnt/fsx", 65578)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatalf("GetProjectQuota is not failed")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetProjectQuota is failed: %v", err)
+			}
+			if usedBytes != tc.expectedUsedBytes || usedFiles != tc.expectedUsedFiles || limitBytes != tc.expectedLimitBytes {
+				t.Fatalf("Quota mismatches. actual: %d %d %d expected: %d %d %d", usedBytes, usedFiles, limitBytes, tc.expectedUsedBytes, tc.expectedUsedFiles, tc.expectedLimitBytes)
+			}
+		})
+	}
+}
+
+func TestGetInstance(t *testing.T) {
+	lustre := newFakeLustre(t, []string{"getname", "/mnt/fsx"}, "fsx-ffff8e3a1c2d3000 /mnt/fsx\n", nil)
+	instance, err := lustre.GetInstance("/mnt/fsx")
+	if err != nil {
+		t.Fatalf("GetInstance is failed: %v", err)
+	}
+	if instance != "fsx-ffff8e3a1c2d3000" {
+		t.Fatalf("Instance mismatches. actual: %v expected: %v", instance, "fsx-ffff8e3a1c2d3000")
+	}
+}
+
+func TestParseLustreTunables(t *testing.T) {
+	testCases := []struct {
+		name       string
+		attributes map[string]string
+		expected   map[string]string
+		expectErr  bool
+	}{
+		{
+			name: "success: supported tunables",
+			attributes: map[string]string{
+				volumeContextDnsName:                           "fs-1234.fsx.us-west-2.amazonaws.com",
+				lustreTunablePrefix + "llite.max_cached_mb":    "32",
+				lustreTunablePrefix + "osc.max_rpcs_in_flight": "64",
+			},
+			expected: map[string]string{
+				"llite.max_cached_mb":    "32",
+				"osc.max_rpcs_in_flight": "64",
+			},
+		},
+		{
+			name: "fail: unsupported tunable",
+			attributes: map[string]string{
+				lustreTunablePrefix + "ldlm.namespaces.lru_size": "100",
+			},
+			expectErr: true,
+		},
+		{
+			name: "fail: value is not a number",
+			attributes: map[string]string{
+				lustreTunablePrefix + "llite.max_cached_mb": "32; reboot",
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tunables, err := parseLustreTunables(tc.attributes)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatalf("parseLustreTunables is not failed")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseLustreTunables is failed: %v", err)
+			}
+			if !reflect.DeepEqual(tunables, tc.expected) {
+				t.Fatalf("Tunables mismatch. actual: %v expected: %v", tunables, tc.expected)
+			}
+		})
+	}
+}
+
+func TestLustreTunableParam(t *testing.T) {
+	instance := "fsx-ffff8e3a1c2d3000"
+	testCases := map[string]string{
+		"llite.max_cached_mb":        "llite.fsx-ffff8e3a1c2d3000.max_cached_mb",
+		"osc.max_dirty_mb":           "osc.fsx-OST*-osc-ffff8e3a1c2d3000.max_dirty_mb",
+		"mdc.max_mod_rpcs_in_flight": "mdc.fsx-MDT*-mdc-ffff8e3a1c2d3000.max_mod_rpcs_in_flight",
+	}
+
+	for tunable, expected := range testCases {
+		if param := lustreTunableParam(instance, tunable); param != expected {
+			t.Fatalf("Param mismatches. actual: %v expected: %v", param, expected)
+		}
+	}
+}
+
+func TestRecoverImports(t *testing.T) {
+	devices := `  0 UP mgc MGC10.0.1.5@tcp 3c2a9e4b-5d1f-4a7e-9b0c-2f6e8d1a7c3b 4
+  1 UP lov mountname-clilov-ffff8e3a1c2d3000 8a1b2c3d-4e5f-6a7b-8c9d-0e1f2a3b4c5d 3
+  2 UP lmv mountname-clilmv-ffff8e3a1c2d3000 8a1b2c3d-4e5f-6a7b-8c9d-0e1f2a3b4c5d 4
+  3 UP mdc mountname-MDT0000-mdc-ffff8e3a1c2d3000 8a1b2c3d-4e5f-6a7b-8c9d-0e1f2a3b4c5d 4
+  4 UP osc mountname-OST0000-osc-ffff8e3a1c2d3000 8a1b2c3d-4e5f-6a7b-8c9d-0e1f2a3b4c5d 4
+  5 UP osc other-OST0000-osc-ffff8e3a1c2d4000 9b2c3d4e-5f6a-7b8c-9d0e-1f2a3b4c5d6e 4
+`
+	testCases := []struct {
+		name         string
+		output       string
+		recoverErr   error
+		expectedArgs [][]string
+		expectErr    bool
+	}{
+		{
+			name:   "success: recover MDT and OST imports of filesystem",
+			output: devices,
+			expectedArgs: [][]string{
+				{"dl"},
+				{"--device", "mountname-MDT0000-mdc-ffff8e3a1c2d3000", "recover"},
+				{"--device", "mountname-OST0000-osc-ffff8e3a1c2d3000", "recover"},
+			},
+		},
+		{
+			name:         "fail: no imports of filesystem",
+			output:       "  0 UP mgc MGC10.0.1.5@tcp 3c2a9e4b-5d1f-4a7e-9b0c-2f6e8d1a7c3b 4\n",
+			expectedArgs: [][]string{{"dl"}},
+			expectErr:    true,
+		},
+		{
+			name:       "fail: recover failed",
+			output:     devices,
+			recoverErr: errors.New("exit status 1"),
+			expectedArgs: [][]string{
+				{"dl"},
+				{"--device", "mountname-MDT0000-mdc-ffff8e3a1c2d3000", "recover"},
+				{"--device", "mountname-OST0000-osc-ffff8e3a1c2d3000", "recover"},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeExec := &testingexec.FakeExec{}
+			for i, expectedArgs := range tc.expectedArgs {
+				output, err := "", tc.recoverErr
+				if i == 0 {
+					output, err = tc.output, nil
+				}
+				fakeExec.CommandScript = append(fakeExec.CommandScript, func(cmd string, args ...string) exec.Cmd {
+					if cmd != "lctl" || !reflect.DeepEqual(args, expectedArgs) {
+						t.Fatalf("Command mismatches. actual: %v %v expected: lctl %v", cmd, args, expectedArgs)
+					}
+					return &testingexec.FakeCmd{
+						CombinedOutputScript: []testingexec.FakeAction{
+							func() ([]byte, []byte, error) { return []byte(output), nil, err },
+						},
+					}
+				})
+			}
+			lustre := &NodeLustre{exec: fakeExec}
+
+			err := lustre.RecoverImports("mountname")
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if fakeExec.CommandCalls != len(tc.expectedArgs) {
+				t.Fatalf("Unexpected number of commands: %d, expected: %d", fakeExec.CommandCalls, len(tc.expectedArgs))
+			}
+		})
+	}
+}
@@ -41,6 +41,51 @@ func (m *MockCloud) EXPECT() *MockCloudMockRecorder {
 	return m.recorder
 }
 
+// CreateBackup mocks base method.
+func (m *MockCloud) CreateBackup(ctx context.Context, snapshotName string, backupOptions *cloud.BackupOptions) (*cloud.Backup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBackup", ctx, snapshotName, backupOptions)
+	ret0, _ := ret[0].(*cloud.Backup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBackup indicates an expected call of CreateBackup.
+func (mr *MockCloudMockRecorder) CreateBackup(ctx, snapshotName, backupOptions any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBackup", reflect.TypeOf((*MockCloud)(nil).CreateBackup), ctx, snapshotName, backupOptions)
+}
+
+// CreateDataRepositoryAssociation mocks base method.
+func (m *MockCloud) CreateDataRepositoryAssociation(ctx context.Context, fileSystemId string, associationOptions *cloud.DataRepositoryAssociationOptions) (*cloud.DataRepositoryAssociation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDataRepositoryAssociation", ctx, fileSystemId, associationOptions)
+	ret0, _ := ret[0].(*cloud.DataRepositoryAssociation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDataRepositoryAssociation indicates an expected call of CreateDataRepositoryAssociation.
+func (mr *MockCloudMockRecorder) CreateDataRepositoryAssociation(ctx, fileSystemId, associationOptions any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDataRepositoryAssociation", reflect.TypeOf((*MockCloud)(nil).CreateDataRepositoryAssociation), ctx, fileSystemId, associationOptions)
+}
+
+// CreateDataRepositoryTask mocks base method.
+func (m *MockCloud) CreateDataRepositoryTask(ctx context.Context, fileSystemId, taskType string) (*cloud.DataRepositoryTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDataRepositoryTask", ctx, fileSystemId, taskType)
+	ret0, _ := ret[0].(*cloud.DataRepositoryTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDataRepositoryTask indicates an expected call of CreateDataRepositoryTask.
+func (mr *MockCloudMockRecorder) CreateDataRepositoryTask(ctx, fileSystemId, taskType any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDataRepositoryTask", reflect.TypeOf((*MockCloud)(nil).CreateDataRepositoryTask), ctx, fileSystemId, taskType)
+}
+
 // CreateFileSystem mocks base method.
 func (m *MockCloud) CreateFileSystem(ctx context.Context, volumeName string, fileSystemOptions *cloud.FileSystemOptions) (*cloud.FileSystem, error) {
 	m.ctrl.T.Helper()
@@ -56,18 +101,107 @@ func (mr *MockCloudMockRecorder) CreateFileSystem(ctx, volumeName, fileSystemOpt
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFileSystem", reflect.TypeOf((*MockCloud)(nil).CreateFileSystem), ctx, volumeName, fileSystemOptions)
 }
 
-// DeleteFileSystem mocks base method.
-func (m *MockCloud) DeleteFileSystem(ctx context.Context, fileSystemId string) error {
+// CreateFileSystemFromBackup mocks base method.
+func (m *MockCloud) CreateFileSystemFromBackup(ctx context.Context, volumeName, backupId string, fileSystemOptions *cloud.FileSystemOptions) (*cloud.FileSystem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFileSystemFromBackup", ctx, volumeName, backupId, fileSystemOptions)
+	ret0, _ := ret[0].(*cloud.FileSystem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFileSystemFromBackup indicates an expected call of CreateFileSystemFromBackup.
+func (mr *MockCloudMockRecorder) CreateFileSystemFromBackup(ctx, volumeName, backupId, fileSystemOptions any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFileSystemFromBackup", reflect.TypeOf((*MockCloud)(nil).CreateFileSystemFromBackup), ctx, volumeName, backupId, fileSystemOptions)
+}
+
+// DeleteBackup mocks base method.
+func (m *MockCloud) DeleteBackup(ctx context.Context, backupId string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteFileSystem", ctx, fileSystemId)
+	ret := m.ctrl.Call(m, "DeleteBackup", ctx, backupId)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// DeleteBackup indicates an expected call of DeleteBackup.
+func (mr *MockCloudMockRecorder) DeleteBackup(ctx, backupId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBackup", reflect.TypeOf((*MockCloud)(nil).DeleteBackup), ctx, backupId)
+}
+
+// DeleteFileSystem mocks base method.
+func (m *MockCloud) DeleteFileSystem(ctx context.Context, fileSystemId string, deleteOptions *cloud.DeleteFileSystemOptions) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFileSystem", ctx, fileSystemId, deleteOptions)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // DeleteFileSystem indicates an expected call of DeleteFileSystem.
-func (mr *MockCloudMockRecorder) DeleteFileSystem(ctx, fileSystemId any) *gomock.Call {
+func (mr *MockCloudMockRecorder) DeleteFileSystem(ctx, fileSystemId, deleteOptions any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFileSystem", reflect.TypeOf((*MockCloud)(nil).DeleteFileSystem), ctx, fileSystemId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFileSystem", reflect.TypeOf((*MockCloud)(nil).DeleteFileSystem), ctx, fileSystemId, deleteOptions)
+}
+
+// DeleteUnusedClusterSecurityGroups mocks base method.
+func (m *MockCloud) DeleteUnusedClusterSecurityGroups(ctx context.Context, clusterName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUnusedClusterSecurityGroups", ctx, clusterName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUnusedClusterSecurityGroups indicates an expected call of DeleteUnusedClusterSecurityGroups.
+func (mr *MockCloudMockRecorder) DeleteUnusedClusterSecurityGroups(ctx, clusterName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUnusedClusterSecurityGroups", reflect.TypeOf((*MockCloud)(nil).DeleteUnusedClusterSecurityGroups), ctx, clusterName)
+}
+
+// DescribeBackup mocks base method.
+func (m *MockCloud) DescribeBackup(ctx context.Context, backupId string) (*cloud.Backup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DescribeBackup", ctx, backupId)
+	ret0, _ := ret[0].(*cloud.Backup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeBackup indicates an expected call of DescribeBackup.
+func (mr *MockCloudMockRecorder) DescribeBackup(ctx, backupId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeBackup", reflect.TypeOf((*MockCloud)(nil).DescribeBackup), ctx, backupId)
+}
+
+// DescribeDataRepositoryAssociation mocks base method.
+func (m *MockCloud) DescribeDataRepositoryAssociation(ctx context.Context, associationId string) (*cloud.DataRepositoryAssociation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DescribeDataRepositoryAssociation", ctx, associationId)
+	ret0, _ := ret[0].(*cloud.DataRepositoryAssociation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeDataRepositoryAssociation indicates an expected call of DescribeDataRepositoryAssociation.
+func (mr *MockCloudMockRecorder) DescribeDataRepositoryAssociation(ctx, associationId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeDataRepositoryAssociation", reflect.TypeOf((*MockCloud)(nil).DescribeDataRepositoryAssociation), ctx, associationId)
+}
+
+// DescribeDataRepositoryTask mocks base method.
+func (m *MockCloud) DescribeDataRepositoryTask(ctx context.Context, taskId string) (*cloud.DataRepositoryTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DescribeDataRepositoryTask", ctx, taskId)
+	ret0, _ := ret[0].(*cloud.DataRepositoryTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeDataRepositoryTask indicates an expected call of DescribeDataRepositoryTask.
+func (mr *MockCloudMockRecorder) DescribeDataRepositoryTask(ctx, taskId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeDataRepositoryTask", reflect.TypeOf((*MockCloud)(nil).DescribeDataRepositoryTask), ctx, taskId)
 }
 
 // DescribeFileSystem mocks base method.
@@ -85,6 +219,51 @@ func (mr *MockCloudMockRecorder) DescribeFileSystem(ctx, fileSystemId any) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeFileSystem", reflect.TypeOf((*MockCloud)(nil).DescribeFileSystem), ctx, fileSystemId)
 }
 
+// DescribeSubnet mocks base method.
+func (m *MockCloud) DescribeSubnet(ctx context.Context, subnetId string) (*cloud.Subnet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DescribeSubnet", ctx, subnetId)
+	ret0, _ := ret[0].(*cloud.Subnet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DescribeSubnet indicates an expected call of DescribeSubnet.
+func (mr *MockCloudMockRecorder) DescribeSubnet(ctx, subnetId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DescribeSubnet", reflect.TypeOf((*MockCloud)(nil).DescribeSubnet), ctx, subnetId)
+}
+
+// EnsureClusterSecurityGroup mocks base method.
+func (m *MockCloud) EnsureClusterSecurityGroup(ctx context.Context, clusterName, vpcId string, sourceSecurityGroupIds []string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureClusterSecurityGroup", ctx, clusterName, vpcId, sourceSecurityGroupIds)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnsureClusterSecurityGroup indicates an expected call of EnsureClusterSecurityGroup.
+func (mr *MockCloudMockRecorder) EnsureClusterSecurityGroup(ctx, clusterName, vpcId, sourceSecurityGroupIds any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureClusterSecurityGroup", reflect.TypeOf((*MockCloud)(nil).EnsureClusterSecurityGroup), ctx, clusterName, vpcId, sourceSecurityGroupIds)
+}
+
+// FindCloneBackup mocks base method.
+func (m *MockCloud) FindCloneBackup(ctx context.Context, fileSystemId, volumeName string) (*cloud.Backup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindCloneBackup", ctx, fileSystemId, volumeName)
+	ret0, _ := ret[0].(*cloud.Backup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindCloneBackup indicates an expected call of FindCloneBackup.
+func (mr *MockCloudMockRecorder) FindCloneBackup(ctx, fileSystemId, volumeName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCloneBackup", reflect.TypeOf((*MockCloud)(nil).FindCloneBackup), ctx, fileSystemId, volumeName)
+}
+
 // FindFileSystemByVolumeName mocks base method.
 func (m *MockCloud) FindFileSystemByVolumeName(ctx context.Context, volumeName string) (*cloud.FileSystem, error) {
 	m.ctrl.T.Helper()
@@ -100,6 +279,97 @@ func (mr *MockCloudMockRecorder) FindFileSystemByVolumeName(ctx, volumeName any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindFileSystemByVolumeName", reflect.TypeOf((*MockCloud)(nil).FindFileSystemByVolumeName), ctx, volumeName)
 }
 
+// FindSecurityGroupsByTags mocks base method.
+func (m *MockCloud) FindSecurityGroupsByTags(ctx context.Context, vpcId string, tags map[string]string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSecurityGroupsByTags", ctx, vpcId, tags)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSecurityGroupsByTags indicates an expected call of FindSecurityGroupsByTags.
+func (mr *MockCloudMockRecorder) FindSecurityGroupsByTags(ctx, vpcId, tags any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSecurityGroupsByTags", reflect.TypeOf((*MockCloud)(nil).FindSecurityGroupsByTags), ctx, vpcId, tags)
+}
+
+// FindSubnetsByTags mocks base method.
+func (m *MockCloud) FindSubnetsByTags(ctx context.Context, tags map[string]string) ([]*cloud.Subnet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSubnetsByTags", ctx, tags)
+	ret0, _ := ret[0].([]*cloud.Subnet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSubnetsByTags indicates an expected call of FindSubnetsByTags.
+func (mr *MockCloudMockRecorder) FindSubnetsByTags(ctx, tags any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSubnetsByTags", reflect.TypeOf((*MockCloud)(nil).FindSubnetsByTags), ctx, tags)
+}
+
+// ListBackups mocks base method.
+func (m *MockCloud) ListBackups(ctx context.Context, fileSystemId string, maxResults int32, nextToken string) ([]*cloud.Backup, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBackups", ctx, fileSystemId, maxResults, nextToken)
+	ret0, _ := ret[0].([]*cloud.Backup)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListBackups indicates an expected call of ListBackups.
+func (mr *MockCloudMockRecorder) ListBackups(ctx, fileSystemId, maxResults, nextToken any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBackups", reflect.TypeOf((*MockCloud)(nil).ListBackups), ctx, fileSystemId, maxResults, nextToken)
+}
+
+// ListDataRepositoryAssociations mocks base method.
+func (m *MockCloud) ListDataRepositoryAssociations(ctx context.Context, fileSystemId string) ([]*cloud.DataRepositoryAssociation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDataRepositoryAssociations", ctx, fileSystemId)
+	ret0, _ := ret[0].([]*cloud.DataRepositoryAssociation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDataRepositoryAssociations indicates an expected call of ListDataRepositoryAssociations.
+func (mr *MockCloudMockRecorder) ListDataRepositoryAssociations(ctx, fileSystemId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDataRepositoryAssociations", reflect.TypeOf((*MockCloud)(nil).ListDataRepositoryAssociations), ctx, fileSystemId)
+}
+
+// ListDataRepositoryTasks mocks base method.
+func (m *MockCloud) ListDataRepositoryTasks(ctx context.Context, fileSystemId string) ([]*cloud.DataRepositoryTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDataRepositoryTasks", ctx, fileSystemId)
+	ret0, _ := ret[0].([]*cloud.DataRepositoryTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDataRepositoryTasks indicates an expected call of ListDataRepositoryTasks.
+func (mr *MockCloudMockRecorder) ListDataRepositoryTasks(ctx, fileSystemId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDataRepositoryTasks", reflect.TypeOf((*MockCloud)(nil).ListDataRepositoryTasks), ctx, fileSystemId)
+}
+
+// ListFileSystems mocks base method.
+func (m *MockCloud) ListFileSystems(ctx context.Context) ([]*cloud.FileSystem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFileSystems", ctx)
+	ret0, _ := ret[0].([]*cloud.FileSystem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFileSystems indicates an expected call of ListFileSystems.
+func (mr *MockCloudMockRecorder) ListFileSystems(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFileSystems", reflect.TypeOf((*MockCloud)(nil).ListFileSystems), ctx)
+}
+
 // ResizeFileSystem mocks base method.
 func (m *MockCloud) ResizeFileSystem(ctx context.Context, fileSystemId string, newSizeGiB int32) (int32, error) {
 	m.ctrl.T.Helper()
@@ -115,6 +385,62 @@ func (mr *MockCloudMockRecorder) ResizeFileSystem(ctx, fileSystemId, newSizeGiB
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResizeFileSystem", reflect.TypeOf((*MockCloud)(nil).ResizeFileSystem), ctx, fileSystemId, newSizeGiB)
 }
 
+// UpdateFileSystem mocks base method.
+func (m *MockCloud) UpdateFileSystem(ctx context.Context, fileSystemId string, updateOptions *cloud.FileSystemUpdateOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFileSystem", ctx, fileSystemId, updateOptions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateFileSystem indicates an expected call of UpdateFileSystem.
+func (mr *MockCloudMockRecorder) UpdateFileSystem(ctx, fileSystemId, updateOptions any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFileSystem", reflect.TypeOf((*MockCloud)(nil).UpdateFileSystem), ctx, fileSystemId, updateOptions)
+}
+
+// WaitForBackupAvailable mocks base method.
+func (m *MockCloud) WaitForBackupAvailable(ctx context.Context, backupId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitForBackupAvailable", ctx, backupId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WaitForBackupAvailable indicates an expected call of WaitForBackupAvailable.
+func (mr *MockCloudMockRecorder) WaitForBackupAvailable(ctx, backupId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForBackupAvailable", reflect.TypeOf((*MockCloud)(nil).WaitForBackupAvailable), ctx, backupId)
+}
+
+// WaitForDataRepositoryAssociationAvailable mocks base method.
+func (m *MockCloud) WaitForDataRepositoryAssociationAvailable(ctx context.Context, associationId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitForDataRepositoryAssociationAvailable", ctx, associationId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WaitForDataRepositoryAssociationAvailable indicates an expected call of WaitForDataRepositoryAssociationAvailable.
+func (mr *MockCloudMockRecorder) WaitForDataRepositoryAssociationAvailable(ctx, associationId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForDataRepositoryAssociationAvailable", reflect.TypeOf((*MockCloud)(nil).WaitForDataRepositoryAssociationAvailable), ctx, associationId)
+}
+
+// WaitForDataRepositoryTask mocks base method.
+func (m *MockCloud) WaitForDataRepositoryTask(ctx context.Context, taskId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitForDataRepositoryTask", ctx, taskId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WaitForDataRepositoryTask indicates an expected call of WaitForDataRepositoryTask.
+func (mr *MockCloudMockRecorder) WaitForDataRepositoryTask(ctx, taskId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForDataRepositoryTask", reflect.TypeOf((*MockCloud)(nil).WaitForDataRepositoryTask), ctx, taskId)
+}
+
 // WaitForFileSystemAvailable mocks base method.
 func (m *MockCloud) WaitForFileSystemAvailable(ctx context.Context, fileSystemId string) error {
 	m.ctrl.T.Helper()
@@ -142,3 +468,17 @@ func (mr *MockCloudMockRecorder) WaitForFileSystemResize(ctx, fileSystemId, resi
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForFileSystemResize", reflect.TypeOf((*MockCloud)(nil).WaitForFileSystemResize), ctx, fileSystemId, resizeGiB)
 }
+
+// WaitForFileSystemUpdate mocks base method.
+func (m *MockCloud) WaitForFileSystemUpdate(ctx context.Context, fileSystemId string, updateOptions *cloud.FileSystemUpdateOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitForFileSystemUpdate", ctx, fileSystemId, updateOptions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WaitForFileSystemUpdate indicates an expected call of WaitForFileSystemUpdate.
+func (mr *MockCloudMockRecorder) WaitForFileSystemUpdate(ctx, fileSystemId, updateOptions any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForFileSystemUpdate", reflect.TypeOf((*MockCloud)(nil).WaitForFileSystemUpdate), ctx, fileSystemId, updateOptions)
+}
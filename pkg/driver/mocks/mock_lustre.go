@@ -0,0 +1,143 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: sigs.k8s.io/aws-fsx-csi-driver/pkg/driver (interfaces: Lustre)
+//
+// Generated by this command:
+//
+//	mockgen -package=mocks -destination=./pkg/driver/mocks/mock_lustre.go --build_flags=--mod=mod sigs.k8s.io/aws-fsx-csi-driver/pkg/driver Lustre
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLustre is a mock of Lustre interface.
+type MockLustre struct {
+	ctrl     *gomock.Controller
+	recorder *MockLustreMockRecorder
+	isgomock struct{}
+}
+
+// MockLustreMockRecorder is the mock recorder for MockLustre.
+type MockLustreMockRecorder struct {
+	mock *MockLustre
+}
+
+// NewMockLustre creates a new mock instance.
+func NewMockLustre(ctrl *gomock.Controller) *MockLustre {
+	mock := &MockLustre{ctrl: ctrl}
+	mock.recorder = &MockLustreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLustre) EXPECT() *MockLustreMockRecorder {
+	return m.recorder
+}
+
+// GetInstance mocks base method.
+func (m *MockLustre) GetInstance(mountPoint string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInstance", mountPoint)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInstance indicates an expected call of GetInstance.
+func (mr *MockLustreMockRecorder) GetInstance(mountPoint any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInstance", reflect.TypeOf((*MockLustre)(nil).GetInstance), mountPoint)
+}
+
+// GetProject mocks base method.
+func (m *MockLustre) GetProject(path string) (uint32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProject", path)
+	ret0, _ := ret[0].(uint32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProject indicates an expected call of GetProject.
+func (mr *MockLustreMockRecorder) GetProject(path any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProject", reflect.TypeOf((*MockLustre)(nil).GetProject), path)
+}
+
+// GetProjectQuota mocks base method.
+func (m *MockLustre) GetProjectQuota(mountPoint string, projectId uint32) (int64, int64, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProjectQuota", mountPoint, projectId)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(int64)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// GetProjectQuota indicates an expected call of GetProjectQuota.
+func (mr *MockLustreMockRecorder) GetProjectQuota(mountPoint, projectId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProjectQuota", reflect.TypeOf((*MockLustre)(nil).GetProjectQuota), mountPoint, projectId)
+}
+
+// RecoverImports mocks base method.
+func (m *MockLustre) RecoverImports(fsName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecoverImports", fsName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecoverImports indicates an expected call of RecoverImports.
+func (mr *MockLustreMockRecorder) RecoverImports(fsName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecoverImports", reflect.TypeOf((*MockLustre)(nil).RecoverImports), fsName)
+}
+
+// SetParam mocks base method.
+func (m *MockLustre) SetParam(name, value string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetParam", name, value)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetParam indicates an expected call of SetParam.
+func (mr *MockLustreMockRecorder) SetParam(name, value any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetParam", reflect.TypeOf((*MockLustre)(nil).SetParam), name, value)
+}
+
+// SetProject mocks base method.
+func (m *MockLustre) SetProject(dir string, projectId uint32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetProject", dir, projectId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetProject indicates an expected call of SetProject.
+func (mr *MockLustreMockRecorder) SetProject(dir, projectId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetProject", reflect.TypeOf((*MockLustre)(nil).SetProject), dir, projectId)
+}
+
+// SetProjectQuota mocks base method.
+func (m *MockLustre) SetProjectQuota(mountPoint string, projectId uint32, limitBytes int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetProjectQuota", mountPoint, projectId, limitBytes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetProjectQuota indicates an expected call of SetProjectQuota.
+func (mr *MockLustreMockRecorder) SetProjectQuota(mountPoint, projectId, limitBytes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetProjectQuota", reflect.TypeOf((*MockLustre)(nil).SetProjectQuota), mountPoint, projectId, limitBytes)
+}
@@ -10,9 +10,11 @@
 package mocks
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "go.uber.org/mock/gomock"
+	unix "golang.org/x/sys/unix"
 	mount "k8s.io/mount-utils"
 )
 
@@ -198,6 +200,20 @@ func (mr *MockMounterMockRecorder) MountSensitiveWithoutSystemdWithMountFlags(so
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MountSensitiveWithoutSystemdWithMountFlags", reflect.TypeOf((*MockMounter)(nil).MountSensitiveWithoutSystemdWithMountFlags), source, target, fstype, options, sensitiveOptions, mountFlags)
 }
 
+// MountWithContext mocks base method.
+func (m *MockMounter) MountWithContext(ctx context.Context, source, target, fstype string, options []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MountWithContext", ctx, source, target, fstype, options)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MountWithContext indicates an expected call of MountWithContext.
+func (mr *MockMounterMockRecorder) MountWithContext(ctx, source, target, fstype, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MountWithContext", reflect.TypeOf((*MockMounter)(nil).MountWithContext), ctx, source, target, fstype, options)
+}
+
 // PathExists mocks base method.
 func (m *MockMounter) PathExists(path string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -213,6 +229,21 @@ func (mr *MockMounterMockRecorder) PathExists(path any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PathExists", reflect.TypeOf((*MockMounter)(nil).PathExists), path)
 }
 
+// Statfs mocks base method.
+func (m *MockMounter) Statfs(path string) (*unix.Statfs_t, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Statfs", path)
+	ret0, _ := ret[0].(*unix.Statfs_t)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Statfs indicates an expected call of Statfs.
+func (mr *MockMounterMockRecorder) Statfs(path any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Statfs", reflect.TypeOf((*MockMounter)(nil).Statfs), path)
+}
+
 // Unmount mocks base method.
 func (m *MockMounter) Unmount(target string) error {
 	m.ctrl.T.Helper()
@@ -226,3 +257,17 @@ func (mr *MockMounterMockRecorder) Unmount(target any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unmount", reflect.TypeOf((*MockMounter)(nil).Unmount), target)
 }
+
+// UnmountWithContext mocks base method.
+func (m *MockMounter) UnmountWithContext(ctx context.Context, target string, flags []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnmountWithContext", ctx, target, flags)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnmountWithContext indicates an expected call of UnmountWithContext.
+func (mr *MockMounterMockRecorder) UnmountWithContext(ctx, target, flags any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnmountWithContext", reflect.TypeOf((*MockMounter)(nil).UnmountWithContext), ctx, target, flags)
+}
@@ -17,8 +17,15 @@ limitations under the License.
 package driver
 
 import (
-	"k8s.io/mount-utils"
+	"context"
+	"errors"
+	"fmt"
 	"os"
+	"strings"
+
+	"golang.org/x/sys/unix"
+	"k8s.io/mount-utils"
+	"k8s.io/utils/exec"
 )
 
 // Mounter is an interface for mount operations
@@ -27,15 +34,20 @@ type Mounter interface {
 	IsCorruptedMnt(err error) bool
 	PathExists(path string) (bool, error)
 	MakeDir(pathname string) error
+	Statfs(path string) (*unix.Statfs_t, error)
+	MountWithContext(ctx context.Context, source string, target string, fstype string, options []string) error
+	UnmountWithContext(ctx context.Context, target string, flags []string) error
 }
 
 type NodeMounter struct {
 	mount.Interface
+	exec exec.Interface
 }
 
 func newNodeMounter() (Mounter, error) {
 	return &NodeMounter{
 		Interface: mount.New(""),
+		exec:      exec.New(),
 	}, nil
 }
 
@@ -49,9 +61,10 @@ func (m *NodeMounter) MakeDir(pathname string) error {
 	return nil
 }
 
-// IsCorruptedMnt return true if err is about corrupted mount point
+// IsCorruptedMnt return true if err is about corrupted mount point, including a Lustre mount of an evicted client that
+// fails with "Cannot send after transport endpoint shutdown"
 func (m *NodeMounter) IsCorruptedMnt(err error) bool {
-	return mount.IsCorruptedMnt(err)
+	return mount.IsCorruptedMnt(err) || errors.Is(err, unix.ESHUTDOWN)
 }
 
 func (m *NodeMounter) PathExists(path string) (bool, error) {
@@ -62,3 +75,64 @@ func (m *NodeMounter) PathExists(path string) (bool, error) {
 	}
 	return true, nil
 }
+
+// Statfs returns the statistics of the filesystem mounted at path
+func (m *NodeMounter) Statfs(path string) (*unix.Statfs_t, error) {
+	var statfs unix.Statfs_t
+	if err := unix.Statfs(path, &statfs); err != nil {
+		return nil, err
+	}
+	return &statfs, nil
+}
+
+// MountWithContext mounts source at target like Mount, but kills the mount helper and returns the error of ctx when
+// ctx is done before the mount completes. The target may be left partially mounted in that case.
+func (m *NodeMounter) MountWithContext(ctx context.Context, source string, target string, fstype string, options []string) error {
+	args := []string{"-t", fstype}
+	if len(options) > 0 {
+		args = append(args, "-o", strings.Join(options, ","))
+	}
+	args = append(args, source, target)
+
+	output, err := m.runWithContext(ctx, "mount", args...)
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("mount failed: %v, mounting arguments: %s, output: %s", err, strings.Join(args, " "), string(output))
+	}
+	return err
+}
+
+// UnmountWithContext unmounts target with umount and flags, e.g. -f or -l, and kills umount and returns the error of ctx
+// when ctx is done before the unmount completes.
+func (m *NodeMounter) UnmountWithContext(ctx context.Context, target string, flags []string) error {
+	args := append(append([]string{}, flags...), target)
+
+	output, err := m.runWithContext(ctx, "umount", args...)
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("unmount failed: %v, unmounting arguments: %s, output: %s", err, strings.Join(args, " "), string(output))
+	}
+	return err
+}
+
+// runWithContext runs a command that is killed when ctx is done, and returns ctx.Err() in that case
+func (m *NodeMounter) runWithContext(ctx context.Context, cmd string, args ...string) ([]byte, error) {
+	type result struct {
+		output []byte
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		output, err := m.exec.CommandContext(ctx, cmd, args...).CombinedOutput()
+		done <- result{output: output, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return r.output, ctxErr
+		}
+		return r.output, r.err
+	case <-ctx.Done():
+		// the command is killed by CommandContext, a command stuck in the kernel is reaped in the background
+		return nil, ctx.Err()
+	}
+}
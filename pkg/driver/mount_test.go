@@ -0,0 +1,184 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/utils/exec"
+	testingexec "k8s.io/utils/exec/testing"
+)
+
+func TestMountWithContext(t *testing.T) {
+	var (
+		source       = "fs-1234.fsx.us-west-2.amazonaws.com@tcp:/random"
+		target       = "/target/path"
+		expectedArgs = []string{"-t", "lustre", "-o", "flock,ro", source, target}
+	)
+
+	testCases := []struct {
+		name        string
+		output      func() ([]byte, []byte, error)
+		timeout     time.Duration
+		expectedErr error
+	}{
+		{
+			name: "success: normal",
+			output: func() ([]byte, []byte, error) {
+				return nil, nil, nil
+			},
+		},
+		{
+			name: "fail: mount failed",
+			output: func() ([]byte, []byte, error) {
+				return []byte("mount.lustre: mount failed"), nil, errors.New("exit status 5")
+			},
+			expectedErr: errors.New("mount failed"),
+		},
+		{
+			name: "fail: mount timed out",
+			output: func() ([]byte, []byte, error) {
+				time.Sleep(time.Second)
+				return nil, nil, errors.New("signal: killed")
+			},
+			timeout:     10 * time.Millisecond,
+			expectedErr: context.DeadlineExceeded,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeExec := &testingexec.FakeExec{
+				CommandScript: []testingexec.FakeCommandAction{
+					func(cmd string, args ...string) exec.Cmd {
+						if cmd != "mount" || !reflect.DeepEqual(args, expectedArgs) {
+							t.Errorf("Command mismatches. actual: %v %v expected: mount %v", cmd, args, expectedArgs)
+						}
+						return &testingexec.FakeCmd{
+							CombinedOutputScript: []testingexec.FakeAction{tc.output},
+						}
+					},
+				},
+			}
+			mounter := &NodeMounter{exec: fakeExec}
+
+			ctx := context.Background()
+			if tc.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+
+			err := mounter.MountWithContext(ctx, source, target, "lustre", []string{"flock", "ro"})
+			switch {
+			case tc.expectedErr == nil && err != nil:
+				t.Fatalf("MountWithContext is failed: %v", err)
+			case tc.expectedErr == context.DeadlineExceeded && !errors.Is(err, context.DeadlineExceeded):
+				t.Fatalf("Unexpected error: %v", err)
+			case tc.expectedErr != nil && err == nil:
+				t.Fatalf("MountWithContext is not failed")
+			}
+		})
+	}
+}
+
+func TestUnmountWithContext(t *testing.T) {
+	var target = "/target/path"
+
+	testCases := []struct {
+		name         string
+		flags        []string
+		expectedArgs []string
+		output       func() ([]byte, []byte, error)
+		timeout      time.Duration
+		expectedErr  error
+	}{
+		{
+			name:         "success: normal",
+			expectedArgs: []string{target},
+			output: func() ([]byte, []byte, error) {
+				return nil, nil, nil
+			},
+		},
+		{
+			name:         "success: lazy unmount",
+			flags:        []string{"-l"},
+			expectedArgs: []string{"-l", target},
+			output: func() ([]byte, []byte, error) {
+				return nil, nil, nil
+			},
+		},
+		{
+			name:         "fail: unmount failed",
+			flags:        []string{"-f"},
+			expectedArgs: []string{"-f", target},
+			output: func() ([]byte, []byte, error) {
+				return []byte("umount: /target/path: target is busy."), nil, errors.New("exit status 32")
+			},
+			expectedErr: errors.New("unmount failed"),
+		},
+		{
+			name:         "fail: unmount timed out",
+			expectedArgs: []string{target},
+			output: func() ([]byte, []byte, error) {
+				time.Sleep(time.Second)
+				return nil, nil, errors.New("signal: killed")
+			},
+			timeout:     10 * time.Millisecond,
+			expectedErr: context.DeadlineExceeded,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeExec := &testingexec.FakeExec{
+				CommandScript: []testingexec.FakeCommandAction{
+					func(cmd string, args ...string) exec.Cmd {
+						if cmd != "umount" || !reflect.DeepEqual(args, tc.expectedArgs) {
+							t.Errorf("Command mismatches. actual: %v %v expected: umount %v", cmd, args, tc.expectedArgs)
+						}
+						return &testingexec.FakeCmd{
+							CombinedOutputScript: []testingexec.FakeAction{tc.output},
+						}
+					},
+				},
+			}
+			mounter := &NodeMounter{exec: fakeExec}
+
+			ctx := context.Background()
+			if tc.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+
+			err := mounter.UnmountWithContext(ctx, target, tc.flags)
+			switch {
+			case tc.expectedErr == nil && err != nil:
+				t.Fatalf("UnmountWithContext is failed: %v", err)
+			case tc.expectedErr == context.DeadlineExceeded && !errors.Is(err, context.DeadlineExceeded):
+				t.Fatalf("Unexpected error: %v", err)
+			case tc.expectedErr != nil && err == nil:
+				t.Fatalf("UnmountWithContext is not failed")
+			}
+		})
+	}
+}
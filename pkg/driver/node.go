@@ -19,8 +19,14 @@ package driver
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -34,11 +40,18 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
+	"k8s.io/mount-utils"
 )
 
 var (
-	nodeCaps = []csi.NodeServiceCapability_RPC_Type{}
+	nodeCaps = []csi.NodeServiceCapability_RPC_Type{
+		csi.NodeServiceCapability_RPC_STAGE_UNSTAGE_VOLUME,
+		csi.NodeServiceCapability_RPC_GET_VOLUME_STATS,
+		csi.NodeServiceCapability_RPC_VOLUME_CONDITION,
+	}
 
 	// taintRemovalBackoff is the exponential backoff configuration for node taint removal
 	taintRemovalBackoff = wait.Backoff{
@@ -46,27 +59,51 @@ var (
 		Factor:   2,
 		Steps:    10, // Max delay = 0.5 * 2^9 = ~4 minutes
 	}
+
+	// lookupHost resolves the DNS name of a filesystem
+	lookupHost = net.LookupHost
 )
 
 // VolumeOperationAlreadyExists is message fmt returned to CO when there is another in-flight call on the given rpcKey
 const VolumeOperationAlreadyExists = "An operation with the given volume=%q and target=%q is already in progress"
 
+// Unmount policies, which control how far the node escalates the unmount of a target that cannot be unmounted
+const (
+	// UnmountPolicyNone only unmounts targets with umount
+	UnmountPolicyNone = "none"
+	// UnmountPolicyForce escalates to umount -f
+	UnmountPolicyForce = "force"
+	// UnmountPolicyLazy escalates to umount -f and then to umount -l, which detaches the target even if it is busy
+	UnmountPolicyLazy = "lazy"
+)
+
 type nodeService struct {
-	mounter       Mounter
+	metadata cloud.MetadataService
+	mounter  Mounter
+	// lustre applies the Lustre client tunables of volumes
+	lustre        Lustre
 	inFlight      *internal.InFlight
 	driverOptions *DriverOptions
+	// mountHealth holds the unhealthy mounts of the node, it is nil when the mount health check is disabled
+	mountHealth *mountHealth
+	// kubeClient and recorder record events on the pods of unhealthy mounts, kubeClient is nil outside of a cluster
+	kubeClient kubernetes.Interface
+	recorder   record.EventRecorder
+	nodeName   string
 	csi.UnimplementedNodeServer
 }
 
-func newNodeService(driverOptions *DriverOptions) nodeService {
-	klog.V(5).InfoS("[Debug] Retrieving node info from metadata service")
+func newNodeService(driverOptions *DriverOptions) (nodeService, error) {
 	region := os.Getenv("AWS_REGION")
+	var metadata cloud.MetadataService
 	if region == "" {
-		klog.InfoS("newNodeService: getting region from metadata")
-		metadata, err := cloud.NewMetadataService(cloud.DefaultEC2MetadataClient, cloud.DefaultKubernetesAPIClient, region)
+		klog.V(5).InfoS("[Debug] Retrieving node info from metadata service")
+		var err error
+		metadata, err = cloud.NewMetadataService(cloud.DefaultEC2MetadataClient, cloud.DefaultKubernetesAPIClient, region)
 		if err != nil {
-			panic(err)
+			return nodeService{}, fmt.Errorf("could not get metadata: %v", err)
 		}
+		klog.InfoS("newNodeService: getting region from metadata")
 		region = metadata.GetRegion()
 	}
 
@@ -74,49 +111,118 @@ func newNodeService(driverOptions *DriverOptions) nodeService {
 
 	nodeMounter, err := newNodeMounter()
 	if err != nil {
-		panic(err)
+		return nodeService{}, err
 	}
 
 	// Remove taint from node to indicate driver startup success
 	// This is done in the background as a goroutine to allow for driver startup
 	go removeTaintInBackground(cloud.DefaultKubernetesAPIClient, removeNotReadyTaint)
 
+	var health *mountHealth
+	if driverOptions.mountHealthCheckInterval > 0 {
+		health = newMountHealth()
+	}
+	kubeClient, recorder := newEventRecorder(cloud.DefaultKubernetesAPIClient)
+
 	return nodeService{
+		metadata:      metadata,
 		mounter:       nodeMounter,
+		lustre:        newNodeLustre(),
 		inFlight:      internal.NewInFlight(),
 		driverOptions: driverOptions,
-	}
+		mountHealth:   health,
+		kubeClient:    kubeClient,
+		recorder:      recorder,
+		nodeName:      os.Getenv("CSI_NODE_NAME"),
+	}, nil
 }
 
 func (d *nodeService) NodeStageVolume(ctx context.Context, req *csi.NodeStageVolumeRequest) (*csi.NodeStageVolumeResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "")
-}
+	klog.V(4).InfoS("NodeStageVolume: called with", "args", util.SanitizeRequest(req))
 
-func (d *nodeService) NodeUnstageVolume(ctx context.Context, req *csi.NodeUnstageVolumeRequest) (*csi.NodeUnstageVolumeResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "")
+	volumeID := req.GetVolumeId()
+	if len(volumeID) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Volume ID not provided")
+	}
+
+	source, err := newVolumeSource(volumeID, req.GetVolumeContext())
+	if err != nil {
+		return nil, err
+	}
+
+	target := req.GetStagingTargetPath()
+	if len(target) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Staging target path not provided")
+	}
+
+	volCap := req.GetVolumeCapability()
+	if volCap == nil {
+		return nil, status.Error(codes.InvalidArgument, "Volume capability not provided")
+	}
+
+	if !isValidVolumeCapabilities([]*csi.VolumeCapability{volCap}) {
+		return nil, status.Error(codes.InvalidArgument, "Volume capability not supported")
+	}
+
+	rpcKey := fmt.Sprintf("%s-%s", volumeID, target)
+	if ok := d.inFlight.Insert(rpcKey); !ok {
+		return nil, status.Errorf(codes.Aborted, VolumeOperationAlreadyExists, volumeID, target)
+	}
+	defer func() {
+		klog.V(4).InfoS("NodeStageVolume: volume operation finished", "rpcKey", rpcKey)
+		d.inFlight.Delete(rpcKey)
+	}()
+
+	// the filesystem is mounted once per node at the staging target path and bind mounted into the pods, so pods
+	// that mount it read-only share the read-write mount and are made read-only by their bind mount
+	if err := d.mountVolume(ctx, source, target, newMountOptions(volCap, false)); err != nil {
+		return nil, err
+	}
+
+	return &csi.NodeStageVolumeResponse{}, nil
 }
 
-func (d *nodeService) NodePublishVolume(ctx context.Context, req *csi.NodePublishVolumeRequest) (*csi.NodePublishVolumeResponse, error) {
-	klog.V(4).InfoS("NodePublishVolume: called with", "args", util.SanitizeRequest(req))
+func (d *nodeService) NodeUnstageVolume(ctx context.Context, req *csi.NodeUnstageVolumeRequest) (*csi.NodeUnstageVolumeResponse, error) {
+	klog.V(4).InfoS("NodeUnstageVolume: called", "args", util.SanitizeRequest(req))
 
 	volumeID := req.GetVolumeId()
 	if len(volumeID) == 0 {
 		return nil, status.Error(codes.InvalidArgument, "Volume ID not provided")
 	}
+	target := req.GetStagingTargetPath()
+	if len(target) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Staging target path not provided")
+	}
 
-	context := req.GetVolumeContext()
-	dnsname := context[volumeContextDnsName]
-	mountname := context[volumeContextMountName]
+	rpcKey := fmt.Sprintf("%s-%s", volumeID, target)
+	if ok := d.inFlight.Insert(rpcKey); !ok {
+		return nil, status.Errorf(codes.Aborted, VolumeOperationAlreadyExists, volumeID, target)
+	}
+	defer func() {
+		klog.V(4).InfoS("NodeUnstageVolume: volume operation finished", "rpcKey", rpcKey)
+		d.inFlight.Delete(rpcKey)
+	}()
 
-	if len(dnsname) == 0 {
-		return nil, status.Error(codes.InvalidArgument, "dnsname is not provided")
+	// volumes that were published before the driver staged volumes have no staging mount, which is skipped
+	if err := d.unmountTarget(target); err != nil {
+		return nil, err
 	}
 
-	if len(mountname) == 0 {
-		mountname = "fsx"
+	return &csi.NodeUnstageVolumeResponse{}, nil
+}
+
+func (d *nodeService) NodePublishVolume(ctx context.Context, req *csi.NodePublishVolumeRequest) (*csi.NodePublishVolumeResponse, error) {
+	klog.V(4).InfoS("NodePublishVolume: called with", "args", util.SanitizeRequest(req))
+
+	volumeID := req.GetVolumeId()
+	if len(volumeID) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Volume ID not provided")
 	}
 
-	source := fmt.Sprintf("%s@tcp:/%s", dnsname, mountname)
+	source, err := newVolumeSource(volumeID, req.GetVolumeContext())
+	if err != nil {
+		return nil, err
+	}
 
 	target := req.GetTargetPath()
 	if len(target) == 0 {
@@ -142,41 +248,47 @@ func (d *nodeService) NodePublishVolume(ctx context.Context, req *csi.NodePublis
 		d.inFlight.Delete(rpcKey)
 	}()
 
-	mountOptions := []string{}
-	if req.GetReadonly() {
-		mountOptions = append(mountOptions, "ro")
+	stagingTarget := req.GetStagingTargetPath()
+	if len(stagingTarget) == 0 {
+		// without a staging target path every pod gets its own mount of the filesystem
+		if err := d.mountVolume(ctx, source, target, newMountOptions(volCap, req.GetReadonly())); err != nil {
+			return nil, err
+		}
+		return &csi.NodePublishVolumeResponse{}, nil
 	}
 
-	if m := volCap.GetMount(); m != nil {
-		hasOption := func(options []string, opt string) bool {
-			for _, o := range options {
-				if o == opt {
-					return true
-				}
-			}
-			return false
-		}
-		for _, f := range m.MountFlags {
-			if !hasOption(mountOptions, f) {
-				mountOptions = append(mountOptions, f)
-			}
-		}
+	mountOptions := []string{"bind"}
+	if req.GetReadonly() {
+		mountOptions = append(mountOptions, "ro")
 	}
 	klog.V(5).InfoS("NodePublishVolume: creating", "dir", target)
 	if err := d.mounter.MakeDir(target); err != nil {
 		return nil, status.Errorf(codes.Internal, "Could not create dir %q: %v", target, err)
 	}
 
-	//Checking if the target directory is already mounted with a volume.
-	mounted, err := d.isMounted(source, target)
+	// the bind mount shows the Lustre device of the staging target path in the mount table
+	stagingMountPoint, err := d.findMountPoint(stagingTarget)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not list mounts: %v", err)
+	}
+	if stagingMountPoint == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "Staging target path %q is not mounted", stagingTarget)
+	}
+
+	mounted, err := d.isMounted(target)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "Could not check if %q is mounted: %v", target, err)
 	}
+	if mounted {
+		if mounted, err = d.verifyMount(target, stagingMountPoint.Device, req.GetReadonly()); err != nil {
+			return nil, err
+		}
+	}
 	if !mounted {
-		klog.V(4).InfoS("NodePublishVolume: mounting", "source", source, "target", target, "mountOptions", mountOptions)
-		if err := d.mounter.Mount(source, target, "lustre", mountOptions); err != nil {
+		klog.V(4).InfoS("NodePublishVolume: bind mounting", "source", stagingTarget, "target", target, "mountOptions", mountOptions)
+		if err := d.mounter.Mount(stagingTarget, target, "", mountOptions); err != nil {
 			os.Remove(target)
-			return nil, status.Errorf(codes.Internal, "Could not mount %q at %q: %v", source, target, err)
+			return nil, status.Errorf(codes.Internal, "Could not bind mount %q at %q: %v", stagingTarget, target, err)
 		}
 		klog.V(5).InfoS("NodePublishVolume: was mounted", "target", target)
 	}
@@ -205,24 +317,98 @@ func (d *nodeService) NodeUnpublishVolume(ctx context.Context, req *csi.NodeUnpu
 		d.inFlight.Delete(rpcKey)
 	}()
 
-	// Check if the target is mounted before unmounting
-	notMnt, _ := d.mounter.IsLikelyNotMountPoint(target)
-	if notMnt {
-		klog.V(5).InfoS("NodeUnpublishVolume: target path not mounted, skipping unmount", "target", target)
-		return &csi.NodeUnpublishVolumeResponse{}, nil
+	if err := d.unmountTarget(target); err != nil {
+		return nil, err
 	}
 
-	klog.V(5).InfoS("NodeUnpublishVolume: unmounting", "target", target)
-	err := d.mounter.Unmount(target)
+	return &csi.NodeUnpublishVolumeResponse{}, nil
+}
+
+func (d *nodeService) NodeGetVolumeStats(ctx context.Context, req *csi.NodeGetVolumeStatsRequest) (*csi.NodeGetVolumeStatsResponse, error) {
+	klog.V(4).InfoS("NodeGetVolumeStats: called", "args", util.SanitizeRequest(req))
+
+	if len(req.GetVolumeId()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Volume ID not provided")
+	}
+	volumePath := req.GetVolumePath()
+	if len(volumePath) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "Volume path not provided")
+	}
+
+	exists, err := d.mounter.PathExists(volumePath)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "Could not unmount %q: %v", target, err)
+		if d.mounter.IsCorruptedMnt(err) {
+			return newAbnormalVolumeStatsResponse(volumePath, err), nil
+		}
+		return nil, status.Errorf(codes.Internal, "Could not check if volume path %q exists: %v", volumePath, err)
+	}
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "Volume path %q not found", volumePath)
 	}
 
-	return &csi.NodeUnpublishVolumeResponse{}, nil
+	// The stats of a volume path that is not mounted are the stats of the filesystem it is on
+	notMnt, err := d.mounter.IsLikelyNotMountPoint(volumePath)
+	if err != nil {
+		if d.mounter.IsCorruptedMnt(err) {
+			return newAbnormalVolumeStatsResponse(volumePath, err), nil
+		}
+		return nil, status.Errorf(codes.Internal, "Could not check if volume path %q is mounted: %v", volumePath, err)
+	}
+	if notMnt {
+		klog.InfoS("NodeGetVolumeStats: volume path is not mounted", "volumePath", volumePath)
+		return &csi.NodeGetVolumeStatsResponse{
+			VolumeCondition: &csi.VolumeCondition{
+				Abnormal: true,
+				Message:  fmt.Sprintf("volume path %q is not mounted", volumePath),
+			},
+		}, nil
+	}
+	if message, unhealthy := d.mountHealth.get(volumePath); unhealthy {
+		return newAbnormalVolumeStatsResponse(volumePath, errors.New(message)), nil
+	}
+
+	statfs, err := d.mounter.Statfs(volumePath)
+	if err != nil {
+		if d.mounter.IsCorruptedMnt(err) {
+			return newAbnormalVolumeStatsResponse(volumePath, err), nil
+		}
+		return nil, status.Errorf(codes.Internal, "Could not get stats of volume path %q: %v", volumePath, err)
+	}
+
+	// Lustre clients report the capacity of all OSTs and the inodes of all MDTs, so the usage of a subdirectory is
+	// the usage of its whole filesystem
+	blockSize := int64(statfs.Bsize)
+	return &csi.NodeGetVolumeStatsResponse{
+		Usage: []*csi.VolumeUsage{
+			{
+				Unit:      csi.VolumeUsage_BYTES,
+				Available: int64(statfs.Bavail) * blockSize,
+				Total:     int64(statfs.Blocks) * blockSize,
+				Used:      (int64(statfs.Blocks) - int64(statfs.Bfree)) * blockSize,
+			},
+			{
+				Unit:      csi.VolumeUsage_INODES,
+				Available: int64(statfs.Ffree),
+				Total:     int64(statfs.Files),
+				Used:      int64(statfs.Files) - int64(statfs.Ffree),
+			},
+		},
+		VolumeCondition: &csi.VolumeCondition{
+			Abnormal: false,
+			Message:  "volume is mounted",
+		},
+	}, nil
 }
 
-func (d *nodeService) NodeGetVolumeStats(ctx context.Context, req *csi.NodeGetVolumeStatsRequest) (*csi.NodeGetVolumeStatsResponse, error) {
-	return nil, status.Error(codes.Unimplemented, "")
+// newAbnormalVolumeStatsResponse reports the volume mounted at volumePath as abnormal because its mount is corrupted.
+func newAbnormalVolumeStatsResponse(volumePath string, err error) *csi.NodeGetVolumeStatsResponse {
+	klog.InfoS("NodeGetVolumeStats: volume path is a corrupted mount", "volumePath", volumePath, "err", err)
+	return &csi.NodeGetVolumeStatsResponse{
+		VolumeCondition: &csi.VolumeCondition{
+			Abnormal: true,
+			Message:  fmt.Sprintf("volume path %q is a corrupted mount: %v", volumePath, err),
+		},
+	}
 }
 
 func (d *nodeService) NodeExpandVolume(ctx context.Context, req *csi.NodeExpandVolumeRequest) (*csi.NodeExpandVolumeResponse, error) {
@@ -246,26 +432,246 @@ func (d *nodeService) NodeGetCapabilities(ctx context.Context, req *csi.NodeGetC
 }
 
 func (d *nodeService) NodeGetInfo(ctx context.Context, _ *csi.NodeGetInfoRequest) (*csi.NodeGetInfoResponse, error) {
-	if id := os.Getenv("CSI_NODE_NAME"); id != "" {
-		klog.InfoS("NodeGetInfo: Got CSI_NODE_NAME from env", "CSI_NODE_NAME", id)
-		return &csi.NodeGetInfoResponse{NodeId: id}, nil
+	nodeID := os.Getenv("CSI_NODE_NAME")
+	metadata, err := d.getMetadata()
+	if err != nil {
+		if nodeID == "" {
+			return nil, status.Errorf(codes.Internal, "Could not get metadata: %v", err)
+		}
+		// the node is still registered without a zone, like before topology was reported
+		klog.ErrorS(err, "NodeGetInfo: could not get metadata, not reporting topology")
+		return &csi.NodeGetInfoResponse{NodeId: nodeID}, nil
+	}
+	if nodeID != "" {
+		klog.InfoS("NodeGetInfo: Got CSI_NODE_NAME from env", "CSI_NODE_NAME", nodeID)
+	} else {
+		klog.InfoS("NodeGetInfo: get node name from metadata")
+		nodeID = metadata.GetInstanceID()
+	}
+
+	// FSx for Lustre filesystems live in a single Availability Zone, so nodes only report the zone they are in
+	var topology *csi.Topology
+	if zone := metadata.GetAvailabilityZone(); zone != "" {
+		topology = &csi.Topology{
+			Segments: map[string]string{TopologyKey: zone},
+		}
 	}
 
-	klog.InfoS("NodeGetInfo: get node name from metadata")
-	region := os.Getenv("AWS_REGION")
-	metadata, err := cloud.NewMetadataService(cloud.DefaultEC2MetadataClient, cloud.DefaultKubernetesAPIClient, region)
+	return &csi.NodeGetInfoResponse{
+		NodeId:             nodeID,
+		AccessibleTopology: topology,
+	}, nil
+}
+
+// getMetadata returns the metadata of the node. The metadata service is only created at startup when the region is
+// not set, otherwise it is created on demand.
+func (d *nodeService) getMetadata() (cloud.MetadataService, error) {
+	if d.metadata != nil {
+		return d.metadata, nil
+	}
+	return cloud.NewMetadataService(cloud.DefaultEC2MetadataClient, cloud.DefaultKubernetesAPIClient, os.Getenv("AWS_REGION"))
+}
+
+// volumeSource represents the Lustre source that a volume is mounted from
+type volumeSource struct {
+	// root is the root of the filesystem, e.g. dnsname@tcp:/mountname
+	root string
+	// subdirectory is the absolute path of the mounted directory of the filesystem, it is empty for the root
+	subdirectory string
+	subpath      *subpathOptions
+	// tunables are the Lustre client tunables that are applied to the mount of the volume
+	tunables map[string]string
+}
+
+func (s *volumeSource) String() string {
+	return s.root + s.subdirectory
+}
+
+// newVolumeSource returns the source of a volume from its ID and its context.
+func newVolumeSource(volumeID string, volumeContext map[string]string) (*volumeSource, error) {
+	dnsname := volumeContext[volumeContextDnsName]
+	mountname := volumeContext[volumeContextMountName]
+
+	if len(dnsname) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "dnsname is not provided")
+	}
+
+	if len(mountname) == 0 {
+		mountname = "fsx"
+	}
+
+	// subdirectory volumes only mount their subdirectory of the filesystem
+	_, subdirectory := parseVolumeId(volumeID)
+	if subdirectory != "" {
+		if err := validateSubdirectory(subdirectory); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	// volumes with a subpath only mount the subpath of the filesystem or of their subdirectory
+	subpath, err := newSubpathOptions(volumeContext)
 	if err != nil {
-		panic(err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if subpath.subpath != "" {
+		subdirectory = path.Join("/", subdirectory, subpath.subpath)
 	}
 
-	return &csi.NodeGetInfoResponse{
-		NodeId: metadata.GetInstanceID(),
+	tunables, err := parseLustreTunables(volumeContext)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return &volumeSource{
+		root:         fmt.Sprintf("%s@tcp:/%s", dnsname, mountname),
+		subdirectory: subdirectory,
+		subpath:      subpath,
+		tunables:     tunables,
 	}, nil
 }
 
+// newMountOptions returns the options to mount a volume with the mount flags of its capability.
+func newMountOptions(volCap *csi.VolumeCapability, readonly bool) []string {
+	mountOptions := []string{}
+	if readonly {
+		mountOptions = append(mountOptions, "ro")
+	}
+
+	if m := volCap.GetMount(); m != nil {
+		for _, f := range m.MountFlags {
+			if !hasMountOption(mountOptions, f) {
+				mountOptions = append(mountOptions, f)
+			}
+		}
+	}
+	return mountOptions
+}
+
+// mountVolume mounts the Lustre source of a volume at target unless target is already mounted. The mount is bounded by
+// ctx and the mount timeout of the driver.
+func (d *nodeService) mountVolume(ctx context.Context, source *volumeSource, target string, mountOptions []string) error {
+	klog.V(5).InfoS("mountVolume: creating", "dir", target)
+	if err := d.mounter.MakeDir(target); err != nil {
+		return status.Errorf(codes.Internal, "Could not create dir %q: %v", target, err)
+	}
+
+	//Checking if the target directory is already mounted with a volume.
+	mounted, err := d.isMounted(target)
+	if err != nil {
+		return status.Errorf(codes.Internal, "Could not check if %q is mounted: %v", target, err)
+	}
+	if mounted {
+		if mounted, err = d.verifyMount(target, source.String(), hasMountOption(mountOptions, "ro")); err != nil {
+			return err
+		}
+	}
+	if !mounted {
+		if source.subpath.create {
+			if err := d.createSubpath(source.root, source.subdirectory, source.subpath); err != nil {
+				return status.Errorf(codes.Internal, "Could not create subpath %q of %q: %v", source.subdirectory, source.root, err)
+			}
+		}
+		klog.V(4).InfoS("mountVolume: mounting", "source", source, "target", target, "mountOptions", mountOptions)
+		if err := d.mountLustre(ctx, source.String(), target, mountOptions); err != nil {
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				// the killed mount helper may have left a partial mount behind
+				if cleanupErr := mount.CleanupMountPoint(target, d.mounter, false); cleanupErr != nil {
+					klog.ErrorS(cleanupErr, "mountVolume: could not clean up target after interrupted mount", "target", target)
+				}
+				if errors.Is(err, context.Canceled) {
+					return status.Errorf(codes.Canceled, "Mounting %q at %q was canceled: %v", source, target, err)
+				}
+				return status.Errorf(codes.DeadlineExceeded, "Timed out mounting %q at %q: %v. Check that the node can reach the filesystem over LNet: "+
+					"the security groups of the filesystem must allow inbound TCP port 988 from the node, and `lctl ping <filesystem IP>@tcp` must succeed on the node", source, target, err)
+			}
+			os.Remove(target)
+			return status.Errorf(codes.Internal, "Could not mount %q at %q: %v", source, target, err)
+		}
+		klog.V(5).InfoS("mountVolume: was mounted", "target", target)
+		if err := d.applyLustreTunables(target, source.tunables); err != nil {
+			if unmountErr := mount.CleanupMountPoint(target, d.mounter, false); unmountErr != nil {
+				klog.ErrorS(unmountErr, "mountVolume: could not unmount target", "target", target)
+			}
+			return status.Errorf(codes.Internal, "Could not apply Lustre tunables to %q: %v", target, err)
+		}
+	}
+	return nil
+}
+
+// applyLustreTunables sets the Lustre client tunables of a volume for the client instance of its mount at target, so
+// that other mounts of the filesystem on the node are not affected.
+func (d *nodeService) applyLustreTunables(target string, tunables map[string]string) error {
+	if len(tunables) == 0 {
+		return nil
+	}
+
+	instance, err := d.lustre.GetInstance(target)
+	if err != nil {
+		return err
+	}
+	for tunable, value := range tunables {
+		param := lustreTunableParam(instance, tunable)
+		klog.V(4).InfoS("mountVolume: setting Lustre tunable", "target", target, "param", param, "value", value)
+		if err := d.lustre.SetParam(param, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unmountTarget unmounts target if it is mounted and removes it.
+func (d *nodeService) unmountTarget(target string) error {
+	// Check if the target is mounted before unmounting, the mount of an evicted client fails the check but must be
+	// unmounted. With an unmount policy the mount table is checked instead, a stat of the mount of an evicted client
+	// may block until the client reconnects.
+	var notMnt bool
+	if d.unmountPolicy() != UnmountPolicyNone {
+		mountPoint, err := d.findMountPoint(target)
+		if err != nil {
+			return status.Errorf(codes.Internal, "Could not list mounts: %v", err)
+		}
+		notMnt = mountPoint == nil
+	} else {
+		var err error
+		notMnt, err = d.mounter.IsLikelyNotMountPoint(target)
+		if err != nil && !os.IsNotExist(err) && d.mounter.IsCorruptedMnt(err) {
+			notMnt = false
+		}
+	}
+	if notMnt {
+		klog.V(5).InfoS("unmountTarget: target path not mounted, skipping unmount", "target", target)
+	} else {
+		klog.V(5).InfoS("unmountTarget: unmounting", "target", target)
+		err := d.unmount(target)
+		if err != nil {
+			return status.Errorf(codes.Internal, "Could not unmount %q: %v", target, err)
+		}
+	}
+
+	// The target path is no longer needed once it is unmounted
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return status.Errorf(codes.Internal, "Could not remove target path %q: %v", target, err)
+	}
+	return nil
+}
+
+// createSubpath mounts the root of the filesystem to create the subpath of a volume if it is missing.
+func (d *nodeService) createSubpath(rootSource string, subpath string, options *subpathOptions) error {
+	return withSourceMounted(d.mounter, rootSource, "fsx-subpath", func(root string) error {
+		dir := filepath.Join(root, subpath)
+		if _, err := os.Stat(dir); err == nil {
+			return nil
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+		klog.V(4).InfoS("NodePublishVolume: creating subpath", "source", rootSource, "subpath", subpath, "mode", options.perms, "uid", options.uid, "gid", options.gid)
+		return makeDirectory(dir, options.perms, options.uid, options.gid)
+	})
+}
+
 // isMounted checks if target is mounted. It does NOT return an error if target
 // doesn't exist.
-func (d *nodeService) isMounted(_ string, target string) (bool, error) {
+func (d *nodeService) isMounted(target string) (bool, error) {
 	/*
 		Checking if it's a mount point using IsLikelyNotMountPoint. There are three different return values,
 		1. true, err when the directory does not exist or corrupted.
@@ -306,6 +712,162 @@ func (d *nodeService) isMounted(_ string, target string) (bool, error) {
 	return !notMnt, nil
 }
 
+// unmount unmounts target. When the unmount fails or does not complete within the unmount timeout, it is escalated to
+// umount -f and umount -l as far as the unmount policy of the driver allows. Each escalation is logged and recorded as
+// an event on the pod of target, or on the node for staging target paths.
+func (d *nodeService) unmount(target string) error {
+	policy := d.unmountPolicy()
+	var timeout time.Duration
+	if d.driverOptions != nil {
+		timeout = d.driverOptions.unmountTimeout
+	}
+	if policy == UnmountPolicyNone {
+		return d.mounter.Unmount(target)
+	}
+
+	steps := [][]string{nil, {"-f"}}
+	if policy == UnmountPolicyLazy {
+		steps = append(steps, []string{"-l"})
+	}
+	var err error
+	for i, flags := range steps {
+		if i > 0 {
+			klog.InfoS("unmount: escalating unmount", "target", target, "flags", flags, "err", err)
+			d.recordTargetEvent(target, corev1.EventTypeWarning, "UnmountEscalated", "Could not unmount %s: %v, retrying with umount %s", target, err, strings.Join(flags, " "))
+		}
+		var ctx context.Context
+		var cancel context.CancelFunc
+		if timeout > 0 {
+			ctx, cancel = context.WithTimeout(context.Background(), timeout)
+		} else {
+			ctx, cancel = context.WithCancel(context.Background())
+		}
+		err = d.mounter.UnmountWithContext(ctx, target, flags)
+		cancel()
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// unmountPolicy returns the unmount policy of the driver
+func (d *nodeService) unmountPolicy() string {
+	if d.driverOptions != nil && d.driverOptions.unmountPolicy != "" {
+		return d.driverOptions.unmountPolicy
+	}
+	return UnmountPolicyNone
+}
+
+// mountLustre mounts the Lustre device source at target. The mount helper is killed and the error of ctx is returned
+// when the mount timeout of the driver is set and the mount does not complete in time or ctx is done.
+func (d *nodeService) mountLustre(ctx context.Context, source string, target string, options []string) error {
+	if d.driverOptions == nil || d.driverOptions.mountTimeout == 0 {
+		return d.mounter.Mount(source, target, "lustre", options)
+	}
+	mountCtx, cancel := context.WithTimeout(ctx, d.driverOptions.mountTimeout)
+	defer cancel()
+	return d.mounter.MountWithContext(mountCtx, source, target, "lustre", options)
+}
+
+// verifyMount checks that the mount at target is a mount of the Lustre device source, e.g. dnsname@tcp:/mountname,
+// that is read-only if and only if readonly is set. A mount that does not match, such as a stale mount that survived a
+// restart of the node plugin, is unmounted so that the caller mounts target again. It returns whether target is still
+// mounted.
+func (d *nodeService) verifyMount(target string, source string, readonly bool) (bool, error) {
+	mountPoint, err := d.findMountPoint(target)
+	if err != nil {
+		return false, status.Errorf(codes.Internal, "Could not list mounts: %v", err)
+	}
+	if mountPoint == nil {
+		// target is not in the mount table, e.g. it is below a mount point, leave it to the mount check
+		return true, nil
+	}
+
+	var mismatch string
+	if !d.lustreDeviceMatches(mountPoint.Device, source) {
+		mismatch = fmt.Sprintf("it is a mount of %q instead of %q", mountPoint.Device, source)
+	} else if hasMountOption(mountPoint.Opts, "ro") != readonly {
+		mismatch = fmt.Sprintf("it is mounted %s instead of %s", mountMode(!readonly), mountMode(readonly))
+	}
+	if mismatch == "" {
+		return true, nil
+	}
+
+	klog.InfoS("Target is already mounted but does not match the volume, remounting", "target", target, "mismatch", mismatch)
+	if err := d.mounter.Unmount(target); err != nil {
+		return false, status.Errorf(codes.FailedPrecondition, "Target %q is already mounted but %s, and could not be unmounted: %v", target, mismatch, err)
+	}
+	return false, nil
+}
+
+// findMountPoint returns the entry of path in the mount table, or nil if path is not a mount point. When mounts are
+// stacked on path the last entry is returned, which is the mount that is visible at path.
+func (d *nodeService) findMountPoint(path string) (*mount.MountPoint, error) {
+	mountPoints, err := d.mounter.List()
+	if err != nil {
+		return nil, err
+	}
+	var found *mount.MountPoint
+	for i := range mountPoints {
+		if mountPoints[i].Path == path {
+			found = &mountPoints[i]
+		}
+	}
+	return found, nil
+}
+
+// lustreDeviceMatches checks if the Lustre device of a mount table entry, e.g. 10.0.1.5@tcp:/mountname, is source,
+// e.g. dnsname@tcp:/mountname. The mount table shows the NIDs of the filesystem instead of its DNS name, so a device
+// matches if the paths are equal and one of its NIDs is an address of the DNS name.
+func (d *nodeService) lustreDeviceMatches(device string, source string) bool {
+	if device == source {
+		return true
+	}
+	deviceNids, devicePath, ok := splitLustreDevice(device)
+	if !ok {
+		return false
+	}
+	sourceNids, sourcePath, ok := splitLustreDevice(source)
+	if !ok || path.Clean(devicePath) != path.Clean(sourcePath) {
+		return false
+	}
+	for _, sourceNid := range sourceNids {
+		host, network, _ := strings.Cut(sourceNid, "@")
+		addresses, err := lookupHost(host)
+		if err != nil {
+			klog.V(4).InfoS("Could not resolve the DNS name of the filesystem", "host", host, "err", err)
+			continue
+		}
+		for _, address := range addresses {
+			if slices.Contains(deviceNids, address+"@"+network) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// splitLustreDevice splits a Lustre device, e.g. 10.0.1.5@tcp:10.0.1.6@tcp:/mountname/dir, into its NIDs and the path
+func splitLustreDevice(device string) ([]string, string, bool) {
+	separator := strings.Index(device, ":/")
+	if separator <= 0 {
+		return nil, "", false
+	}
+	return strings.Split(device[:separator], ":"), device[separator+1:], true
+}
+
+func hasMountOption(options []string, option string) bool {
+	return slices.Contains(options, option)
+}
+
+func mountMode(readonly bool) string {
+	if readonly {
+		return "read-only"
+	}
+	return "read-write"
+}
+
 // Struct for JSON patch operations
 type JSONPatch struct {
 	OP    string      `json:"op,omitempty"`
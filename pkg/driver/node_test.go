@@ -19,16 +19,24 @@ package driver
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
+	"golang.org/x/sys/unix"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/mount-utils"
 	"sigs.k8s.io/aws-fsx-csi-driver/pkg/cloud"
 	"sigs.k8s.io/aws-fsx-csi-driver/pkg/driver/internal"
 	driverMocks "sigs.k8s.io/aws-fsx-csi-driver/pkg/driver/mocks"
@@ -45,6 +53,7 @@ func TestNodePublishVolume(t *testing.T) {
 		mountname     = "random"
 		targetPath    = "/target/path"
 		targetPathAlt = "/target/alt_path"
+		stagingPath   = "/staging/path"
 		stdVolCap     = &csi.VolumeCapability{
 			AccessType: &csi.VolumeCapability_Mount{
 				Mount: &csi.VolumeCapability_MountVolume{},
@@ -490,6 +499,1518 @@ func TestNodePublishVolume(t *testing.T) {
 				mockCtl.Finish()
 			},
 		},
+		{
+			name: "success: subdirectory volume",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+				source := dnsname + "@tcp:/" + mountname + "/teams/pvc-1234"
+
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId: "fs-1234::/teams/pvc-1234",
+					VolumeContext: map[string]string{
+						volumeContextDnsName:   dnsname,
+						volumeContextMountName: mountname,
+					},
+					VolumeCapability: stdVolCap,
+					TargetPath:       targetPath,
+				}
+
+				mockMounter.EXPECT().MakeDir(gomock.Eq(targetPath)).Return(nil)
+				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(targetPath)).Return(true, nil)
+				mockMounter.EXPECT().Mount(gomock.Eq(source), gomock.Eq(targetPath), gomock.Eq("lustre"), gomock.Any()).Return(nil)
+				_, err := driver.NodePublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodePublishVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: subdirectory volume with path traversal",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId: "fs-1234::/teams/../../etc",
+					VolumeContext: map[string]string{
+						volumeContextDnsName:   dnsname,
+						volumeContextMountName: mountname,
+					},
+					VolumeCapability: stdVolCap,
+					TargetPath:       targetPath,
+				}
+
+				_, err := driver.NodePublishVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: subpath",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+				source := dnsname + "@tcp:/" + mountname + "/datasets/team-a"
+
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId: "volumeId",
+					VolumeContext: map[string]string{
+						volumeContextDnsName:   dnsname,
+						volumeContextMountName: mountname,
+						volumeContextSubpath:   "datasets/team-a",
+					},
+					VolumeCapability: stdVolCap,
+					TargetPath:       targetPath,
+				}
+
+				mockMounter.EXPECT().MakeDir(gomock.Eq(targetPath)).Return(nil)
+				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(targetPath)).Return(true, nil)
+				mockMounter.EXPECT().Mount(gomock.Eq(source), gomock.Eq(targetPath), gomock.Eq("lustre"), gomock.Any()).Return(nil)
+				_, err := driver.NodePublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodePublishVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: create missing subpath",
+			testFunc: func(t *testing.T) {
+				mounter := newFakeFileSystemMounter(t)
+				if err := os.MkdirAll(filepath.Join(mounter.root, "datasets"), 0755); err != nil {
+					t.Fatal(err)
+				}
+
+				driver := &nodeService{
+					mounter:  mounter,
+					inFlight: internal.NewInFlight(),
+				}
+				target := filepath.Join(t.TempDir(), "target")
+
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId: "volumeId",
+					VolumeContext: map[string]string{
+						volumeContextDnsName:       dnsname,
+						volumeContextMountName:     mountname,
+						volumeContextSubpath:       "datasets/team-a",
+						volumeContextCreateSubpath: "true",
+						volumeContextSubpathMode:   "0750",
+						volumeContextSubpathUid:    strconv.Itoa(os.Getuid()),
+						volumeContextSubpathGid:    strconv.Itoa(os.Getgid()),
+					},
+					VolumeCapability: stdVolCap,
+					TargetPath:       target,
+				}
+
+				_, err := driver.NodePublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodePublishVolume is failed: %v", err)
+				}
+
+				expectedSources := []string{dnsname + "@tcp:/" + mountname, dnsname + "@tcp:/" + mountname + "/datasets/team-a"}
+				if !reflect.DeepEqual(mounter.sources, expectedSources) {
+					t.Fatalf("Mount sources mismatch. actual: %v expected: %v", mounter.sources, expectedSources)
+				}
+
+				// the fake mounter moves the content of the filesystem to the target of the last mount
+				info, err := os.Stat(filepath.Join(target, "datasets", "team-a"))
+				if err != nil {
+					t.Fatalf("Subpath is not created: %v", err)
+				}
+
+				if info.Mode().Perm() != 0750 {
+					t.Fatalf("Permissions mismatch. actual: %v expected: %v", info.Mode().Perm(), os.FileMode(0750))
+				}
+			},
+		},
+		{
+			name: "fail: subpath with path traversal",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId: "volumeId",
+					VolumeContext: map[string]string{
+						volumeContextDnsName:   dnsname,
+						volumeContextMountName: mountname,
+						volumeContextSubpath:   "datasets/../../etc",
+					},
+					VolumeCapability: stdVolCap,
+					TargetPath:       targetPath,
+				}
+
+				_, err := driver.NodePublishVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: createSubpath without subpath",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId: "volumeId",
+					VolumeContext: map[string]string{
+						volumeContextDnsName:       dnsname,
+						volumeContextMountName:     mountname,
+						volumeContextCreateSubpath: "true",
+					},
+					VolumeCapability: stdVolCap,
+					TargetPath:       targetPath,
+				}
+
+				_, err := driver.NodePublishVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: bind mount staged volume",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId: "volumeId",
+					VolumeContext: map[string]string{
+						volumeContextDnsName:   dnsname,
+						volumeContextMountName: mountname,
+					},
+					VolumeCapability:  stdVolCap,
+					StagingTargetPath: stagingPath,
+					TargetPath:        targetPath,
+				}
+
+				mockMounter.EXPECT().MakeDir(gomock.Eq(targetPath)).Return(nil)
+				mockMounter.EXPECT().List().Return([]mount.MountPoint{{Device: dnsname + "@tcp:/" + mountname, Path: stagingPath, Type: "lustre"}}, nil)
+				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(targetPath)).Return(true, nil)
+				mockMounter.EXPECT().Mount(gomock.Eq(stagingPath), gomock.Eq(targetPath), gomock.Eq(""), gomock.Eq([]string{"bind"})).Return(nil)
+				_, err := driver.NodePublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodePublishVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: read only bind mount of staged volume",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId: "volumeId",
+					VolumeContext: map[string]string{
+						volumeContextDnsName:   dnsname,
+						volumeContextMountName: mountname,
+					},
+					VolumeCapability:  stdVolCap,
+					StagingTargetPath: stagingPath,
+					TargetPath:        targetPath,
+					Readonly:          true,
+				}
+
+				mockMounter.EXPECT().MakeDir(gomock.Eq(targetPath)).Return(nil)
+				mockMounter.EXPECT().List().Return([]mount.MountPoint{{Device: dnsname + "@tcp:/" + mountname, Path: stagingPath, Type: "lustre"}}, nil)
+				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(targetPath)).Return(true, nil)
+				mockMounter.EXPECT().Mount(gomock.Eq(stagingPath), gomock.Eq(targetPath), gomock.Eq(""), gomock.Eq([]string{"bind", "ro"})).Return(nil)
+				_, err := driver.NodePublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodePublishVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: staged volume already bind mounted",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId: "volumeId",
+					VolumeContext: map[string]string{
+						volumeContextDnsName:   dnsname,
+						volumeContextMountName: mountname,
+					},
+					VolumeCapability:  stdVolCap,
+					StagingTargetPath: stagingPath,
+					TargetPath:        targetPath,
+				}
+
+				mountPoints := []mount.MountPoint{
+					{Device: dnsname + "@tcp:/" + mountname, Path: stagingPath, Type: "lustre"},
+					{Device: dnsname + "@tcp:/" + mountname, Path: targetPath, Type: "lustre"},
+				}
+				mockMounter.EXPECT().MakeDir(gomock.Eq(targetPath)).Return(nil)
+				mockMounter.EXPECT().List().Return(mountPoints, nil).Times(2)
+				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(targetPath)).Return(false, nil)
+				_, err := driver.NodePublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodePublishVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: remount target that is a mount of another filesystem",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId: "volumeId",
+					VolumeContext: map[string]string{
+						volumeContextDnsName:   dnsname,
+						volumeContextMountName: mountname,
+					},
+					VolumeCapability:  stdVolCap,
+					StagingTargetPath: stagingPath,
+					TargetPath:        targetPath,
+				}
+
+				mountPoints := []mount.MountPoint{
+					{Device: dnsname + "@tcp:/" + mountname, Path: stagingPath, Type: "lustre"},
+					{Device: "fs-0123456789abcdef0.fsx.us-west-2.amazonaws.com@tcp:/other", Path: targetPath, Type: "lustre"},
+				}
+				mockMounter.EXPECT().MakeDir(gomock.Eq(targetPath)).Return(nil)
+				mockMounter.EXPECT().List().Return(mountPoints, nil).Times(2)
+				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(targetPath)).Return(false, nil)
+				mockMounter.EXPECT().Unmount(gomock.Eq(targetPath)).Return(nil)
+				mockMounter.EXPECT().Mount(gomock.Eq(stagingPath), gomock.Eq(targetPath), gomock.Eq(""), gomock.Eq([]string{"bind"})).Return(nil)
+				_, err := driver.NodePublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodePublishVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: remount read-write target that is requested read only",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId: "volumeId",
+					VolumeContext: map[string]string{
+						volumeContextDnsName:   dnsname,
+						volumeContextMountName: mountname,
+					},
+					VolumeCapability:  stdVolCap,
+					StagingTargetPath: stagingPath,
+					TargetPath:        targetPath,
+					Readonly:          true,
+				}
+
+				mountPoints := []mount.MountPoint{
+					{Device: dnsname + "@tcp:/" + mountname, Path: stagingPath, Type: "lustre"},
+					{Device: dnsname + "@tcp:/" + mountname, Path: targetPath, Type: "lustre", Opts: []string{"rw"}},
+				}
+				mockMounter.EXPECT().MakeDir(gomock.Eq(targetPath)).Return(nil)
+				mockMounter.EXPECT().List().Return(mountPoints, nil).Times(2)
+				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(targetPath)).Return(false, nil)
+				mockMounter.EXPECT().Unmount(gomock.Eq(targetPath)).Return(nil)
+				mockMounter.EXPECT().Mount(gomock.Eq(stagingPath), gomock.Eq(targetPath), gomock.Eq(""), gomock.Eq([]string{"bind", "ro"})).Return(nil)
+				_, err := driver.NodePublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodePublishVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: target that does not match could not be unmounted",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId: "volumeId",
+					VolumeContext: map[string]string{
+						volumeContextDnsName:   dnsname,
+						volumeContextMountName: mountname,
+					},
+					VolumeCapability:  stdVolCap,
+					StagingTargetPath: stagingPath,
+					TargetPath:        targetPath,
+				}
+
+				mountPoints := []mount.MountPoint{
+					{Device: dnsname + "@tcp:/" + mountname, Path: stagingPath, Type: "lustre"},
+					{Device: "fs-0123456789abcdef0.fsx.us-west-2.amazonaws.com@tcp:/other", Path: targetPath, Type: "lustre"},
+				}
+				mockMounter.EXPECT().MakeDir(gomock.Eq(targetPath)).Return(nil)
+				mockMounter.EXPECT().List().Return(mountPoints, nil).Times(2)
+				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(targetPath)).Return(false, nil)
+				mockMounter.EXPECT().Unmount(gomock.Eq(targetPath)).Return(fmt.Errorf("target is busy"))
+				_, err := driver.NodePublishVolume(ctx, req)
+				expectErr(t, err, codes.FailedPrecondition)
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: staging target path is not mounted",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+
+				ctx := context.Background()
+				req := &csi.NodePublishVolumeRequest{
+					VolumeId: "volumeId",
+					VolumeContext: map[string]string{
+						volumeContextDnsName:   dnsname,
+						volumeContextMountName: mountname,
+					},
+					VolumeCapability:  stdVolCap,
+					StagingTargetPath: stagingPath,
+					TargetPath:        targetPath,
+				}
+
+				mountPoints := []mount.MountPoint{}
+				mockMounter.EXPECT().MakeDir(gomock.Eq(targetPath)).Return(nil)
+				mockMounter.EXPECT().List().Return(mountPoints, nil)
+				_, err := driver.NodePublishVolume(ctx, req)
+				expectErr(t, err, codes.FailedPrecondition)
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestNodeUnpublishVolume(t *testing.T) {
+
+	var (
+		targetPath    = "/target/path"
+		targetPathAlt = "/target/alt_path"
+	)
+
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: normal",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+
+				ctx := context.Background()
+				req := &csi.NodeUnpublishVolumeRequest{
+					VolumeId:   "volumeId",
+					TargetPath: targetPath,
+				}
+
+				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(targetPath)).Return(false, nil)
+				mockMounter.EXPECT().Unmount(gomock.Eq(targetPath)).Return(nil)
+
+				_, err := driver.NodeUnpublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodeUnpublishVolume is failed: %v", err)
+				}
+			},
+		},
+		{
+			name: "success: target already unmounted",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+
+				ctx := context.Background()
+				req := &csi.NodeUnpublishVolumeRequest{
+					VolumeId:   "volumeId",
+					TargetPath: targetPath,
+				}
+
+				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(targetPath)).Return(true, nil)
+
+				_, err := driver.NodeUnpublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodeUnpublishVolume is failed: %v", err)
+				}
+			},
+		},
+		{
+			name: "fail: targetPath is missing",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+
+				ctx := context.Background()
+				req := &csi.NodeUnpublishVolumeRequest{
+					VolumeId: "volumeId",
+				}
+
+				_, err := driver.NodeUnpublishVolume(ctx, req)
+				if err == nil {
+					t.Fatalf("NodeUnpublishVolume is not failed: %v", err)
+				}
+			},
+		},
+		{
+			name: "fail: mounter failed to umount",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+
+				ctx := context.Background()
+				req := &csi.NodeUnpublishVolumeRequest{
+					VolumeId:   "volumeId",
+					TargetPath: targetPath,
+				}
+
+				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(targetPath)).Return(false, nil)
+				mountErr := fmt.Errorf("Unmount failed")
+				mockMounter.EXPECT().Unmount(gomock.Eq(targetPath)).Return(mountErr)
+
+				_, err := driver.NodeUnpublishVolume(ctx, req)
+				if err == nil {
+					t.Fatalf("NodeUnpublishVolume is not failed: %v", err)
+				}
+			},
+		},
+		{
+			name: "fail another operation in-flight on given volumeId-targetPath",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				awsDriver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+
+				req := &csi.NodeUnpublishVolumeRequest{
+					VolumeId:   volumeID,
+					TargetPath: targetPath,
+				}
+
+				rpcKey := fmt.Sprintf("%s-%s", volumeID, targetPath)
+
+				awsDriver.inFlight.Insert(rpcKey)
+				_, err := awsDriver.NodeUnpublishVolume(context.TODO(), req)
+				expectErr(t, err, codes.Aborted)
+			},
+		},
+		{
+			name: "success: operation in-flight with different volumeId-targetPath",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				awsDriver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+
+				ctx := context.Background()
+				req := &csi.NodeUnpublishVolumeRequest{
+					VolumeId:   "volumeId",
+					TargetPath: targetPath,
+				}
+
+				rpcKeyAlt := fmt.Sprintf("%s-%s", volumeID, targetPathAlt)
+				awsDriver.inFlight.Insert(rpcKeyAlt)
+
+				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(targetPath)).Return(false, nil)
+				mockMounter.EXPECT().Unmount(gomock.Eq(targetPath)).Return(nil)
+
+				_, err := awsDriver.NodeUnpublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodeUnpublishVolume is failed: %v", err)
+				}
+			},
+		},
+		{
+			name: "success: unmount escalated to force unmount",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				recorder := record.NewFakeRecorder(10)
+				driver := &nodeService{
+					mounter:       mockMounter,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{unmountPolicy: UnmountPolicyForce, unmountTimeout: time.Second},
+					recorder:      recorder,
+					nodeName:      "test-node",
+				}
+
+				ctx := context.Background()
+				req := &csi.NodeUnpublishVolumeRequest{
+					VolumeId:   "volumeId",
+					TargetPath: targetPath,
+				}
+
+				mockMounter.EXPECT().List().Return([]mount.MountPoint{{Device: "fs.fsx.us-west-2.amazonaws.com@tcp:/random", Path: targetPath, Type: "lustre"}}, nil)
+				gomock.InOrder(
+					mockMounter.EXPECT().UnmountWithContext(gomock.Any(), gomock.Eq(targetPath), gomock.Nil()).Return(fmt.Errorf("target is busy")),
+					mockMounter.EXPECT().UnmountWithContext(gomock.Any(), gomock.Eq(targetPath), gomock.Eq([]string{"-f"})).Return(nil),
+				)
+				_, err := driver.NodeUnpublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodeUnpublishVolume is failed: %v", err)
+				}
+				if len(recorder.Events) != 1 {
+					t.Fatalf("Unexpected number of UnmountEscalated events: %d, expected: 1", len(recorder.Events))
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: unmount escalated to lazy unmount",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				recorder := record.NewFakeRecorder(10)
+				driver := &nodeService{
+					mounter:       mockMounter,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{unmountPolicy: UnmountPolicyLazy, unmountTimeout: time.Second},
+					recorder:      recorder,
+					nodeName:      "test-node",
+				}
+
+				ctx := context.Background()
+				req := &csi.NodeUnpublishVolumeRequest{
+					VolumeId:   "volumeId",
+					TargetPath: targetPath,
+				}
+
+				mockMounter.EXPECT().List().Return([]mount.MountPoint{{Device: "fs.fsx.us-west-2.amazonaws.com@tcp:/random", Path: targetPath, Type: "lustre"}}, nil)
+				gomock.InOrder(
+					mockMounter.EXPECT().UnmountWithContext(gomock.Any(), gomock.Eq(targetPath), gomock.Nil()).Return(context.DeadlineExceeded),
+					mockMounter.EXPECT().UnmountWithContext(gomock.Any(), gomock.Eq(targetPath), gomock.Eq([]string{"-f"})).Return(fmt.Errorf("target is busy")),
+					mockMounter.EXPECT().UnmountWithContext(gomock.Any(), gomock.Eq(targetPath), gomock.Eq([]string{"-l"})).Return(nil),
+				)
+				_, err := driver.NodeUnpublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodeUnpublishVolume is failed: %v", err)
+				}
+				if len(recorder.Events) != 2 {
+					t.Fatalf("Unexpected number of UnmountEscalated events: %d, expected: 2", len(recorder.Events))
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: evicted mount is unmounted",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				recorder := record.NewFakeRecorder(10)
+				driver := &nodeService{
+					mounter:       mockMounter,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{unmountPolicy: UnmountPolicyForce, unmountTimeout: time.Second},
+					recorder:      recorder,
+					nodeName:      "test-node",
+				}
+
+				ctx := context.Background()
+				req := &csi.NodeUnpublishVolumeRequest{
+					VolumeId:   "volumeId",
+					TargetPath: targetPath,
+				}
+
+				// the mount table is checked instead of the target, whose stat would block
+				mockMounter.EXPECT().List().Return([]mount.MountPoint{{Device: "fs.fsx.us-west-2.amazonaws.com@tcp:/random", Path: targetPath, Type: "lustre"}}, nil)
+				mockMounter.EXPECT().UnmountWithContext(gomock.Any(), gomock.Eq(targetPath), gomock.Nil()).Return(nil)
+				_, err := driver.NodeUnpublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodeUnpublishVolume is failed: %v", err)
+				}
+				if len(recorder.Events) != 0 {
+					t.Fatalf("Unexpected number of UnmountEscalated events: %d, expected: 0", len(recorder.Events))
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: target not in mount table is not unmounted with unmount policy",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:       mockMounter,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{unmountPolicy: UnmountPolicyForce, unmountTimeout: time.Second},
+				}
+
+				ctx := context.Background()
+				req := &csi.NodeUnpublishVolumeRequest{
+					VolumeId:   "volumeId",
+					TargetPath: targetPath,
+				}
+
+				mockMounter.EXPECT().List().Return([]mount.MountPoint{{Device: "/dev/nvme0n1p1", Path: "/", Type: "xfs"}}, nil)
+				_, err := driver.NodeUnpublishVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodeUnpublishVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: force unmount failed",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				recorder := record.NewFakeRecorder(10)
+				driver := &nodeService{
+					mounter:       mockMounter,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{unmountPolicy: UnmountPolicyForce, unmountTimeout: time.Second},
+					recorder:      recorder,
+					nodeName:      "test-node",
+				}
+
+				ctx := context.Background()
+				req := &csi.NodeUnpublishVolumeRequest{
+					VolumeId:   "volumeId",
+					TargetPath: targetPath,
+				}
+
+				mockMounter.EXPECT().List().Return([]mount.MountPoint{{Device: "fs.fsx.us-west-2.amazonaws.com@tcp:/random", Path: targetPath, Type: "lustre"}}, nil)
+				gomock.InOrder(
+					mockMounter.EXPECT().UnmountWithContext(gomock.Any(), gomock.Eq(targetPath), gomock.Nil()).Return(fmt.Errorf("target is busy")),
+					mockMounter.EXPECT().UnmountWithContext(gomock.Any(), gomock.Eq(targetPath), gomock.Eq([]string{"-f"})).Return(fmt.Errorf("target is busy")),
+				)
+				_, err := driver.NodeUnpublishVolume(ctx, req)
+				expectErr(t, err, codes.Internal)
+				if len(recorder.Events) != 1 {
+					t.Fatalf("Unexpected number of UnmountEscalated events: %d, expected: 1", len(recorder.Events))
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestNodeStageVolume(t *testing.T) {
+
+	var (
+		dnsname     = "fs-0a2d0632b5ff567e9.fsx.us-west-2.amazonaws.com"
+		mountname   = "random"
+		stagingPath = "/staging/path"
+		stdVolCap   = &csi.VolumeCapability{
+			AccessType: &csi.VolumeCapability_Mount{
+				Mount: &csi.VolumeCapability_MountVolume{
+					MountFlags: []string{"flock"},
+				},
+			},
+			AccessMode: &csi.VolumeCapability_AccessMode{
+				Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+			},
+		}
+	)
+
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: normal",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+				source := dnsname + "@tcp:/" + mountname
+
+				ctx := context.Background()
+				req := &csi.NodeStageVolumeRequest{
+					VolumeId: "volumeId",
+					VolumeContext: map[string]string{
+						volumeContextDnsName:   dnsname,
+						volumeContextMountName: mountname,
+					},
+					VolumeCapability:  stdVolCap,
+					StagingTargetPath: stagingPath,
+				}
+
+				mockMounter.EXPECT().MakeDir(gomock.Eq(stagingPath)).Return(nil)
+				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(stagingPath)).Return(true, nil)
+				mockMounter.EXPECT().Mount(gomock.Eq(source), gomock.Eq(stagingPath), gomock.Eq("lustre"), gomock.Eq([]string{"flock"})).Return(nil)
+				_, err := driver.NodeStageVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodeStageVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: subdirectory volume with subpath",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+				source := dnsname + "@tcp:/" + mountname + "/teams/pvc-1234/data"
+
+				ctx := context.Background()
+				req := &csi.NodeStageVolumeRequest{
+					VolumeId: "fs-1234::/teams/pvc-1234",
+					VolumeContext: map[string]string{
+						volumeContextDnsName:   dnsname,
+						volumeContextMountName: mountname,
+						volumeContextSubpath:   "data",
+					},
+					VolumeCapability:  stdVolCap,
+					StagingTargetPath: stagingPath,
+				}
+
+				mockMounter.EXPECT().MakeDir(gomock.Eq(stagingPath)).Return(nil)
+				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(stagingPath)).Return(true, nil)
+				mockMounter.EXPECT().Mount(gomock.Eq(source), gomock.Eq(stagingPath), gomock.Eq("lustre"), gomock.Any()).Return(nil)
+				_, err := driver.NodeStageVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodeStageVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: already staged",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+
+				ctx := context.Background()
+				req := &csi.NodeStageVolumeRequest{
+					VolumeId: "volumeId",
+					VolumeContext: map[string]string{
+						volumeContextDnsName:   dnsname,
+						volumeContextMountName: mountname,
+					},
+					VolumeCapability:  stdVolCap,
+					StagingTargetPath: stagingPath,
+				}
+
+				mockMounter.EXPECT().MakeDir(gomock.Eq(stagingPath)).Return(nil)
+				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(stagingPath)).Return(false, nil)
+				mockMounter.EXPECT().List().Return([]mount.MountPoint{{Device: dnsname + "@tcp:/" + mountname, Path: stagingPath, Type: "lustre"}}, nil)
+				_, err := driver.NodeStageVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodeStageVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: already staged with the address of the filesystem",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+
+				defer func(original func(string) ([]string, error)) { lookupHost = original }(lookupHost)
+				lookupHost = func(host string) ([]string, error) {
+					if host != dnsname {
+						return nil, fmt.Errorf("unexpected host %q", host)
+					}
+					return []string{"10.0.1.5"}, nil
+				}
+
+				ctx := context.Background()
+				req := &csi.NodeStageVolumeRequest{
+					VolumeId: "volumeId",
+					VolumeContext: map[string]string{
+						volumeContextDnsName:   dnsname,
+						volumeContextMountName: mountname,
+					},
+					VolumeCapability:  stdVolCap,
+					StagingTargetPath: stagingPath,
+				}
+
+				mockMounter.EXPECT().MakeDir(gomock.Eq(stagingPath)).Return(nil)
+				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(stagingPath)).Return(false, nil)
+				mockMounter.EXPECT().List().Return([]mount.MountPoint{{Device: "10.0.1.5@tcp:/" + mountname, Path: stagingPath, Type: "lustre"}}, nil)
+				_, err := driver.NodeStageVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodeStageVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: remount staging target that is a mount of another filesystem",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+
+				defer func(original func(string) ([]string, error)) { lookupHost = original }(lookupHost)
+				lookupHost = func(host string) ([]string, error) {
+					if host != dnsname {
+						return nil, fmt.Errorf("unexpected host %q", host)
+					}
+					return []string{"10.0.1.5"}, nil
+				}
+
+				ctx := context.Background()
+				req := &csi.NodeStageVolumeRequest{
+					VolumeId: "volumeId",
+					VolumeContext: map[string]string{
+						volumeContextDnsName:   dnsname,
+						volumeContextMountName: mountname,
+					},
+					VolumeCapability:  stdVolCap,
+					StagingTargetPath: stagingPath,
+				}
+
+				mockMounter.EXPECT().MakeDir(gomock.Eq(stagingPath)).Return(nil)
+				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(stagingPath)).Return(false, nil)
+				mockMounter.EXPECT().List().Return([]mount.MountPoint{{Device: "10.0.1.6@tcp:/" + mountname, Path: stagingPath, Type: "lustre"}}, nil)
+				mockMounter.EXPECT().Unmount(gomock.Eq(stagingPath)).Return(nil)
+				mockMounter.EXPECT().Mount(gomock.Eq(dnsname+"@tcp:/"+mountname), gomock.Eq(stagingPath), gomock.Eq("lustre"), gomock.Eq([]string{"flock"})).Return(nil)
+				_, err := driver.NodeStageVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodeStageVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: missing staging target path",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+
+				ctx := context.Background()
+				req := &csi.NodeStageVolumeRequest{
+					VolumeId: "volumeId",
+					VolumeContext: map[string]string{
+						volumeContextDnsName:   dnsname,
+						volumeContextMountName: mountname,
+					},
+					VolumeCapability: stdVolCap,
+				}
+
+				_, err := driver.NodeStageVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: missing dns name",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+
+				ctx := context.Background()
+				req := &csi.NodeStageVolumeRequest{
+					VolumeId:          "volumeId",
+					VolumeCapability:  stdVolCap,
+					StagingTargetPath: stagingPath,
+				}
+
+				_, err := driver.NodeStageVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: mounter failed to Mount",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+				source := dnsname + "@tcp:/" + mountname
+
+				ctx := context.Background()
+				req := &csi.NodeStageVolumeRequest{
+					VolumeId: "volumeId",
+					VolumeContext: map[string]string{
+						volumeContextDnsName:   dnsname,
+						volumeContextMountName: mountname,
+					},
+					VolumeCapability:  stdVolCap,
+					StagingTargetPath: stagingPath,
+				}
+
+				mockMounter.EXPECT().MakeDir(gomock.Eq(stagingPath)).Return(nil)
+				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(stagingPath)).Return(true, nil)
+				mockMounter.EXPECT().Mount(gomock.Eq(source), gomock.Eq(stagingPath), gomock.Eq("lustre"), gomock.Any()).Return(fmt.Errorf("failed to Mount"))
+				_, err := driver.NodeStageVolume(ctx, req)
+				if status.Code(err) != codes.Internal {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: Lustre tunables",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+				mockLustre := driverMocks.NewMockLustre(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					lustre:   mockLustre,
+					inFlight: internal.NewInFlight(),
+				}
+				source := dnsname + "@tcp:/" + mountname
+
+				ctx := context.Background()
+				req := &csi.NodeStageVolumeRequest{
+					VolumeId: "volumeId",
+					VolumeContext: map[string]string{
+						volumeContextDnsName:                           dnsname,
+						volumeContextMountName:                         mountname,
+						lustreTunablePrefix + "llite.max_cached_mb":    "32",
+						lustreTunablePrefix + "osc.max_rpcs_in_flight": "64",
+					},
+					VolumeCapability:  stdVolCap,
+					StagingTargetPath: stagingPath,
+				}
+
+				mockMounter.EXPECT().MakeDir(gomock.Eq(stagingPath)).Return(nil)
+				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(stagingPath)).Return(true, nil)
+				mockMounter.EXPECT().Mount(gomock.Eq(source), gomock.Eq(stagingPath), gomock.Eq("lustre"), gomock.Any()).Return(nil)
+				mockLustre.EXPECT().GetInstance(gomock.Eq(stagingPath)).Return("fsx-ffff8e3a1c2d3000", nil)
+				mockLustre.EXPECT().SetParam(gomock.Eq("llite.fsx-ffff8e3a1c2d3000.max_cached_mb"), gomock.Eq("32")).Return(nil)
+				mockLustre.EXPECT().SetParam(gomock.Eq("osc.fsx-OST*-osc-ffff8e3a1c2d3000.max_rpcs_in_flight"), gomock.Eq("64")).Return(nil)
+				_, err := driver.NodeStageVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodeStageVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: unsupported Lustre tunable",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					lustre:   driverMocks.NewMockLustre(mockCtl),
+					inFlight: internal.NewInFlight(),
+				}
+
+				ctx := context.Background()
+				req := &csi.NodeStageVolumeRequest{
+					VolumeId: "volumeId",
+					VolumeContext: map[string]string{
+						volumeContextDnsName:                             dnsname,
+						volumeContextMountName:                           mountname,
+						lustreTunablePrefix + "ldlm.namespaces.lru_size": "100",
+					},
+					VolumeCapability:  stdVolCap,
+					StagingTargetPath: stagingPath,
+				}
+
+				_, err := driver.NodeStageVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: Lustre tunable not applied",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+				mockLustre := driverMocks.NewMockLustre(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					lustre:   mockLustre,
+					inFlight: internal.NewInFlight(),
+				}
+				source := dnsname + "@tcp:/" + mountname
+
+				ctx := context.Background()
+				req := &csi.NodeStageVolumeRequest{
+					VolumeId: "volumeId",
+					VolumeContext: map[string]string{
+						volumeContextDnsName:                        dnsname,
+						volumeContextMountName:                      mountname,
+						lustreTunablePrefix + "llite.max_cached_mb": "32",
+					},
+					VolumeCapability:  stdVolCap,
+					StagingTargetPath: stagingPath,
+				}
+
+				mockMounter.EXPECT().MakeDir(gomock.Eq(stagingPath)).Return(nil)
+				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(stagingPath)).Return(true, nil)
+				mockMounter.EXPECT().Mount(gomock.Eq(source), gomock.Eq(stagingPath), gomock.Eq("lustre"), gomock.Any()).Return(nil)
+				mockLustre.EXPECT().GetInstance(gomock.Eq(stagingPath)).Return("fsx-ffff8e3a1c2d3000", nil)
+				mockLustre.EXPECT().SetParam(gomock.Any(), gomock.Any()).Return(fmt.Errorf("permission denied"))
+				_, err := driver.NodeStageVolume(ctx, req)
+				if status.Code(err) != codes.Internal {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: mount timed out",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:       mockMounter,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{mountTimeout: 10 * time.Millisecond},
+				}
+				source := dnsname + "@tcp:/" + mountname
+
+				ctx := context.Background()
+				req := &csi.NodeStageVolumeRequest{
+					VolumeId: "volumeId",
+					VolumeContext: map[string]string{
+						volumeContextDnsName:   dnsname,
+						volumeContextMountName: mountname,
+					},
+					VolumeCapability:  stdVolCap,
+					StagingTargetPath: stagingPath,
+				}
+
+				mockMounter.EXPECT().MakeDir(gomock.Eq(stagingPath)).Return(nil)
+				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(stagingPath)).Return(true, nil)
+				mockMounter.EXPECT().MountWithContext(gomock.Any(), gomock.Eq(source), gomock.Eq(stagingPath), gomock.Eq("lustre"), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, source string, target string, fstype string, options []string) error {
+						// the MGS is unreachable, so the mount hangs until it is killed
+						<-ctx.Done()
+						return ctx.Err()
+					})
+				_, err := driver.NodeStageVolume(ctx, req)
+				if status.Code(err) != codes.DeadlineExceeded {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if !strings.Contains(err.Error(), "port 988") {
+					t.Fatalf("Error does not contain the LNet hint: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: mount canceled",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:       mockMounter,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{mountTimeout: time.Minute},
+				}
+				source := dnsname + "@tcp:/" + mountname
+
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				req := &csi.NodeStageVolumeRequest{
+					VolumeId: "volumeId",
+					VolumeContext: map[string]string{
+						volumeContextDnsName:   dnsname,
+						volumeContextMountName: mountname,
+					},
+					VolumeCapability:  stdVolCap,
+					StagingTargetPath: stagingPath,
+				}
+
+				mockMounter.EXPECT().MakeDir(gomock.Eq(stagingPath)).Return(nil)
+				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(stagingPath)).Return(true, nil)
+				mockMounter.EXPECT().MountWithContext(gomock.Any(), gomock.Eq(source), gomock.Eq(stagingPath), gomock.Eq("lustre"), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, source string, target string, fstype string, options []string) error {
+						// the request is canceled while the mount hangs
+						cancel()
+						<-ctx.Done()
+						return ctx.Err()
+					})
+				_, err := driver.NodeStageVolume(ctx, req)
+				if status.Code(err) != codes.Canceled {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if strings.Contains(err.Error(), "port 988") {
+					t.Fatalf("Error of canceled mount contains the LNet hint: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestNodeUnstageVolume(t *testing.T) {
+
+	var (
+		stagingPath = "/staging/path"
+	)
+
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: normal",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+
+				ctx := context.Background()
+				req := &csi.NodeUnstageVolumeRequest{
+					VolumeId:          "volumeId",
+					StagingTargetPath: stagingPath,
+				}
+
+				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(stagingPath)).Return(false, nil)
+				mockMounter.EXPECT().Unmount(gomock.Eq(stagingPath)).Return(nil)
+
+				_, err := driver.NodeUnstageVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodeUnstageVolume is failed: %v", err)
+				}
+			},
+		},
+		{
+			name: "success: volume published without staging",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+
+				ctx := context.Background()
+				req := &csi.NodeUnstageVolumeRequest{
+					VolumeId:          "volumeId",
+					StagingTargetPath: stagingPath,
+				}
+
+				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(stagingPath)).Return(true, os.ErrNotExist)
+
+				_, err := driver.NodeUnstageVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("NodeUnstageVolume is failed: %v", err)
+				}
+			},
+		},
+		{
+			name: "fail: staging target path is missing",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+
+				ctx := context.Background()
+				req := &csi.NodeUnstageVolumeRequest{
+					VolumeId: "volumeId",
+				}
+
+				_, err := driver.NodeUnstageVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			},
+		},
+		{
+			name: "fail: mounter failed to umount",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+
+				ctx := context.Background()
+				req := &csi.NodeUnstageVolumeRequest{
+					VolumeId:          "volumeId",
+					StagingTargetPath: stagingPath,
+				}
+
+				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(stagingPath)).Return(false, nil)
+				mockMounter.EXPECT().Unmount(gomock.Eq(stagingPath)).Return(fmt.Errorf("device busy"))
+
+				_, err := driver.NodeUnstageVolume(ctx, req)
+				if status.Code(err) != codes.Internal {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -497,11 +2018,18 @@ func TestNodePublishVolume(t *testing.T) {
 	}
 }
 
-func TestNodeUnpublishVolume(t *testing.T) {
+func TestNodeGetVolumeStats(t *testing.T) {
 
 	var (
-		targetPath    = "/target/path"
-		targetPathAlt = "/target/alt_path"
+		volumePath = "/target/path"
+		statfs     = &unix.Statfs_t{
+			Bsize:  4096,
+			Blocks: 1000,
+			Bfree:  800,
+			Bavail: 700,
+			Files:  100,
+			Ffree:  60,
+		}
 	)
 
 	testCases := []struct {
@@ -522,22 +2050,45 @@ func TestNodeUnpublishVolume(t *testing.T) {
 				}
 
 				ctx := context.Background()
-				req := &csi.NodeUnpublishVolumeRequest{
+				req := &csi.NodeGetVolumeStatsRequest{
 					VolumeId:   "volumeId",
-					TargetPath: targetPath,
+					VolumePath: volumePath,
 				}
 
-				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(targetPath)).Return(false, nil)
-				mockMounter.EXPECT().Unmount(gomock.Eq(targetPath)).Return(nil)
+				mockMounter.EXPECT().PathExists(gomock.Eq(volumePath)).Return(true, nil)
+				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(volumePath)).Return(false, nil)
+				mockMounter.EXPECT().Statfs(gomock.Eq(volumePath)).Return(statfs, nil)
 
-				_, err := driver.NodeUnpublishVolume(ctx, req)
+				resp, err := driver.NodeGetVolumeStats(ctx, req)
 				if err != nil {
-					t.Fatalf("NodeUnpublishVolume is failed: %v", err)
+					t.Fatalf("NodeGetVolumeStats is failed: %v", err)
+				}
+
+				expectedUsage := []*csi.VolumeUsage{
+					{
+						Unit:      csi.VolumeUsage_BYTES,
+						Available: 700 * 4096,
+						Total:     1000 * 4096,
+						Used:      200 * 4096,
+					},
+					{
+						Unit:      csi.VolumeUsage_INODES,
+						Available: 60,
+						Total:     100,
+						Used:      40,
+					},
+				}
+				if !reflect.DeepEqual(resp.Usage, expectedUsage) {
+					t.Fatalf("Usage mismatches. actual: %v expected: %v", resp.Usage, expectedUsage)
+				}
+
+				if resp.VolumeCondition.Abnormal {
+					t.Fatalf("VolumeCondition is abnormal: %v", resp.VolumeCondition.Message)
 				}
 			},
 		},
 		{
-			name: "success: target already unmounted",
+			name: "success: corrupted mount",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				defer mockCtl.Finish()
@@ -550,21 +2101,27 @@ func TestNodeUnpublishVolume(t *testing.T) {
 				}
 
 				ctx := context.Background()
-				req := &csi.NodeUnpublishVolumeRequest{
+				req := &csi.NodeGetVolumeStatsRequest{
 					VolumeId:   "volumeId",
-					TargetPath: targetPath,
+					VolumePath: volumePath,
 				}
 
-				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(targetPath)).Return(true, nil)
+				pathErr := fmt.Errorf("transport endpoint is not connected")
+				mockMounter.EXPECT().PathExists(gomock.Eq(volumePath)).Return(false, pathErr)
+				mockMounter.EXPECT().IsCorruptedMnt(gomock.Eq(pathErr)).Return(true)
 
-				_, err := driver.NodeUnpublishVolume(ctx, req)
+				resp, err := driver.NodeGetVolumeStats(ctx, req)
 				if err != nil {
-					t.Fatalf("NodeUnpublishVolume is failed: %v", err)
+					t.Fatalf("NodeGetVolumeStats is failed: %v", err)
+				}
+
+				if !resp.VolumeCondition.Abnormal {
+					t.Fatalf("VolumeCondition is not abnormal")
 				}
 			},
 		},
 		{
-			name: "fail: targetPath is missing",
+			name: "success: statfs of corrupted mount",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				defer mockCtl.Finish()
@@ -577,18 +2134,29 @@ func TestNodeUnpublishVolume(t *testing.T) {
 				}
 
 				ctx := context.Background()
-				req := &csi.NodeUnpublishVolumeRequest{
-					VolumeId: "volumeId",
+				req := &csi.NodeGetVolumeStatsRequest{
+					VolumeId:   "volumeId",
+					VolumePath: volumePath,
 				}
 
-				_, err := driver.NodeUnpublishVolume(ctx, req)
-				if err == nil {
-					t.Fatalf("NodeUnpublishVolume is not failed: %v", err)
+				statErr := fmt.Errorf("stale file handle")
+				mockMounter.EXPECT().PathExists(gomock.Eq(volumePath)).Return(true, nil)
+				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(volumePath)).Return(false, nil)
+				mockMounter.EXPECT().Statfs(gomock.Eq(volumePath)).Return(nil, statErr)
+				mockMounter.EXPECT().IsCorruptedMnt(gomock.Eq(statErr)).Return(true)
+
+				resp, err := driver.NodeGetVolumeStats(ctx, req)
+				if err != nil {
+					t.Fatalf("NodeGetVolumeStats is failed: %v", err)
+				}
+
+				if !resp.VolumeCondition.Abnormal {
+					t.Fatalf("VolumeCondition is not abnormal")
 				}
 			},
 		},
 		{
-			name: "fail: mounter failed to umount",
+			name: "success: abnormal condition of volume path that is not mounted",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				defer mockCtl.Finish()
@@ -601,78 +2169,239 @@ func TestNodeUnpublishVolume(t *testing.T) {
 				}
 
 				ctx := context.Background()
-				req := &csi.NodeUnpublishVolumeRequest{
+				req := &csi.NodeGetVolumeStatsRequest{
 					VolumeId:   "volumeId",
-					TargetPath: targetPath,
+					VolumePath: volumePath,
 				}
 
-				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(targetPath)).Return(false, nil)
-				mountErr := fmt.Errorf("Unmount failed")
-				mockMounter.EXPECT().Unmount(gomock.Eq(targetPath)).Return(mountErr)
+				mockMounter.EXPECT().PathExists(gomock.Eq(volumePath)).Return(true, nil)
+				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(volumePath)).Return(true, nil)
 
-				_, err := driver.NodeUnpublishVolume(ctx, req)
-				if err == nil {
-					t.Fatalf("NodeUnpublishVolume is not failed: %v", err)
+				resp, err := driver.NodeGetVolumeStats(ctx, req)
+				if err != nil {
+					t.Fatalf("NodeGetVolumeStats is failed: %v", err)
+				}
+				if !resp.GetVolumeCondition().GetAbnormal() {
+					t.Fatalf("Volume condition is not abnormal: %v", resp.GetVolumeCondition())
+				}
+				if len(resp.GetUsage()) != 0 {
+					t.Fatalf("Unexpected usage of volume path that is not mounted: %v", resp.GetUsage())
 				}
 			},
 		},
 		{
-			name: "fail another operation in-flight on given volumeId-targetPath",
+			name: "fail: volume path not found",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				defer mockCtl.Finish()
 
 				mockMounter := driverMocks.NewMockMounter(mockCtl)
 
-				awsDriver := &nodeService{
+				driver := &nodeService{
 					mounter:  mockMounter,
 					inFlight: internal.NewInFlight(),
 				}
 
-				req := &csi.NodeUnpublishVolumeRequest{
-					VolumeId:   volumeID,
-					TargetPath: targetPath,
+				ctx := context.Background()
+				req := &csi.NodeGetVolumeStatsRequest{
+					VolumeId:   "volumeId",
+					VolumePath: volumePath,
 				}
 
-				rpcKey := fmt.Sprintf("%s-%s", volumeID, targetPath)
+				mockMounter.EXPECT().PathExists(gomock.Eq(volumePath)).Return(false, nil)
 
-				awsDriver.inFlight.Insert(rpcKey)
-				_, err := awsDriver.NodeUnpublishVolume(context.TODO(), req)
-				expectErr(t, err, codes.Aborted)
+				_, err := driver.NodeGetVolumeStats(ctx, req)
+				if status.Code(err) != codes.NotFound {
+					t.Fatalf("Unexpected error: %v", err)
+				}
 			},
 		},
 		{
-			name: "success: operation in-flight with different volumeId-targetPath",
+			name: "fail: missing volume path",
 			testFunc: func(t *testing.T) {
 				mockCtl := gomock.NewController(t)
 				defer mockCtl.Finish()
 
 				mockMounter := driverMocks.NewMockMounter(mockCtl)
 
-				awsDriver := &nodeService{
+				driver := &nodeService{
 					mounter:  mockMounter,
 					inFlight: internal.NewInFlight(),
 				}
 
 				ctx := context.Background()
-				req := &csi.NodeUnpublishVolumeRequest{
+				req := &csi.NodeGetVolumeStatsRequest{
+					VolumeId: "volumeId",
+				}
+
+				_, err := driver.NodeGetVolumeStats(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			},
+		},
+		{
+			name: "fail: statfs failed",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
+
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				driver := &nodeService{
+					mounter:  mockMounter,
+					inFlight: internal.NewInFlight(),
+				}
+
+				ctx := context.Background()
+				req := &csi.NodeGetVolumeStatsRequest{
 					VolumeId:   "volumeId",
-					TargetPath: targetPath,
+					VolumePath: volumePath,
 				}
 
-				rpcKeyAlt := fmt.Sprintf("%s-%s", volumeID, targetPathAlt)
-				awsDriver.inFlight.Insert(rpcKeyAlt)
+				statErr := fmt.Errorf("permission denied")
+				mockMounter.EXPECT().PathExists(gomock.Eq(volumePath)).Return(true, nil)
+				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(volumePath)).Return(false, nil)
+				mockMounter.EXPECT().Statfs(gomock.Eq(volumePath)).Return(nil, statErr)
+				mockMounter.EXPECT().IsCorruptedMnt(gomock.Eq(statErr)).Return(false)
 
-				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(targetPath)).Return(false, nil)
-				mockMounter.EXPECT().Unmount(gomock.Eq(targetPath)).Return(nil)
+				_, err := driver.NodeGetVolumeStats(ctx, req)
+				if status.Code(err) != codes.Internal {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			},
+		},
+		{
+			name: "success: abnormal condition of mount found unhealthy by the health check",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				defer mockCtl.Finish()
 
-				_, err := awsDriver.NodeUnpublishVolume(ctx, req)
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+
+				health := newMountHealth()
+				health.set(map[string]string{volumePath: "cannot send after transport endpoint shutdown"})
+				driver := &nodeService{
+					mounter:     mockMounter,
+					inFlight:    internal.NewInFlight(),
+					mountHealth: health,
+				}
+
+				ctx := context.Background()
+				req := &csi.NodeGetVolumeStatsRequest{
+					VolumeId:   "volumeId",
+					VolumePath: volumePath,
+				}
+
+				mockMounter.EXPECT().PathExists(gomock.Eq(volumePath)).Return(true, nil)
+				mockMounter.EXPECT().IsLikelyNotMountPoint(gomock.Eq(volumePath)).Return(false, nil)
+
+				resp, err := driver.NodeGetVolumeStats(ctx, req)
 				if err != nil {
-					t.Fatalf("NodeUnpublishVolume is failed: %v", err)
+					t.Fatalf("NodeGetVolumeStats is failed: %v", err)
+				}
+				if !resp.VolumeCondition.Abnormal || !strings.Contains(resp.VolumeCondition.Message, "transport endpoint shutdown") {
+					t.Fatalf("Unexpected volume condition: %v", resp.VolumeCondition)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestNodeGetInfo(t *testing.T) {
+	var (
+		instanceID       = "i-1234567890abcdef0"
+		availabilityZone = "us-west-2a"
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: node name from env",
+			testFunc: func(t *testing.T) {
+				t.Setenv("CSI_NODE_NAME", "test-node")
+				driver := &nodeService{
+					metadata: &cloud.Metadata{
+						InstanceID:       instanceID,
+						AvailabilityZone: availabilityZone,
+					},
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				resp, err := driver.NodeGetInfo(context.TODO(), &csi.NodeGetInfoRequest{})
+				if err != nil {
+					t.Fatalf("NodeGetInfo is failed: %v", err)
+				}
+
+				if resp.NodeId != "test-node" {
+					t.Fatalf("NodeId mismatches. actual: %v expected: %v", resp.NodeId, "test-node")
+				}
+
+				expectedTopology := &csi.Topology{Segments: map[string]string{TopologyKey: availabilityZone}}
+				if !reflect.DeepEqual(resp.AccessibleTopology, expectedTopology) {
+					t.Fatalf("AccessibleTopology mismatches. actual: %v expected: %v", resp.AccessibleTopology, expectedTopology)
+				}
+			},
+		},
+		{
+			name: "success: node name from metadata",
+			testFunc: func(t *testing.T) {
+				t.Setenv("CSI_NODE_NAME", "")
+				driver := &nodeService{
+					metadata: &cloud.Metadata{
+						InstanceID:       instanceID,
+						AvailabilityZone: availabilityZone,
+					},
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				resp, err := driver.NodeGetInfo(context.TODO(), &csi.NodeGetInfoRequest{})
+				if err != nil {
+					t.Fatalf("NodeGetInfo is failed: %v", err)
+				}
+
+				if resp.NodeId != instanceID {
+					t.Fatalf("NodeId mismatches. actual: %v expected: %v", resp.NodeId, instanceID)
+				}
+
+				if resp.AccessibleTopology.Segments[TopologyKey] != availabilityZone {
+					t.Fatalf("zone mismatches. actual: %v expected: %v", resp.AccessibleTopology.Segments[TopologyKey], availabilityZone)
+				}
+			},
+		},
+		{
+			name: "success: unknown availability zone",
+			testFunc: func(t *testing.T) {
+				t.Setenv("CSI_NODE_NAME", "test-node")
+				driver := &nodeService{
+					metadata: &cloud.Metadata{
+						InstanceID: instanceID,
+					},
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				resp, err := driver.NodeGetInfo(context.TODO(), &csi.NodeGetInfoRequest{})
+				if err != nil {
+					t.Fatalf("NodeGetInfo is failed: %v", err)
+				}
+
+				if resp.AccessibleTopology != nil {
+					t.Fatalf("AccessibleTopology is not nil: %v", resp.AccessibleTopology)
 				}
 			},
 		},
 	}
+
 	for _, tc := range testCases {
 		t.Run(tc.name, tc.testFunc)
 	}
@@ -0,0 +1,163 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"k8s.io/mount-utils"
+)
+
+// Orphaned mount cleanups, which control whether the node unmounts the Lustre mounts of pods that no longer exist or
+// only reports them
+const (
+	OrphanedMountCleanupDisabled = "disabled"
+	OrphanedMountCleanupDryRun   = "dry-run"
+	OrphanedMountCleanupEnabled  = "enabled"
+)
+
+// DefaultKubeletPath is the root directory of the kubelet, which contains the directories of the pods of the node
+const DefaultKubeletPath = "/var/lib/kubelet"
+
+// cleanupOrphanedMounts unmounts the Lustre mounts in the pods directory of the kubelet whose pods no longer exist on
+// the node, e.g. because the node plugin was not running when the pods were deleted. In dry-run mode orphaned mounts
+// are only reported. Nothing is unmounted when the pods of the node cannot be listed. Mounts that a volume operation
+// is in progress for are skipped.
+func (d *nodeService) cleanupOrphanedMounts() {
+	mountPoints, err := d.mounter.List()
+	if err != nil {
+		klog.ErrorS(err, "cleanupOrphanedMounts: could not list mounts")
+		return
+	}
+
+	kubeletPath := DefaultKubeletPath
+	if d.driverOptions.kubeletPath != "" {
+		kubeletPath = d.driverOptions.kubeletPath
+	}
+	podsDir := filepath.Join(kubeletPath, "pods") + "/"
+	var podMounts []mount.MountPoint
+	for _, mountPoint := range mountPoints {
+		if mountPoint.Type == "lustre" && strings.HasPrefix(mountPoint.Path, podsDir) && podVolumeMountPattern.MatchString(mountPoint.Path) {
+			podMounts = append(podMounts, mountPoint)
+		}
+	}
+	if len(podMounts) == 0 {
+		return
+	}
+
+	// the pods are listed after the mounts, so the mounts of pods that are created in between are not orphaned
+	pods := d.listNodePods(context.Background())
+	if pods == nil {
+		klog.InfoS("cleanupOrphanedMounts: could not list the pods of the node, skipping cleanup of orphaned mounts")
+		return
+	}
+
+	for _, mountPoint := range podMounts {
+		podUID := podVolumeMountPattern.FindStringSubmatch(mountPoint.Path)[1]
+		if _, ok := pods[types.UID(podUID)]; ok {
+			continue
+		}
+
+		if d.driverOptions.orphanedMountCleanup == OrphanedMountCleanupDryRun {
+			klog.InfoS("cleanupOrphanedMounts: found orphaned Lustre mount, not unmounting it in dry-run mode", "path", mountPoint.Path, "device", mountPoint.Device, "podUID", podUID)
+			d.recordNodeEvent(corev1.EventTypeNormal, "OrphanedMountFound", "Found Lustre mount %s of deleted pod %s, which is not unmounted in dry-run mode", mountPoint.Path, podUID)
+			continue
+		}
+
+		volumeID, err := podVolumeID(mountPoint.Path)
+		if err != nil {
+			klog.ErrorS(err, "cleanupOrphanedMounts: could not get volume ID of orphaned Lustre mount", "path", mountPoint.Path)
+			d.recordNodeEvent(corev1.EventTypeWarning, "OrphanedMountCleanupFailed", "Could not unmount Lustre mount %s of deleted pod %s: %v", mountPoint.Path, podUID, err)
+			continue
+		}
+		unmounted, err := d.unmountOrphan(volumeID, mountPoint.Path)
+		if err != nil {
+			klog.ErrorS(err, "cleanupOrphanedMounts: could not unmount orphaned Lustre mount", "path", mountPoint.Path)
+			d.recordNodeEvent(corev1.EventTypeWarning, "OrphanedMountCleanupFailed", "Could not unmount Lustre mount %s of deleted pod %s: %v", mountPoint.Path, podUID, err)
+			continue
+		}
+		if unmounted {
+			d.recordNodeEvent(corev1.EventTypeNormal, "OrphanedMountUnmounted", "Unmounted Lustre mount %s of deleted pod %s", mountPoint.Path, podUID)
+		}
+	}
+}
+
+// unmountOrphan unmounts the orphaned mount of the volume at target and removes target. It holds the key of
+// NodeUnpublishVolume for the volume and target, and skips the mount if a volume operation is in progress for it or
+// it was unmounted in the meantime. The mount is unmounted only once, without escalating to a forced or lazy
+// unmount. It returns whether the mount was unmounted.
+func (d *nodeService) unmountOrphan(volumeID string, target string) (bool, error) {
+	rpcKey := fmt.Sprintf("%s-%s", volumeID, target)
+	if ok := d.inFlight.Insert(rpcKey); !ok {
+		klog.V(4).InfoS("unmountOrphan: volume operation in progress, skipping orphaned mount", "volumeID", volumeID, "target", target)
+		return false, nil
+	}
+	defer d.inFlight.Delete(rpcKey)
+
+	mountPoint, err := d.findMountPoint(target)
+	if err != nil {
+		return false, fmt.Errorf("could not list mounts: %v", err)
+	}
+	if mountPoint == nil {
+		klog.V(4).InfoS("unmountOrphan: orphaned mount was unmounted in the meantime", "volumeID", volumeID, "target", target)
+		return false, nil
+	}
+
+	klog.InfoS("unmountOrphan: unmounting orphaned Lustre mount", "volumeID", volumeID, "target", target)
+	if d.driverOptions.unmountTimeout > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), d.driverOptions.unmountTimeout)
+		err = d.mounter.UnmountWithContext(ctx, target, nil)
+		cancel()
+	} else {
+		err = d.mounter.Unmount(target)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return true, fmt.Errorf("could not remove target path %q: %v", target, err)
+	}
+	return true, nil
+}
+
+// podVolumeID returns the ID of the CSI volume that is published at the target path of a pod, or staged at a staging
+// target path, from the volume data that the kubelet keeps next to the path
+func podVolumeID(target string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(target), "vol_data.json"))
+	if err != nil {
+		return "", fmt.Errorf("could not read volume data: %v", err)
+	}
+	var volumeData struct {
+		VolumeHandle string `json:"volumeHandle"`
+	}
+	if err := json.Unmarshal(data, &volumeData); err != nil {
+		return "", fmt.Errorf("could not parse volume data: %v", err)
+	}
+	if volumeData.VolumeHandle == "" {
+		return "", fmt.Errorf("volume data has no volume handle")
+	}
+	return volumeData.VolumeHandle, nil
+}
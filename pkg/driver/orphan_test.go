@@ -0,0 +1,251 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/mount-utils"
+	"sigs.k8s.io/aws-fsx-csi-driver/pkg/driver/internal"
+	driverMocks "sigs.k8s.io/aws-fsx-csi-driver/pkg/driver/mocks"
+)
+
+func TestCleanupOrphanedMounts(t *testing.T) {
+	var (
+		volumeID         = "fs-0123456789abcdef0"
+		device           = "10.0.1.5@tcp:/mountname"
+		kubeletPath      = t.TempDir()
+		runningMount     = filepath.Join(kubeletPath, "pods/running-uid/volumes/kubernetes.io~csi/pv-1/mount")
+		orphanMount      = filepath.Join(kubeletPath, "pods/deleted-uid/volumes/kubernetes.io~csi/pv-1/mount")
+		orphanMountPoint = mount.MountPoint{Device: device, Path: orphanMount, Type: "lustre"}
+		mountPoints      = []mount.MountPoint{
+			{Device: "/dev/nvme0n1p1", Path: filepath.Join(kubeletPath, "pods/deleted-uid/volumes/kubernetes.io~csi/pv-2/mount"), Type: "xfs"},
+			{Device: device, Path: filepath.Join(kubeletPath, "plugins/kubernetes.io/csi/fsx.csi.aws.com/0123abcd/globalmount"), Type: "lustre"},
+			{Device: device, Path: "/mnt/fsx", Type: "lustre"},
+			{Device: device, Path: runningMount, Type: "lustre"},
+			orphanMountPoint,
+		}
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "training",
+				Namespace: "ml",
+				UID:       "running-uid",
+			},
+			Spec: corev1.PodSpec{
+				NodeName: "test-node",
+			},
+		}
+	)
+
+	// the kubelet keeps the volume data of a pod volume next to its target path
+	if err := os.MkdirAll(orphanMount, 0750); err != nil {
+		t.Fatalf("Could not create target path: %v", err)
+	}
+	volumeData := fmt.Sprintf(`{"driverName":"fsx.csi.aws.com","specVolID":"pv-1","volumeHandle":%q}`, volumeID)
+	if err := os.WriteFile(filepath.Join(filepath.Dir(orphanMount), "vol_data.json"), []byte(volumeData), 0640); err != nil {
+		t.Fatalf("Could not write volume data: %v", err)
+	}
+
+	newNodeService := func(mockMounter *driverMocks.MockMounter, kubeClient kubernetes.Interface, recorder record.EventRecorder, cleanup string) *nodeService {
+		return &nodeService{
+			mounter:       mockMounter,
+			inFlight:      internal.NewInFlight(),
+			driverOptions: &DriverOptions{orphanedMountCleanup: cleanup, kubeletPath: kubeletPath},
+			kubeClient:    kubeClient,
+			recorder:      recorder,
+			nodeName:      "test-node",
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: orphaned mount is unmounted",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+				recorder := record.NewFakeRecorder(10)
+				driver := newNodeService(mockMounter, fake.NewSimpleClientset(pod), recorder, OrphanedMountCleanupEnabled)
+
+				mockMounter.EXPECT().List().Return(mountPoints, nil).Times(2)
+				mockMounter.EXPECT().Unmount(gomock.Eq(orphanMount)).Return(nil)
+				driver.cleanupOrphanedMounts()
+
+				select {
+				case event := <-recorder.Events:
+					if !strings.Contains(event, "OrphanedMountUnmounted") || !strings.Contains(event, orphanMount) {
+						t.Fatalf("Unexpected event: %v", event)
+					}
+				default:
+					t.Fatal("No event is recorded")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: orphaned mount is not escalated past a plain unmount",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+				recorder := record.NewFakeRecorder(10)
+				driver := newNodeService(mockMounter, fake.NewSimpleClientset(pod), recorder, OrphanedMountCleanupEnabled)
+				driver.driverOptions.unmountPolicy = UnmountPolicyLazy
+				driver.driverOptions.unmountTimeout = time.Minute
+
+				mockMounter.EXPECT().List().Return(mountPoints, nil).Times(2)
+				mockMounter.EXPECT().UnmountWithContext(gomock.Any(), gomock.Eq(orphanMount), gomock.Nil()).Return(context.DeadlineExceeded)
+				driver.cleanupOrphanedMounts()
+
+				select {
+				case event := <-recorder.Events:
+					if !strings.Contains(event, "OrphanedMountCleanupFailed") {
+						t.Fatalf("Unexpected event: %v", event)
+					}
+				default:
+					t.Fatal("No event is recorded")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: orphaned mount with volume operation in progress is skipped",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+				recorder := record.NewFakeRecorder(10)
+				driver := newNodeService(mockMounter, fake.NewSimpleClientset(pod), recorder, OrphanedMountCleanupEnabled)
+				driver.inFlight.Insert(fmt.Sprintf("%s-%s", volumeID, orphanMount))
+
+				mockMounter.EXPECT().List().Return(mountPoints, nil)
+				driver.cleanupOrphanedMounts()
+
+				if len(recorder.Events) != 0 {
+					t.Fatalf("Unexpected event: %v", <-recorder.Events)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: orphaned mount unmounted in the meantime is skipped",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+				recorder := record.NewFakeRecorder(10)
+				driver := newNodeService(mockMounter, fake.NewSimpleClientset(pod), recorder, OrphanedMountCleanupEnabled)
+
+				gomock.InOrder(
+					mockMounter.EXPECT().List().Return(mountPoints, nil),
+					mockMounter.EXPECT().List().Return(mountPoints[:len(mountPoints)-1], nil),
+				)
+				driver.cleanupOrphanedMounts()
+
+				if len(recorder.Events) != 0 {
+					t.Fatalf("Unexpected event: %v", <-recorder.Events)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: orphaned mount is only reported in dry-run mode",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+				recorder := record.NewFakeRecorder(10)
+				driver := newNodeService(mockMounter, fake.NewSimpleClientset(pod), recorder, OrphanedMountCleanupDryRun)
+
+				mockMounter.EXPECT().List().Return(mountPoints, nil)
+				driver.cleanupOrphanedMounts()
+
+				select {
+				case event := <-recorder.Events:
+					if !strings.Contains(event, "OrphanedMountFound") || !strings.Contains(event, orphanMount) {
+						t.Fatalf("Unexpected event: %v", event)
+					}
+				default:
+					t.Fatal("No event is recorded")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: nothing is unmounted when pods cannot be listed",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+				recorder := record.NewFakeRecorder(10)
+				driver := newNodeService(mockMounter, nil, recorder, OrphanedMountCleanupEnabled)
+
+				mockMounter.EXPECT().List().Return(mountPoints, nil)
+				driver.cleanupOrphanedMounts()
+
+				if len(recorder.Events) != 0 {
+					t.Fatalf("Unexpected event: %v", <-recorder.Events)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: orphaned mount could not be unmounted",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockMounter := driverMocks.NewMockMounter(mockCtl)
+				recorder := record.NewFakeRecorder(10)
+				driver := newNodeService(mockMounter, fake.NewSimpleClientset(pod), recorder, OrphanedMountCleanupEnabled)
+
+				mockMounter.EXPECT().List().Return(mountPoints, nil).Times(2)
+				mockMounter.EXPECT().Unmount(gomock.Eq(orphanMount)).Return(errors.New("target is busy"))
+				driver.cleanupOrphanedMounts()
+
+				select {
+				case event := <-recorder.Events:
+					if !strings.Contains(event, "OrphanedMountCleanupFailed") {
+						t.Fatalf("Unexpected event: %v", event)
+					}
+				default:
+					t.Fatal("No event is recorded")
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
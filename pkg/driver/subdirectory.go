@@ -0,0 +1,547 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+	"k8s.io/mount-utils"
+	"sigs.k8s.io/aws-fsx-csi-driver/pkg/cloud"
+)
+
+const (
+	// subdirectoryVolumeIdSeparator separates the filesystem ID and the subdirectory in the ID of a subdirectory volume
+	subdirectoryVolumeIdSeparator = "::"
+	// archivedSubdirectoryPrefix prefixes the name of a subdirectory that is archived on deletion
+	archivedSubdirectoryPrefix = "archived-"
+
+	defaultSubdirectoryBasePath = "/"
+	defaultDirectoryPerms       = 0755
+
+	// minProjectId is the smallest Lustre project ID assigned to subdirectory volumes, smaller project IDs are left
+	// to administrators
+	minProjectId = 1 << 16
+	maxProjectId = math.MaxInt32
+)
+
+// Subdirectory delete policies, which control what DeleteVolume does with the subdirectory of a volume
+const (
+	SubdirectoryDeletePolicyDelete  = "delete"
+	SubdirectoryDeletePolicyArchive = "archive"
+	SubdirectoryDeletePolicyRetain  = "retain"
+)
+
+// subdirectoryOptions represents the options to create the subdirectory of a volume
+type subdirectoryOptions struct {
+	fileSystemId string
+	basePath     string
+	perms        os.FileMode
+	uid          int
+	gid          int
+	projectQuota bool
+}
+
+// parseVolumeId splits the ID of a volume into the filesystem ID and, for subdirectory volumes, the absolute path of
+// the subdirectory inside the filesystem.
+func parseVolumeId(volumeId string) (fileSystemId string, subdirectory string) {
+	fileSystemId, subdirectory, _ = strings.Cut(volumeId, subdirectoryVolumeIdSeparator)
+	return fileSystemId, subdirectory
+}
+
+func newSubdirectoryVolumeId(fileSystemId string, subdirectory string) string {
+	return fileSystemId + subdirectoryVolumeIdSeparator + subdirectory
+}
+
+// validateSubdirectory returns an error unless subdirectory is a clean absolute path below the filesystem root.
+func validateSubdirectory(subdirectory string) error {
+	if !path.IsAbs(subdirectory) || path.Clean(subdirectory) != subdirectory || subdirectory == "/" {
+		return fmt.Errorf("subdirectory %q must be a clean absolute path below the filesystem root", subdirectory)
+	}
+	return nil
+}
+
+// newSubdirectoryOptions parses the parameters of a StorageClass that provisions volumes as subdirectories of the
+// filesystem in parameter fileSystemId.
+func newSubdirectoryOptions(volumeParams map[string]string) (*subdirectoryOptions, error) {
+	options := &subdirectoryOptions{
+		fileSystemId: volumeParams[volumeParamsFileSystemId],
+		basePath:     defaultSubdirectoryBasePath,
+		perms:        defaultDirectoryPerms,
+		uid:          -1,
+		gid:          -1,
+	}
+
+	if val, ok := volumeParams[volumeParamsBasePath]; ok {
+		if !path.IsAbs(val) || path.Clean(val) != val {
+			return nil, fmt.Errorf("%s must be a clean absolute path", volumeParamsBasePath)
+		}
+		options.basePath = val
+	}
+	if val, ok := volumeParams[volumeParamsDirectoryPerms]; ok {
+		perms, err := parseDirectoryPerms(volumeParamsDirectoryPerms, val)
+		if err != nil {
+			return nil, err
+		}
+		options.perms = perms
+	}
+	if val, ok := volumeParams[volumeParamsUid]; ok {
+		uid, err := parseOwnerId(volumeParamsUid, val)
+		if err != nil {
+			return nil, err
+		}
+		options.uid = uid
+	}
+	if val, ok := volumeParams[volumeParamsGid]; ok {
+		gid, err := parseOwnerId(volumeParamsGid, val)
+		if err != nil {
+			return nil, err
+		}
+		options.gid = gid
+	}
+	if val, ok := volumeParams[volumeParamsProjectQuota]; ok {
+		projectQuota, err := strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a boolean", volumeParamsProjectQuota)
+		}
+		options.projectQuota = projectQuota
+	}
+	return options, nil
+}
+
+// subpathOptions represents the options of a statically provisioned volume that mounts a subpath of the filesystem
+type subpathOptions struct {
+	subpath string
+	create  bool
+	perms   os.FileMode
+	uid     int
+	gid     int
+}
+
+// validateSubpath returns an error unless subpath is a clean relative path inside the filesystem.
+func validateSubpath(subpath string) error {
+	if path.IsAbs(subpath) || validateSubdirectory("/"+subpath) != nil {
+		return fmt.Errorf("%s %q must be a clean relative path inside the filesystem", volumeContextSubpath, subpath)
+	}
+	return nil
+}
+
+// newSubpathOptions parses the attributes of a volume that mounts the subpath in attribute subpath, which is created
+// if attribute createSubpath is true and the subpath is missing.
+func newSubpathOptions(volumeContext map[string]string) (*subpathOptions, error) {
+	options := &subpathOptions{
+		subpath: volumeContext[volumeContextSubpath],
+		perms:   defaultDirectoryPerms,
+		uid:     -1,
+		gid:     -1,
+	}
+	if options.subpath == "" {
+		for _, key := range []string{volumeContextCreateSubpath, volumeContextSubpathUid, volumeContextSubpathGid, volumeContextSubpathMode} {
+			if _, ok := volumeContext[key]; ok {
+				return nil, fmt.Errorf("%s requires %s", key, volumeContextSubpath)
+			}
+		}
+		return options, nil
+	}
+
+	if err := validateSubpath(options.subpath); err != nil {
+		return nil, err
+	}
+	if val, ok := volumeContext[volumeContextCreateSubpath]; ok {
+		create, err := strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a boolean", volumeContextCreateSubpath)
+		}
+		options.create = create
+	}
+	if val, ok := volumeContext[volumeContextSubpathMode]; ok {
+		perms, err := parseDirectoryPerms(volumeContextSubpathMode, val)
+		if err != nil {
+			return nil, err
+		}
+		options.perms = perms
+	}
+	if val, ok := volumeContext[volumeContextSubpathUid]; ok {
+		uid, err := parseOwnerId(volumeContextSubpathUid, val)
+		if err != nil {
+			return nil, err
+		}
+		options.uid = uid
+	}
+	if val, ok := volumeContext[volumeContextSubpathGid]; ok {
+		gid, err := parseOwnerId(volumeContextSubpathGid, val)
+		if err != nil {
+			return nil, err
+		}
+		options.gid = gid
+	}
+	return options, nil
+}
+
+func parseDirectoryPerms(key string, val string) (os.FileMode, error) {
+	perms, err := strconv.ParseUint(val, 8, 32)
+	if err != nil || perms > 0777 {
+		return 0, fmt.Errorf("%s must be an octal permission like 0755", key)
+	}
+	return os.FileMode(perms), nil
+}
+
+func parseOwnerId(key string, val string) (int, error) {
+	id, err := strconv.Atoi(val)
+	if err != nil || id < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer", key)
+	}
+	return id, nil
+}
+
+// makeDirectory creates dir and its missing parents with perms and, unless uid and gid are -1, changes the owner of
+// dir.
+func makeDirectory(dir string, perms os.FileMode, uid int, gid int) error {
+	if err := os.MkdirAll(dir, perms); err != nil {
+		return err
+	}
+	// MkdirAll is subject to the umask, so the permissions are set explicitly
+	if err := os.Chmod(dir, perms); err != nil {
+		return err
+	}
+	if uid >= 0 || gid >= 0 {
+		return os.Chown(dir, uid, gid)
+	}
+	return nil
+}
+
+// createSubdirectoryVolume creates a volume as a subdirectory of an existing filesystem, which is mounted by the
+// controller to create the subdirectory.
+func (d *controllerService) createSubdirectoryVolume(ctx context.Context, req *csi.CreateVolumeRequest) (*csi.CreateVolumeResponse, error) {
+	if req.GetVolumeContentSource() != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "Volumes provisioned with parameter %q do not support a volume content source", volumeParamsFileSystemId)
+	}
+
+	// the volume name becomes a single directory below the base path
+	name := req.GetName()
+	if name == "" || name == "." || name == ".." || path.Base(name) != name {
+		return nil, status.Errorf(codes.InvalidArgument, "Volume name %q is not a valid directory name", name)
+	}
+
+	options, err := newSubdirectoryOptions(req.GetParameters())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	capacityBytes := req.GetCapacityRange().GetRequiredBytes()
+	if options.projectQuota && capacityBytes <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "Volumes provisioned with parameter %q require a capacity", volumeParamsProjectQuota)
+	}
+
+	fs, err := d.cloud.DescribeFileSystem(ctx, options.fileSystemId)
+	if err != nil {
+		if errors.Is(err, cloud.ErrNotFound) {
+			return nil, status.Errorf(codes.NotFound, "Filesystem %s not found", options.fileSystemId)
+		}
+		return nil, status.Errorf(codes.Internal, "Could not get filesystem %s: %v", options.fileSystemId, err)
+	}
+
+	var zone string
+	if fs.SubnetId != "" {
+		subnet, err := d.describeSubnet(ctx, fs.SubnetId)
+		if err != nil {
+			return nil, err
+		}
+		zone = subnet.AvailabilityZone
+		if !isZoneAccessible(req.GetAccessibilityRequirements(), zone) {
+			return nil, status.Errorf(codes.ResourceExhausted, "Filesystem %s in Availability Zone %s is not accessible from the requested topology", fs.FileSystemId, zone)
+		}
+	}
+
+	subdirectory := path.Join(options.basePath, req.GetName())
+	err = d.withFileSystemMounted(fs, func(root string) error {
+		dir := filepath.Join(root, subdirectory)
+		if err := makeDirectory(dir, options.perms, options.uid, options.gid); err != nil {
+			return err
+		}
+		if !options.projectQuota {
+			return nil
+		}
+
+		projectId, err := d.setProject(fs.FileSystemId, root, dir)
+		if err != nil {
+			return err
+		}
+		klog.V(4).InfoS("CreateVolume: setting project quota", "filesystem", fs.FileSystemId, "subdirectory", subdirectory, "project", projectId, "bytes", capacityBytes)
+		return d.lustre.SetProjectQuota(root, projectId, capacityBytes)
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not create subdirectory %s of filesystem %s: %v", subdirectory, fs.FileSystemId, err)
+	}
+
+	volume := newCSIVolume(fs, nil)
+	volume.VolumeId = newSubdirectoryVolumeId(fs.FileSystemId, subdirectory)
+	volume.CapacityBytes = capacityBytes
+	if zone != "" {
+		volume.AccessibleTopology = []*csi.Topology{
+			{
+				Segments: map[string]string{TopologyKey: zone},
+			},
+		}
+	}
+	return &csi.CreateVolumeResponse{Volume: volume}, nil
+}
+
+// deleteSubdirectoryVolume deletes, archives or retains the subdirectory of a volume according to the subdirectory
+// delete policy of the driver.
+func (d *controllerService) deleteSubdirectoryVolume(ctx context.Context, fileSystemId string, subdirectory string) error {
+	if err := validateSubdirectory(subdirectory); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	policy := d.driverOptions.subdirectoryDeletePolicy
+	if policy == "" {
+		policy = SubdirectoryDeletePolicyDelete
+	}
+	if policy == SubdirectoryDeletePolicyRetain {
+		klog.V(4).InfoS("DeleteVolume: retaining subdirectory", "filesystem", fileSystemId, "subdirectory", subdirectory)
+		return nil
+	}
+
+	fs, err := d.cloud.DescribeFileSystem(ctx, fileSystemId)
+	if err != nil {
+		if errors.Is(err, cloud.ErrNotFound) {
+			klog.V(4).InfoS("DeleteVolume: filesystem not found, returning with success", "filesystem", fileSystemId)
+			return nil
+		}
+		return status.Errorf(codes.Internal, "Could not get filesystem %s: %v", fileSystemId, err)
+	}
+
+	err = d.withFileSystemMounted(fs, func(root string) error {
+		dir := filepath.Join(root, subdirectory)
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			klog.V(4).InfoS("DeleteVolume: subdirectory not found, returning with success", "filesystem", fileSystemId, "subdirectory", subdirectory)
+			return nil
+		}
+		if policy == SubdirectoryDeletePolicyArchive {
+			archived := filepath.Join(filepath.Dir(dir), fmt.Sprintf("%s%s-%d", archivedSubdirectoryPrefix, filepath.Base(dir), time.Now().Unix()))
+			klog.V(4).InfoS("DeleteVolume: archiving subdirectory", "filesystem", fileSystemId, "subdirectory", subdirectory, "archive", archived)
+			return os.Rename(dir, archived)
+		}
+		// the project quota of the volume is removed along with the subdirectory, a filesystem without project quotas
+		// leaves nothing to remove
+		projectId, err := d.ownProjectId(dir)
+		if err != nil {
+			klog.V(4).InfoS("DeleteVolume: could not get project of subdirectory", "filesystem", fileSystemId, "subdirectory", subdirectory, "err", err)
+			projectId = 0
+		}
+		klog.V(4).InfoS("DeleteVolume: deleting subdirectory", "filesystem", fileSystemId, "subdirectory", subdirectory)
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+		if projectId == 0 {
+			return nil
+		}
+		klog.V(4).InfoS("DeleteVolume: removing project quota", "filesystem", fileSystemId, "subdirectory", subdirectory, "project", projectId)
+		return d.lustre.SetProjectQuota(root, projectId, 0)
+	})
+	if err != nil {
+		return status.Errorf(codes.Internal, "Could not %s subdirectory %s of filesystem %s: %v", policy, subdirectory, fileSystemId, err)
+	}
+	return nil
+}
+
+// expandSubdirectoryVolume raises the project quota of a subdirectory volume. The capacity of subdirectory volumes
+// without a project quota is not enforced, they share the capacity of the filesystem. The project quota is never
+// lowered below its limit or the usage of the subdirectory.
+func (d *controllerService) expandSubdirectoryVolume(ctx context.Context, fileSystemId string, subdirectory string, capacityBytes int64) error {
+	if err := validateSubdirectory(subdirectory); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	fs, err := d.cloud.DescribeFileSystem(ctx, fileSystemId)
+	if err != nil {
+		if errors.Is(err, cloud.ErrNotFound) {
+			return status.Errorf(codes.NotFound, "Filesystem %s not found", fileSystemId)
+		}
+		return status.Errorf(codes.Internal, "Could not get filesystem %s: %v", fileSystemId, err)
+	}
+
+	err = d.withFileSystemMounted(fs, func(root string) error {
+		dir := filepath.Join(root, subdirectory)
+		if _, err := os.Stat(dir); err != nil {
+			return err
+		}
+		projectId, err := d.lustre.GetProject(dir)
+		if err != nil {
+			return err
+		}
+		if projectId == 0 {
+			klog.V(4).InfoS("ControllerExpandVolume: subdirectory has no project quota", "filesystem", fileSystemId, "subdirectory", subdirectory)
+			return nil
+		}
+		usedBytes, _, limitBytes, err := d.lustre.GetProjectQuota(root, projectId)
+		if err != nil {
+			return err
+		}
+		// the limit is set in KiB, so a capacity that is not a multiple of KiB is compared rounded up
+		if (capacityBytes+1023)/1024*1024 < limitBytes || capacityBytes < usedBytes {
+			return errQuotaShrink{limitBytes: limitBytes, usedBytes: usedBytes}
+		}
+		klog.V(4).InfoS("ControllerExpandVolume: setting project quota", "filesystem", fileSystemId, "subdirectory", subdirectory, "project", projectId, "bytes", capacityBytes)
+		return d.lustre.SetProjectQuota(root, projectId, capacityBytes)
+	})
+	if os.IsNotExist(err) {
+		return status.Errorf(codes.NotFound, "Subdirectory %s of filesystem %s not found", subdirectory, fileSystemId)
+	}
+	var shrink errQuotaShrink
+	if errors.As(err, &shrink) {
+		return status.Errorf(codes.OutOfRange, "Requested capacity of %d bytes is less than the project quota of %d bytes or the usage of %d bytes of subdirectory %s of filesystem %s", capacityBytes, shrink.limitBytes, shrink.usedBytes, subdirectory, fileSystemId)
+	}
+	if err != nil {
+		return status.Errorf(codes.Internal, "Could not expand subdirectory %s of filesystem %s: %v", subdirectory, fileSystemId, err)
+	}
+	return nil
+}
+
+// errQuotaShrink is returned when the project quota of a subdirectory volume would be lowered
+type errQuotaShrink struct {
+	limitBytes int64
+	usedBytes  int64
+}
+
+func (e errQuotaShrink) Error() string {
+	return fmt.Sprintf("project quota of %d bytes with a usage of %d bytes cannot be lowered", e.limitBytes, e.usedBytes)
+}
+
+// setProject assigns a project ID to dir on the filesystem mounted at root and returns it. Project IDs are allocated
+// one at a time per filesystem, so that concurrent volumes are not assigned the same unused project ID.
+func (d *controllerService) setProject(fileSystemId string, root string, dir string) (uint32, error) {
+	lock, _ := d.projectIdLocks.LoadOrStore(fileSystemId, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	projectId, err := d.allocateProjectId(root, dir)
+	if err != nil {
+		return 0, err
+	}
+	if err := d.lustre.SetProject(dir, projectId); err != nil {
+		return 0, err
+	}
+	return projectId, nil
+}
+
+// ownProjectId returns the project ID of dir if it has one of its own, or 0 if it inherits the project ID of its
+// parent.
+func (d *controllerService) ownProjectId(dir string) (uint32, error) {
+	parentProjectId, err := d.lustre.GetProject(filepath.Dir(dir))
+	if err != nil {
+		return 0, err
+	}
+	projectId, err := d.lustre.GetProject(dir)
+	if err != nil {
+		return 0, err
+	}
+	if projectId == parentProjectId {
+		return 0, nil
+	}
+	return projectId, nil
+}
+
+// allocateProjectId returns the project ID of dir if it has one of its own. Otherwise it derives a project ID from
+// the name of dir, skipping the project ID of the parent of dir and project IDs that are used anywhere on the
+// filesystem mounted at root.
+func (d *controllerService) allocateProjectId(root string, dir string) (uint32, error) {
+	parentProjectId, err := d.lustre.GetProject(filepath.Dir(dir))
+	if err != nil {
+		return 0, err
+	}
+	projectId, err := d.lustre.GetProject(dir)
+	if err != nil {
+		return 0, err
+	}
+	// a directory inherits the project ID of its parent, which must not be shared with the volume
+	if projectId != 0 && projectId != parentProjectId {
+		return projectId, nil
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(filepath.Base(dir)))
+	projectId = minProjectId + hash.Sum32()%(maxProjectId-minProjectId+1)
+	for {
+		if projectId != parentProjectId {
+			usedBytes, usedFiles, _, err := d.lustre.GetProjectQuota(root, projectId)
+			if err != nil {
+				return 0, err
+			}
+			// a project ID is in use while any file or directory has it
+			if usedBytes == 0 && usedFiles == 0 {
+				return projectId, nil
+			}
+		}
+		projectId++
+		if projectId > maxProjectId {
+			projectId = minProjectId
+		}
+	}
+}
+
+// withFileSystemMounted mounts the root of the filesystem to a temporary directory, calls f with that directory and
+// unmounts the filesystem again.
+func (d *controllerService) withFileSystemMounted(fs *cloud.FileSystem, f func(root string) error) error {
+	if d.mounter == nil {
+		return fmt.Errorf("the controller is not able to mount filesystems")
+	}
+
+	mountName := fs.MountName
+	if mountName == "" {
+		mountName = "fsx"
+	}
+	return withSourceMounted(d.mounter, fmt.Sprintf("%s@tcp:/%s", fs.DnsName, mountName), fs.FileSystemId, f)
+}
+
+// withSourceMounted mounts the Lustre source to a temporary directory whose name starts with name, calls f with that
+// directory and unmounts the source again.
+func withSourceMounted(mounter Mounter, source string, name string, f func(root string) error) (err error) {
+	root, err := os.MkdirTemp("", name+"-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cleanupErr := mount.CleanupMountPoint(root, mounter, false); cleanupErr != nil {
+			klog.ErrorS(cleanupErr, "Could not unmount filesystem", "source", source, "target", root)
+			if err == nil {
+				err = cleanupErr
+			}
+		}
+	}()
+
+	klog.V(4).InfoS("Mounting filesystem", "source", source, "target", root)
+	if err := mounter.Mount(source, root, "lustre", nil); err != nil {
+		return fmt.Errorf("could not mount %q at %q: %v", source, root, err)
+	}
+	return f(root)
+}
@@ -0,0 +1,855 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/mount-utils"
+	"sigs.k8s.io/aws-fsx-csi-driver/pkg/cloud"
+	"sigs.k8s.io/aws-fsx-csi-driver/pkg/driver/internal"
+	"sigs.k8s.io/aws-fsx-csi-driver/pkg/driver/mocks"
+)
+
+// fakeFileSystemMounter simulates the mount of a filesystem whose content is stored in root: the content is moved to
+// the target on mount and back to root on unmount.
+type fakeFileSystemMounter struct {
+	NodeMounter
+	root    string
+	sources []string
+}
+
+func newFakeFileSystemMounter(t *testing.T) *fakeFileSystemMounter {
+	return &fakeFileSystemMounter{
+		NodeMounter: NodeMounter{
+			Interface: &mount.FakeMounter{},
+		},
+		root: t.TempDir(),
+	}
+}
+
+func (m *fakeFileSystemMounter) Mount(source string, target string, fstype string, options []string) error {
+	if err := m.NodeMounter.Mount(source, target, fstype, options); err != nil {
+		return err
+	}
+	m.sources = append(m.sources, source)
+	return moveEntries(m.root, target)
+}
+
+func (m *fakeFileSystemMounter) MountWithContext(_ context.Context, source string, target string, fstype string, options []string) error {
+	return m.Mount(source, target, fstype, options)
+}
+
+func (m *fakeFileSystemMounter) Unmount(target string) error {
+	if err := moveEntries(target, m.root); err != nil {
+		return err
+	}
+	return m.NodeMounter.Unmount(target)
+}
+
+func moveEntries(from string, to string) error {
+	entries, err := os.ReadDir(from)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := os.Rename(filepath.Join(from, entry.Name()), filepath.Join(to, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestCreateSubdirectoryVolume(t *testing.T) {
+	var (
+		volumeName       = "pvc-1234"
+		fileSystemId     = "fs-1234"
+		subnetId         = "subnet-056da83524edbe641"
+		availabilityZone = "us-west-2a"
+		dnsName          = "test.fsx.us-west-2.amazoawd.com"
+		mountName        = "random"
+		stdVolCap        = &csi.VolumeCapability{
+			AccessType: &csi.VolumeCapability_Mount{
+				Mount: &csi.VolumeCapability_MountVolume{},
+			},
+			AccessMode: &csi.VolumeCapability_AccessMode{
+				Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_MULTI_WRITER,
+			},
+		}
+		fs = &cloud.FileSystem{
+			FileSystemId: fileSystemId,
+			CapacityGiB:  1200,
+			DnsName:      dnsName,
+			MountName:    mountName,
+			SubnetId:     subnetId,
+		}
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: normal",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mounter := newFakeFileSystemMounter(t)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					mounter:       mounter,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: 10 * 1024 * 1024 * 1024,
+					},
+					Parameters: map[string]string{
+						volumeParamsFileSystemId:                    fileSystemId,
+						volumeParamsBasePath:                        "/teams",
+						volumeParamsDirectoryPerms:                  "0770",
+						volumeParamsUid:                             strconv.Itoa(os.Getuid()),
+						volumeParamsGid:                             strconv.Itoa(os.Getgid()),
+						lustreTunablePrefix + "llite.max_cached_mb": "32",
+					},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
+				mockCloud.EXPECT().DescribeSubnet(gomock.Eq(ctx), gomock.Eq(subnetId)).Return(&cloud.Subnet{SubnetId: subnetId, AvailabilityZone: availabilityZone}, nil)
+
+				resp, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume is failed: %v", err)
+				}
+
+				expectedVolumeId := fileSystemId + "::/teams/" + volumeName
+				if resp.Volume.VolumeId != expectedVolumeId {
+					t.Fatalf("VolumeId mismatches. actual: %v expected: %v", resp.Volume.VolumeId, expectedVolumeId)
+				}
+
+				if resp.Volume.CapacityBytes != req.CapacityRange.RequiredBytes {
+					t.Fatalf("CapacityBytes mismatches. actual: %v expected: %v", resp.Volume.CapacityBytes, req.CapacityRange.RequiredBytes)
+				}
+
+				if resp.Volume.VolumeContext[volumeContextDnsName] != dnsName {
+					t.Fatalf("dnsname mismatches. actual: %v expected: %v", resp.Volume.VolumeContext[volumeContextDnsName], dnsName)
+				}
+
+				if resp.Volume.VolumeContext[lustreTunablePrefix+"llite.max_cached_mb"] != "32" {
+					t.Fatalf("Lustre tunable mismatches. actual: %v expected: %v", resp.Volume.VolumeContext[lustreTunablePrefix+"llite.max_cached_mb"], "32")
+				}
+
+				zone := resp.Volume.AccessibleTopology[0].Segments[TopologyKey]
+				if zone != availabilityZone {
+					t.Fatalf("AccessibleTopology mismatches. actual: %v expected: %v", zone, availabilityZone)
+				}
+
+				expectedSource := dnsName + "@tcp:/" + mountName
+				if len(mounter.sources) != 1 || mounter.sources[0] != expectedSource {
+					t.Fatalf("Mount source mismatches. actual: %v expected: %v", mounter.sources, expectedSource)
+				}
+
+				info, err := os.Stat(filepath.Join(mounter.root, "teams", volumeName))
+				if err != nil {
+					t.Fatalf("Subdirectory is not created: %v", err)
+				}
+
+				if info.Mode().Perm() != 0770 {
+					t.Fatalf("Permissions mismatch. actual: %v expected: %v", info.Mode().Perm(), os.FileMode(0770))
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: invalid directoryPerms",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					mounter:       newFakeFileSystemMounter(t),
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsFileSystemId:   fileSystemId,
+						volumeParamsDirectoryPerms: "rwx",
+					},
+				}
+
+				ctx := context.Background()
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: invalid volume names",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					mounter:       newFakeFileSystemMounter(t),
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				ctx := context.Background()
+				for _, name := range []string{"", ".", "..", "teams/pvc-1234", "pvc-1234/", "/pvc-1234"} {
+					req := &csi.CreateVolumeRequest{
+						Name: name,
+						VolumeCapabilities: []*csi.VolumeCapability{
+							stdVolCap,
+						},
+						Parameters: map[string]string{
+							volumeParamsFileSystemId: fileSystemId,
+						},
+					}
+
+					_, err := driver.createSubdirectoryVolume(ctx, req)
+					if status.Code(err) != codes.InvalidArgument {
+						t.Fatalf("Unexpected error for volume name %q: %v", name, err)
+					}
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: relative basePath",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					mounter:       newFakeFileSystemMounter(t),
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsFileSystemId: fileSystemId,
+						volumeParamsBasePath:     "../teams",
+					},
+				}
+
+				ctx := context.Background()
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: filesystem not found",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					mounter:       newFakeFileSystemMounter(t),
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsFileSystemId: fileSystemId,
+					},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(nil, cloud.ErrNotFound)
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.NotFound {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: project quota",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mockLustre := mocks.NewMockLustre(mockCtl)
+				mounter := newFakeFileSystemMounter(t)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					mounter:       mounter,
+					lustre:        mockLustre,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: 10 * 1024 * 1024 * 1024,
+					},
+					Parameters: map[string]string{
+						volumeParamsFileSystemId: fileSystemId,
+						volumeParamsBasePath:     "/teams",
+						volumeParamsProjectQuota: "true",
+					},
+				}
+
+				ctx := context.Background()
+				fs := &cloud.FileSystem{
+					FileSystemId: fileSystemId,
+					DnsName:      dnsName,
+					MountName:    mountName,
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
+
+				var projectId uint32
+				// the parent and the new subdirectory have no project
+				mockLustre.EXPECT().GetProject(gomock.Any()).Return(uint32(0), nil).Times(2)
+				// the project ID derived from the name is used elsewhere on the filesystem, so the next one is taken
+				var checkedIds []uint32
+				mockLustre.EXPECT().GetProjectQuota(gomock.Any(), gomock.Any()).DoAndReturn(func(mountPoint string, id uint32) (int64, int64, int64, error) {
+					checkedIds = append(checkedIds, id)
+					if len(checkedIds) == 1 {
+						return int64(0), int64(1), int64(0), nil
+					}
+					return int64(0), int64(0), int64(0), nil
+				}).Times(2)
+				mockLustre.EXPECT().SetProject(gomock.Any(), gomock.Any()).DoAndReturn(func(dir string, id uint32) error {
+					if filepath.Base(dir) != volumeName {
+						t.Fatalf("SetProject directory mismatches. actual: %v expected: %v", filepath.Base(dir), volumeName)
+					}
+					projectId = id
+					return nil
+				})
+				mockLustre.EXPECT().SetProjectQuota(gomock.Any(), gomock.Any(), gomock.Eq(req.CapacityRange.RequiredBytes)).DoAndReturn(func(mountPoint string, id uint32, limitBytes int64) error {
+					if id != projectId {
+						t.Fatalf("SetProjectQuota project mismatches. actual: %v expected: %v", id, projectId)
+					}
+					return nil
+				})
+
+				resp, err := driver.CreateVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("CreateVolume is failed: %v", err)
+				}
+
+				if projectId < minProjectId || projectId > maxProjectId {
+					t.Fatalf("Project ID %v is out of range", projectId)
+				}
+
+				if projectId != checkedIds[1] || projectId == checkedIds[0] {
+					t.Fatalf("Project ID %v is in use, checked project IDs: %v", projectId, checkedIds)
+				}
+
+				if resp.Volume.CapacityBytes != req.CapacityRange.RequiredBytes {
+					t.Fatalf("CapacityBytes mismatches. actual: %v expected: %v", resp.Volume.CapacityBytes, req.CapacityRange.RequiredBytes)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: project quota without capacity",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					mounter:       newFakeFileSystemMounter(t),
+					lustre:        mocks.NewMockLustre(mockCtl),
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				req := &csi.CreateVolumeRequest{
+					Name: volumeName,
+					VolumeCapabilities: []*csi.VolumeCapability{
+						stdVolCap,
+					},
+					Parameters: map[string]string{
+						volumeParamsFileSystemId: fileSystemId,
+						volumeParamsProjectQuota: "true",
+					},
+				}
+
+				ctx := context.Background()
+				_, err := driver.CreateVolume(ctx, req)
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestDeleteSubdirectoryVolume(t *testing.T) {
+	var (
+		fileSystemId = "fs-1234"
+		volumeId     = fileSystemId + "::/teams/pvc-1234"
+		fs           = &cloud.FileSystem{
+			FileSystemId: fileSystemId,
+			DnsName:      "test.fsx.us-west-2.amazoawd.com",
+			MountName:    "random",
+		}
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: delete",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mounter := newFakeFileSystemMounter(t)
+				if err := os.MkdirAll(filepath.Join(mounter.root, "teams", "pvc-1234", "data"), 0755); err != nil {
+					t.Fatal(err)
+				}
+
+				mockLustre := mocks.NewMockLustre(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					mounter:       mounter,
+					lustre:        mockLustre,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{subdirectoryDeletePolicy: SubdirectoryDeletePolicyDelete},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
+				// the subdirectory has no project quota
+				mockLustre.EXPECT().GetProject(gomock.Any()).Return(uint32(0), nil).Times(2)
+				_, err := driver.DeleteVolume(ctx, &csi.DeleteVolumeRequest{VolumeId: volumeId})
+				if err != nil {
+					t.Fatalf("DeleteVolume is failed: %v", err)
+				}
+
+				entries, _ := os.ReadDir(filepath.Join(mounter.root, "teams"))
+				if len(entries) != 0 {
+					t.Fatalf("Subdirectory is not deleted: %v", entries)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: delete removes the project quota",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mockLustre := mocks.NewMockLustre(mockCtl)
+				mounter := newFakeFileSystemMounter(t)
+				if err := os.MkdirAll(filepath.Join(mounter.root, "teams", "pvc-1234"), 0755); err != nil {
+					t.Fatal(err)
+				}
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					mounter:       mounter,
+					lustre:        mockLustre,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{subdirectoryDeletePolicy: SubdirectoryDeletePolicyDelete},
+				}
+
+				ctx := context.Background()
+				projectId := uint32(minProjectId + 42)
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
+				mockLustre.EXPECT().GetProject(gomock.Any()).DoAndReturn(func(path string) (uint32, error) {
+					if filepath.Base(path) == "pvc-1234" {
+						return projectId, nil
+					}
+					return 0, nil
+				}).Times(2)
+				mockLustre.EXPECT().SetProjectQuota(gomock.Any(), gomock.Eq(projectId), gomock.Eq(int64(0))).Return(nil)
+				_, err := driver.DeleteVolume(ctx, &csi.DeleteVolumeRequest{VolumeId: volumeId})
+				if err != nil {
+					t.Fatalf("DeleteVolume is failed: %v", err)
+				}
+
+				entries, _ := os.ReadDir(filepath.Join(mounter.root, "teams"))
+				if len(entries) != 0 {
+					t.Fatalf("Subdirectory is not deleted: %v", entries)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: archive",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mounter := newFakeFileSystemMounter(t)
+				if err := os.MkdirAll(filepath.Join(mounter.root, "teams", "pvc-1234"), 0755); err != nil {
+					t.Fatal(err)
+				}
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					mounter:       mounter,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{subdirectoryDeletePolicy: SubdirectoryDeletePolicyArchive},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
+				_, err := driver.DeleteVolume(ctx, &csi.DeleteVolumeRequest{VolumeId: volumeId})
+				if err != nil {
+					t.Fatalf("DeleteVolume is failed: %v", err)
+				}
+
+				entries, _ := os.ReadDir(filepath.Join(mounter.root, "teams"))
+				if len(entries) != 1 || !strings.HasPrefix(entries[0].Name(), archivedSubdirectoryPrefix+"pvc-1234-") {
+					t.Fatalf("Subdirectory is not archived: %v", entries)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: retain",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					mounter:       newFakeFileSystemMounter(t),
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{subdirectoryDeletePolicy: SubdirectoryDeletePolicyRetain},
+				}
+
+				ctx := context.Background()
+				_, err := driver.DeleteVolume(ctx, &csi.DeleteVolumeRequest{VolumeId: volumeId})
+				if err != nil {
+					t.Fatalf("DeleteVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "success: subdirectory not found",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					mounter:       newFakeFileSystemMounter(t),
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{subdirectoryDeletePolicy: SubdirectoryDeletePolicyDelete},
+				}
+
+				ctx := context.Background()
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
+				_, err := driver.DeleteVolume(ctx, &csi.DeleteVolumeRequest{VolumeId: volumeId})
+				if err != nil {
+					t.Fatalf("DeleteVolume is failed: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: filesystem root",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					mounter:       newFakeFileSystemMounter(t),
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{subdirectoryDeletePolicy: SubdirectoryDeletePolicyDelete},
+				}
+
+				ctx := context.Background()
+				_, err := driver.DeleteVolume(ctx, &csi.DeleteVolumeRequest{VolumeId: fileSystemId + "::/"})
+				if status.Code(err) != codes.InvalidArgument {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
+
+func TestExpandSubdirectoryVolume(t *testing.T) {
+	var (
+		fileSystemId = "fs-1234"
+		volumeId     = fileSystemId + "::/teams/pvc-1234"
+		projectId    = uint32(minProjectId + 42)
+		fs           = &cloud.FileSystem{
+			FileSystemId: fileSystemId,
+			DnsName:      "test.fsx.us-west-2.amazoawd.com",
+			MountName:    "random",
+		}
+	)
+	testCases := []struct {
+		name     string
+		testFunc func(t *testing.T)
+	}{
+		{
+			name: "success: project quota",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mockLustre := mocks.NewMockLustre(mockCtl)
+				mounter := newFakeFileSystemMounter(t)
+				if err := os.MkdirAll(filepath.Join(mounter.root, "teams", "pvc-1234"), 0755); err != nil {
+					t.Fatal(err)
+				}
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					mounter:       mounter,
+					lustre:        mockLustre,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				ctx := context.Background()
+				req := &csi.ControllerExpandVolumeRequest{
+					VolumeId: volumeId,
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: 20 * 1024 * 1024 * 1024,
+					},
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
+				mockLustre.EXPECT().GetProject(gomock.Any()).Return(projectId, nil)
+				mockLustre.EXPECT().GetProjectQuota(gomock.Any(), gomock.Eq(projectId)).Return(int64(1024*1024*1024), int64(1), int64(10*1024*1024*1024), nil)
+				mockLustre.EXPECT().SetProjectQuota(gomock.Any(), gomock.Eq(projectId), gomock.Eq(req.CapacityRange.RequiredBytes)).Return(nil)
+
+				resp, err := driver.ControllerExpandVolume(ctx, req)
+				if err != nil {
+					t.Fatalf("ControllerExpandVolume is failed: %v", err)
+				}
+
+				if resp.CapacityBytes != req.CapacityRange.RequiredBytes {
+					t.Fatalf("CapacityBytes mismatches. actual: %v expected: %v", resp.CapacityBytes, req.CapacityRange.RequiredBytes)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: capacity below the project quota",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mockLustre := mocks.NewMockLustre(mockCtl)
+				mounter := newFakeFileSystemMounter(t)
+				if err := os.MkdirAll(filepath.Join(mounter.root, "teams", "pvc-1234"), 0755); err != nil {
+					t.Fatal(err)
+				}
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					mounter:       mounter,
+					lustre:        mockLustre,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				ctx := context.Background()
+				req := &csi.ControllerExpandVolumeRequest{
+					VolumeId: volumeId,
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: 5 * 1024 * 1024 * 1024,
+					},
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
+				mockLustre.EXPECT().GetProject(gomock.Any()).Return(projectId, nil)
+				mockLustre.EXPECT().GetProjectQuota(gomock.Any(), gomock.Eq(projectId)).Return(int64(1024*1024*1024), int64(1), int64(10*1024*1024*1024), nil)
+
+				_, err := driver.ControllerExpandVolume(ctx, req)
+				if status.Code(err) != codes.OutOfRange {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: volume operation in progress",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					mounter:       newFakeFileSystemMounter(t),
+					lustre:        mocks.NewMockLustre(mockCtl),
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+				driver.inFlight.Insert(volumeId)
+
+				ctx := context.Background()
+				req := &csi.ControllerExpandVolumeRequest{
+					VolumeId: volumeId,
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: 20 * 1024 * 1024 * 1024,
+					},
+				}
+
+				_, err := driver.ControllerExpandVolume(ctx, req)
+				if status.Code(err) != codes.Aborted {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: subdirectory not found",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					mounter:       newFakeFileSystemMounter(t),
+					lustre:        mocks.NewMockLustre(mockCtl),
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				ctx := context.Background()
+				req := &csi.ControllerExpandVolumeRequest{
+					VolumeId: volumeId,
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: 20 * 1024 * 1024 * 1024,
+					},
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
+
+				_, err := driver.ControllerExpandVolume(ctx, req)
+				if status.Code(err) != codes.NotFound {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+		{
+			name: "fail: set quota failed",
+			testFunc: func(t *testing.T) {
+				mockCtl := gomock.NewController(t)
+				mockCloud := mocks.NewMockCloud(mockCtl)
+				mockLustre := mocks.NewMockLustre(mockCtl)
+				mounter := newFakeFileSystemMounter(t)
+				if err := os.MkdirAll(filepath.Join(mounter.root, "teams", "pvc-1234"), 0755); err != nil {
+					t.Fatal(err)
+				}
+
+				driver := controllerService{
+					cloud:         mockCloud,
+					mounter:       mounter,
+					lustre:        mockLustre,
+					inFlight:      internal.NewInFlight(),
+					driverOptions: &DriverOptions{},
+				}
+
+				ctx := context.Background()
+				req := &csi.ControllerExpandVolumeRequest{
+					VolumeId: volumeId,
+					CapacityRange: &csi.CapacityRange{
+						RequiredBytes: 20 * 1024 * 1024 * 1024,
+					},
+				}
+				mockCloud.EXPECT().DescribeFileSystem(gomock.Eq(ctx), gomock.Eq(fileSystemId)).Return(fs, nil)
+				mockLustre.EXPECT().GetProject(gomock.Any()).Return(projectId, nil)
+				mockLustre.EXPECT().GetProjectQuota(gomock.Any(), gomock.Eq(projectId)).Return(int64(0), int64(0), int64(10*1024*1024*1024), nil)
+				mockLustre.EXPECT().SetProjectQuota(gomock.Any(), gomock.Eq(projectId), gomock.Any()).Return(errors.New("quota disabled"))
+
+				_, err := driver.ControllerExpandVolume(ctx, req)
+				if status.Code(err) != codes.Internal {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				mockCtl.Finish()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, tc.testFunc)
+	}
+}
@@ -35,7 +35,7 @@ type fsxVolume struct {
 }
 
 func (v *fsxVolume) DeleteVolume(ctx context.Context) {
-	err := v.c.DeleteFileSystem(ctx, v.fileSystemId)
+	_, err := v.c.DeleteFileSystem(ctx, v.fileSystemId, nil)
 	if err != nil {
 		Fail(fmt.Sprintf("failed to delete filesystem %s", err))
 	}
@@ -224,7 +224,7 @@ func (t *TestPersistentVolumeClaim) DeleteBoundPersistentVolume() {
 func (t *TestPersistentVolumeClaim) DeleteBackingVolume(cloud awscloud.Cloud) {
 	volumeID := t.persistentVolume.Spec.CSI.VolumeHandle
 	By(fmt.Sprintf("deleting FSx filesystem %q", volumeID))
-	err := cloud.DeleteFileSystem(context.Background(), volumeID)
+	_, err := cloud.DeleteFileSystem(context.Background(), volumeID, nil)
 	if err != nil {
 		Fail(fmt.Sprintf("could not delete volume %q: %v", volumeID, err))
 	}
@@ -17,10 +17,10 @@ import (
 	"os"
 	"testing"
 
-	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
-	sanity "github.com/kubernetes-csi/csi-test/pkg/sanity"
+	sanity "github.com/kubernetes-csi/csi-test/v5/pkg/sanity"
 
 	"sigs.k8s.io/aws-fsx-csi-driver/pkg/driver"
 	"sigs.k8s.io/aws-fsx-csi-driver/pkg/util"
@@ -54,11 +54,12 @@ var _ = AfterSuite(func() {
 
 var _ = Describe("AWS FSx for Lustre CSI Driver", func() {
 	_ = os.MkdirAll("/tmp/csi", os.ModePerm)
-	config := &sanity.Config{
-		Address:        endpoint,
-		TargetPath:     mountPath,
-		StagingPath:    stagePath,
-		TestVolumeSize: 2000 * util.GiB,
-	}
-	sanity.GinkgoTest(config)
+	config := sanity.NewTestConfig()
+	config.Address = endpoint
+	config.TargetPath = mountPath
+	config.StagingPath = stagePath
+	config.TestVolumeSize = 1200 * util.GiB
+	config.TestVolumeExpandSize = 2400 * util.GiB
+	config.TestVolumeMutableParameters = map[string]string{"dataCompressionType": "LZ4"}
+	sanity.GinkgoTest(&config)
 })